@@ -8,11 +8,13 @@ import (
 	"go/token"
 	"go/types"
 	"log"
+	"maps"
 	"math"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 
@@ -40,8 +42,12 @@ type Type interface {
 
 // LocalName returns the local name of the type.
 // It will panic if `ty` is not named.
+// For anonymous structs, the synthesized name is returned.
 func LocalName(ty Type) string {
-	return ty.Type().(*types.Named).Obj().Name()
+	if st, isStruct := ty.(*Struct); isStruct {
+		return st.Name.Obj().Name()
+	}
+	return ty.Type().(interface{ Obj() *types.TypeName }).Obj().Name()
 }
 
 func selectByFile(pkgs []*packages.Package, file string) *packages.Package {
@@ -134,6 +140,44 @@ func LoadSources(sourceFiles []string) ([]*packages.Package, string, error) {
 	return out, dir, nil
 }
 
+// ExpandSources returns the Go files (tests excluded) matched by [pattern], which is either
+// a file, a file glob (like models/*.go), or a package pattern, like an import path or ./models/...
+// The files are sorted, so that the analysis is deterministic.
+func ExpandSources(pattern string) ([]string, error) {
+	var files []string
+	if strings.HasSuffix(pattern, ".go") {
+		if !strings.ContainsAny(pattern, "*?[") { // regular file
+			return []string{pattern}, nil
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range matches {
+			if !strings.HasSuffix(file, "_test.go") {
+				files = append(files, file)
+			}
+		}
+	} else {
+		cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles}
+		pkgs, err := packages.Load(cfg, pattern)
+		if err != nil {
+			return nil, err
+		}
+		if nbErrors := packages.PrintErrors(pkgs); nbErrors > 0 {
+			return nil, fmt.Errorf("invalid package pattern %s", pattern)
+		}
+		for _, pkg := range pkgs {
+			files = append(files, pkg.GoFiles...)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files matching %s", pattern)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
 // LoadSource returns the `packages.Package` containing the given file.
 func LoadSource(sourceFile string) (*packages.Package, error) {
 	pkgs, _, err := LoadSources([]string{sourceFile})
@@ -268,21 +312,74 @@ type Analysis struct {
 	Pkg *packages.Package
 
 	// Source is the list of top-level types
-	// defined in the analysis input file.
+	// defined in the analysis input file(s).
 	Source []types.Type
+
+	anonymousCount int             // used to name top level anonymous structs
+	anonymousNames map[string]bool // qualified names given to anonymous structs
+
+	instantiations map[string]*types.Named // by qualified monomorphized name
 }
 
 // NewAnalysisFromFile uses the given Package [pa]
 // to build the analysis for the types defined in `sourceFile`,
 // one file included in [pa]
-func NewAnalysisFromFile(pkg *packages.Package, sourceFile string) *Analysis {
-	sourceFileAbs, err := filepath.Abs(sourceFile)
-	if err != nil {
-		panic(err)
+// The types (or fields) which can't be analyzed are reported to [diags] and
+// ignored.
+func NewAnalysisFromFile(pkg *packages.Package, sourceFile string, diags *Diagnostics) *Analysis {
+	return NewAnalysisFromFiles([]*packages.Package{pkg}, []string{sourceFile}, diags)
+}
+
+// NewAnalysisFromFiles is the same as [NewAnalysisFromFile], but merges the types
+// defined in several files, where `sourceFiles[i]` is included in `pkgs[i]`, as returned
+// by [LoadSources]. The files may belong to different packages, the first one being
+// used as [Analysis.Pkg].
+// [Analysis.Source] is sorted by file, and then by position in the file.
+func NewAnalysisFromFiles(pkgs []*packages.Package, sourceFiles []string, diags *Diagnostics) *Analysis {
+	var (
+		objs  []*types.TypeName
+		roots []*packages.Package
+	)
+	for i, sourceFile := range sourceFiles {
+		pkg := pkgs[i]
+		sourceFileAbs, err := filepath.Abs(sourceFile)
+		if err != nil {
+			diags.Add(NewError(token.Position{Filename: sourceFile}, CodeUnsupportedType, "invalid source file: %s", err))
+			continue
+		}
+		if !slices.Contains(roots, pkg) {
+			roots = append(roots, pkg)
+		}
+		objs = append(objs, fileTypeNames(pkg, sourceFileAbs)...)
+	}
+
+	// order according to source, so that for instance SQL constraints
+	// are kept in correct order
+	sort.Slice(objs, func(i, j int) bool {
+		// the packages are loaded at once, sharing the file set
+		pi, pj := pkgs[0].Fset.Position(objs[i].Pos()), pkgs[0].Fset.Position(objs[j].Pos())
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+
+	nameds := make([]types.Type, len(objs))
+	for i, obj := range objs {
+		nameds[i] = obj.Type()
+	}
+
+	if len(roots) == 0 { // all the files are invalid
+		return &Analysis{Pkg: pkgs[0], Types: map[types.Type]Type{}}
 	}
+	out := &Analysis{Pkg: roots[0]}
+	out.populateTypes(roots, nameds, diags)
+	return out
+}
 
-	// walk the top level type declarations
-	var objs []*types.TypeName
+// fileTypeNames returns the top level type declarations of [pkg]
+// defined in [sourceFileAbs]
+func fileTypeNames(pkg *packages.Package, sourceFileAbs string) (out []*types.TypeName) {
 	scope := pkg.Types.Scope()
 	for _, name := range scope.Names() {
 		object := scope.Lookup(name)
@@ -297,38 +394,39 @@ func NewAnalysisFromFile(pkg *packages.Package, sourceFile string) *Analysis {
 			continue
 		}
 
-		objs = append(objs, typeName)
+		out = append(out, typeName)
 	}
-
-	// order according to source, so that for instance SQL constraints
-	// are kept in correct order
-	sort.Slice(objs, func(i, j int) bool { return objs[i].Pos() < objs[j].Pos() })
-
-	nameds := make([]types.Type, len(objs))
-	for i, obj := range objs {
-		nameds[i] = obj.Type()
-	}
-
-	return NewAnalysisFromTypes(pkg, nameds)
+	return out
 }
 
 // NewAnalysisFromTypes build the analysis for the given `types`.
 // `root` is the root package, required to query type information.
-func NewAnalysisFromTypes(pkg *packages.Package, source []types.Type) *Analysis {
-	out := &Analysis{Source: source, Pkg: pkg}
-	out.populateTypes(pkg)
+// The types which can't be analyzed are reported to [diags], and
+// removed from [Analysis.Source].
+func NewAnalysisFromTypes(pkg *packages.Package, source []types.Type, diags *Diagnostics) *Analysis {
+	out := &Analysis{Pkg: pkg}
+	out.populateTypes([]*packages.Package{pkg}, source, diags)
 	return out
 }
 
-func (an *Analysis) populateTypes(pa *packages.Package) {
+// populateTypes analyzes [source], using [roots] to query
+// the enums, unions and comments.
+func (an *Analysis) populateTypes(roots []*packages.Package, source []types.Type, diags *Diagnostics) {
 	log.Println("Fetching enums and unions....")
-	enums, unions := fetchEnumsAndUnions(pa)
+	enums, unions := make(enumsMap), make(unionsMap)
+	for _, root := range roots {
+		rootEnums, rootUnions := fetchEnumsAndUnions(root)
+		maps.Copy(enums, rootEnums)
+		maps.Copy(unions, rootUnions)
+	}
 
-	ctx := context{enums: enums, unions: unions, rootPackage: pa}
+	ctx := context{enums: enums, unions: unions, roots: roots, diags: diags}
 
 	an.Types = make(map[types.Type]Type)
-	for _, typ := range an.Source {
-		an.handleType(typ, ctx)
+	for _, typ := range source {
+		if diags.Try(DeclarationPos(typ), func() { an.handleType(typ, ctx) }) {
+			an.Source = append(an.Source, typ)
+		}
 	}
 
 	for _, type_ := range an.Types {
@@ -341,7 +439,11 @@ func (an *Analysis) populateTypes(pa *packages.Package) {
 // context stores the parameters need by the analysis,
 // which may vary between types
 type context struct {
-	rootPackage *packages.Package
+	// roots are the packages of the analyzed files, the first
+	// one being the root package
+	roots []*packages.Package
+
+	diags *Diagnostics
 
 	// enums and unions are used during analysis,
 	// and may be used to handle enums and union types
@@ -349,9 +451,13 @@ type context struct {
 	unions unionsMap
 
 	isInExtern bool
+
+	// anonymousName is the name given to the anonymous structs
+	// found when analyzing a field or a named type
+	anonymousName *types.TypeName
 }
 
-func (an *Analysis) handleStructFields(typ *types.Struct, ctx context) []StructField {
+func (an *Analysis) handleStructFields(parent *types.Named, typ *types.Struct, ctx context) []StructField {
 	var out []StructField
 	for i := 0; i < typ.NumFields(); i++ {
 		field := typ.Field(i)
@@ -361,18 +467,33 @@ func (an *Analysis) handleStructFields(typ *types.Struct, ctx context) []StructF
 			continue
 		}
 
-		// recurse
-		fieldType := an.handleType(field.Type(), ctx)
+		// anonymous structs are named after the parent type and the field
+		ctx.anonymousName = types.NewTypeName(field.Pos(), parent.Obj().Pkg(), MonomorphizedName(parent)+field.Name(), nil)
+
+		var fieldType Type
+		ok := ctx.diags.Try(field.Pos(), func() {
+			if ext := tag.Get("gomacro-type"); ext != "" { // the mapping only applies to this field
+				fieldType = newExternalFromTag(field.Type(), ext)
+			} else {
+				fieldType = an.handleType(field.Type(), ctx) // recurse
+			}
+		})
+		if !ok { // the error is reported, ignore the field
+			continue
+		}
 
 		// to simplify, we do not fully support embedded fields :
 		// we only accept structs, and we merge the fields
-		if field.Embedded() {
+		// As the json package, embedded fields with a json name are not flattened,
+		// whereas fields with the 'inline' json option are.
+		jsonName, jsonOptions := jsonTag(tag)
+		if (field.Embedded() && jsonName == "") || slices.Contains(jsonOptions, "inline") {
 			if st, isStruct := fieldType.(*Struct); isStruct {
-				log.Printf("gomacro: embedded struct field %s will be flattened", field.Name())
+				log.Printf("gomacro: struct field %s will be flattened", field.Name())
 				out = append(out, st.Fields...)
 				continue
 			} else {
-				log.Printf("gomacro: field %s: embedding will be ignored", field.Name())
+				ctx.diags.Warnf(field.Pos(), CodeIgnoredField, "field %s: embedding will be ignored", field.Name())
 			}
 		}
 
@@ -381,11 +502,53 @@ func (an *Analysis) handleStructFields(typ *types.Struct, ctx context) []StructF
 	return out
 }
 
+// handleAnonymousStruct analyzes the anonymous struct [typ], using the name
+// provided by the context, or a generated one for top level types.
+func (an *Analysis) handleAnonymousStruct(typ *types.Struct, ctx context) *Struct {
+	obj := ctx.anonymousName
+	if obj == nil {
+		an.anonymousCount++
+		obj = types.NewTypeName(token.NoPos, ctx.roots[0].Types, fmt.Sprintf("Anonymous%d", an.anonymousCount), nil)
+	}
+	// do not share the object between types, and avoid name collisions
+	obj = types.NewTypeName(obj.Pos(), obj.Pkg(), an.uniqueAnonymousName(obj.Pkg(), obj.Name()), nil)
+	str := &Struct{Name: types.NewNamed(obj, typ, nil), Anonymous: typ}
+	an.Types[typ] = str                                    // register before recursing
+	str.Fields = an.handleStructFields(str.Name, typ, ctx) // recurse
+	return str
+}
+
+// uniqueAnonymousName returns [name], or [name] followed by a number if it is already
+// used by a type declared in [pkg] or by another anonymous struct.
+func (an *Analysis) uniqueAnonymousName(pkg *types.Package, name string) string {
+	if an.anonymousNames == nil {
+		an.anonymousNames = make(map[string]bool)
+	}
+	isUsed := func(name string) bool {
+		return pkg.Scope().Lookup(name) != nil || an.anonymousNames[pkg.Path()+"."+name]
+	}
+	out := name
+	for i := 2; isUsed(out); i++ {
+		out = fmt.Sprintf("%s%d", name, i)
+	}
+	an.anonymousNames[pkg.Path()+"."+out] = true
+	return out
+}
+
 func (an *Analysis) createType(typ types.Type, ctx context) Type {
 	if typ == nil {
 		panic("nil types.Type")
 	}
 
+	if param, isParam := typ.(*types.TypeParam); isParam {
+		return &TypeParam{T: param}
+	}
+
+	// types not supported, or with a custom representation
+	if ext, isExternal := lookupExternal(typ); isExternal {
+		return &External{typ: typ, Mapping: ext}
+	}
+
 	if alias, isAlias := typ.(*types.Alias); isAlias {
 		typ = types.Unalias(alias)
 	}
@@ -412,14 +575,28 @@ func (an *Analysis) createType(typ types.Type, ctx context) Type {
 				Name: name,
 				// Implements are defered
 			}
-			an.Types[typ] = str                         // register before recursing
-			str.Fields = an.handleStructFields(st, ctx) // recurse
-			str.Comments = fetchStructComments(ctx.rootPackage, name)
+			an.Types[typ] = str // register before recursing
+			str.TypeParams = an.handleTypeParams(name, ctx)
+			if str.TypeArgs = an.handleTypeArgs(name, ctx); len(str.TypeArgs) != 0 {
+				str.Origin = an.handleType(name.Origin(), ctx).(*Struct)
+			}
+			str.Fields = an.handleStructFields(name, st, ctx) // recurse
+			str.Comments = fetchStructComments(ctx.roots, name, ctx.diags)
 			return str
 		} else {
 			// otherwise, analyze the underlying type
-			under := an.handleType(typ.Underlying(), ctx).(AnonymousType)
-			return &Named{name: name, Underlying: under}
+			out := &Named{name: name, TypeParams: an.handleTypeParams(name, ctx)}
+			if out.TypeArgs = an.handleTypeArgs(name, ctx); len(out.TypeArgs) != 0 {
+				out.Origin = an.handleType(name.Origin(), ctx).(*Named)
+			}
+			// anonymous structs are named after the named type, like ItemsElem for Items []struct{...}
+			suffix := "Elem"
+			if _, isMap := typ.Underlying().(*types.Map); isMap {
+				suffix = "" // the map adds its own suffixes
+			}
+			ctx.anonymousName = types.NewTypeName(name.Obj().Pos(), name.Obj().Pkg(), MonomorphizedName(name)+suffix, nil)
+			out.Underlying = an.handleType(typ.Underlying(), ctx).(AnonymousType)
+			return out
 		}
 	}
 
@@ -428,6 +605,9 @@ func (an *Analysis) createType(typ types.Type, ctx context) Type {
 		elem := an.handleType(underlying.Elem(), ctx) // recurse for the element
 		return &Pointer{Elem: elem}
 	case *types.Basic:
+		if _, ok := NewBasicKind(underlying.Info()); !ok {
+			panic(NewError(token.Position{}, CodeUnsupportedType, "unsupported basic type %s", typ))
+		}
 		return &Basic{B: underlying}
 
 	// to properly handle recursive types (for Array, Slice, Map, Struct), we first register
@@ -446,14 +626,19 @@ func (an *Analysis) createType(typ types.Type, ctx context) Type {
 	case *types.Map:
 		out := &Map{}
 		an.Types[typ] = out
-		out.Key = an.handleType(underlying.Key(), ctx)   // recurse
-		out.Elem = an.handleType(underlying.Elem(), ctx) // recurse
+		// anonymous keys and values are named after the map, like IndexKey and IndexElem
+		keyCtx, elemCtx := ctx, ctx
+		if base := ctx.anonymousName; base != nil {
+			keyCtx.anonymousName = types.NewTypeName(base.Pos(), base.Pkg(), base.Name()+"Key", nil)
+			elemCtx.anonymousName = types.NewTypeName(base.Pos(), base.Pkg(), base.Name()+"Elem", nil)
+		}
+		out.Key = an.handleType(underlying.Key(), keyCtx)    // recurse
+		out.Elem = an.handleType(underlying.Elem(), elemCtx) // recurse
 		return out
 	case *types.Struct:
-		panic("anonymous structs are not supported")
+		return an.handleAnonymousStruct(underlying, ctx)
 	default:
-		// unhandled type, should not happend on real case
-		panic("unsupported type " + typ.String())
+		panic(NewError(token.Position{}, CodeUnsupportedType, "unsupported type %s", typ))
 	}
 }
 
@@ -464,6 +649,12 @@ func (an *Analysis) handleType(typ types.Type, ctx context) Type {
 	if v, has := an.Types[typ]; has { // we have already seen this type
 		return v
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			delete(an.Types, typ) // do not keep incomplete types
+			panic(r)
+		}
+	}()
 
 	// resolve the type
 	type_ := an.createType(typ, ctx)
@@ -502,7 +693,8 @@ func NewLinker(rootDirectory string, sources []*Analysis) Linker {
 	prefix := path.Dir(rootDirectory) + "/"
 	for _, src := range sources {
 		for typ, resolved := range src.Types {
-			if _, isTime := resolved.(*Time); isTime { // considered as predefined
+			switch resolved.(type) {
+			case *Time, *External: // considered as predefined
 				continue
 			}
 			if named, isNamed := types.Unalias(typ).(*types.Named); isNamed {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/types"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -38,15 +39,20 @@ func TestPanics(t *testing.T) {
 
 	ShouldPanic(t, func() { fetchConstComment(&pkg, testPkg.Types.Scope().Lookup("Yes").(*types.Const)) })
 
-	ShouldPanic(t, func() { isSpecialComment("// gomacro:XXX a") })
-
 	ShouldPanic(t, func() { (&Basic{B: types.Typ[types.Complex128]}).Kind() })
 
 	ShouldPanic(t, func() { (&Analysis{}).createType(nil, context{}) })
-	ShouldPanic(t, func() { (&Analysis{}).createType(types.NewStruct(nil, nil), context{}) })
 	ShouldPanic(t, func() { (&Analysis{}).createType(types.NewChan(types.RecvOnly, nil), context{}) })
 }
 
+func TestSpecialComments(t *testing.T) {
+	kind, content, err := isSpecialComment("// gomacro:SQL ADD UNIQUE(Id)")
+	Assert(t, err == nil && kind == CommentSQL && content == "ADD UNIQUE(Id)")
+
+	_, _, err = isSpecialComment("// gomacro:XXX a")
+	Assert(t, err != nil)
+}
+
 func TestMethodTags(t *testing.T) {
 	for _, v := range []Type{
 		&Basic{B: &types.Basic{}},
@@ -58,6 +64,7 @@ func TestMethodTags(t *testing.T) {
 		&Union{},
 		&Pointer{&Time{}},
 		&Named{},
+		&TypeParam{},
 	} {
 		v.Type()
 	}
@@ -69,7 +76,7 @@ func newAnalysisFromFile(sourceFile string) (*Analysis, error) {
 		return nil, err
 	}
 
-	return NewAnalysisFromFile(pa, sourceFile), nil
+	return NewAnalysisFromFile(pa, sourceFile, nil), nil
 }
 
 func TestLoadSource(t *testing.T) {
@@ -86,6 +93,51 @@ func TestLoadSource(t *testing.T) {
 	Assert(t, err == nil)
 }
 
+func TestExpandSources(t *testing.T) {
+	files, err := ExpandSources("analysis.go")
+	Assert(t, err == nil && len(files) == 1)
+
+	files, err = ExpandSources("../testutils/testsource/subpackage/*.go")
+	Assert(t, err == nil && len(files) == 2)
+	Assert(t, filepath.Base(files[0]) == "enums.go" && filepath.Base(files[1]) == "named.go")
+
+	files, err = ExpandSources("*.go") // tests are excluded
+	Assert(t, err == nil && slices.Contains(files, "analysis.go") && !slices.Contains(files, "analysis_test.go"))
+
+	files, err = ExpandSources("../testutils/testsource/...")
+	Assert(t, err == nil && len(files) == 5)
+
+	files, err = ExpandSources("github.com/benoitkugler/gomacro/testutils/testsource/subpackage")
+	Assert(t, err == nil && len(files) == 2)
+
+	_, err = ExpandSources("../testutils/testsource/not_go/*.go")
+	Assert(t, err != nil)
+}
+
+func TestAnalysisFromFiles(t *testing.T) {
+	files, err := ExpandSources("../testutils/testsource/...")
+	Assert(t, err == nil)
+	pkgs, _, err := LoadSources(files)
+	Assert(t, err == nil)
+
+	diags := NewDiagnostics(pkgs[0].Fset)
+	an := NewAnalysisFromFiles(pkgs, files, diags)
+	Assert(t, an.Pkg == pkgs[0])
+	Assert(t, diags.ErrorCount() == 7) // from invalid.go
+
+	// Source is sorted by file and position
+	var names []string
+	for _, ty := range an.Source {
+		names = append(names, ty.(*types.Named).Obj().Name())
+	}
+	Assert(t, names[0] == "ConcretType1" && names[len(names)-1] == "NamedSlice")
+	Assert(t, slices.Index(names, "WithOpaque") < slices.Index(names, "Event") && slices.Index(names, "Broken") < slices.Index(names, "WithEmbeded"))
+
+	// comments are found in every package
+	st := an.Types[Lookup(pkgs[len(pkgs)-1], "StructWithComment")].(*Struct)
+	Assert(t, len(st.Comments) == 1)
+}
+
 func TestFetch(t *testing.T) {
 	enums, _ := fetchEnumsAndUnions(testPkg)
 	if len(enums) != 6 {
@@ -96,13 +148,13 @@ func TestFetch(t *testing.T) {
 func TestAnalysFromTypes(t *testing.T) {
 	st := Lookup(testPkg, "StructWithExternalRef")
 
-	an := NewAnalysisFromTypes(testPkg, []types.Type{st})
+	an := NewAnalysisFromTypes(testPkg, []types.Type{st}, nil)
 	Assert(t, len(an.Source) == 1)
 	Assert(t, len(an.Types) > 0)
 }
 
 func TestAnalysisStruct(t *testing.T) {
-	an := NewAnalysisFromFile(testPkg, testSource)
+	an := NewAnalysisFromFile(testPkg, testSource, nil)
 
 	st := Lookup(testPkg, "StructWithExternalRef")
 	fields := an.Types[st].(*Struct).Fields
@@ -110,13 +162,13 @@ func TestAnalysisStruct(t *testing.T) {
 }
 
 func TestGetByName(t *testing.T) {
-	an := NewAnalysisFromFile(testPkg, testSource)
+	an := NewAnalysisFromFile(testPkg, testSource, nil)
 
 	fmt.Println(an.GetByName("Basic2").Type().String())
 }
 
 func TestLinker(t *testing.T) {
-	an := NewAnalysisFromFile(testPkg, testSource)
+	an := NewAnalysisFromFile(testPkg, testSource, nil)
 
 	lk := NewLinker("go/src/github.com/benoitkugler/gomacro/testutils/testsource", []*Analysis{an})
 	lk.Extension = ".dart"
@@ -129,14 +181,14 @@ func TestLinker(t *testing.T) {
 }
 
 func TestTime(t *testing.T) {
-	an := NewAnalysisFromFile(testPkg, testSource)
+	an := NewAnalysisFromFile(testPkg, testSource, nil)
 
 	st := Lookup(testPkg, "ComplexStruct")
 	Assert(t, !an.Types[st].(*Struct).Fields[3].Type.(*Time).IsDate)
 }
 
 func TestOpaque(t *testing.T) {
-	an := NewAnalysisFromFile(testPkg, testSource)
+	an := NewAnalysisFromFile(testPkg, testSource, nil)
 
 	st := Lookup(testPkg, "WithOpaque")
 	Assert(t, an.Types[st].(*Struct).Fields[0].IsOpaqueFor("dart"))
@@ -146,3 +198,40 @@ func TestOpaque(t *testing.T) {
 	Assert(t, !an.Types[st].(*Struct).Fields[2].IsOpaqueFor("dart"))
 	Assert(t, an.Types[st].(*Struct).Fields[2].IsOpaqueFor("typescript"))
 }
+
+func TestAnonymousStructs(t *testing.T) {
+	structT := Lookup(testPkg, "WithAnonymous")
+	an := NewAnalysisFromTypes(testPkg, []types.Type{structT}, nil)
+
+	st := an.Types[structT].(*Struct)
+	Assert(t, len(st.Fields) == 4)
+
+	meta := st.Fields[0].Type.(*Struct)
+	Assert(t, meta.Anonymous != nil && LocalName(meta) == "WithAnonymousMeta")
+	Assert(t, meta.Type() == meta.Anonymous && len(meta.Fields) == 2)
+
+	item := st.Fields[1].Type.(*Array).Elem.(*Struct)
+	Assert(t, LocalName(item) == "WithAnonymousItems")
+	Assert(t, LocalName(item.Fields[2].Type) == "WithAnonymousItemsInner")
+
+	pair := st.Fields[2].Type.(*Pointer).Elem.(*Struct)
+	Assert(t, LocalName(pair) == "WithAnonymousPair")
+
+	rows := st.Fields[3].Type.(*Named).Underlying.(*Array).Elem.(*Struct)
+	Assert(t, LocalName(rows) == "RowsElem")
+
+	// synthesized names are unique
+	structT = Lookup(testPkg, "WithAnonymousCollisions")
+	an = NewAnalysisFromTypes(testPkg, []types.Type{structT}, nil)
+	st = an.Types[structT].(*Struct)
+	Assert(t, LocalName(st.Fields[0].Type) == "WithAnonymousCollisionsSettings2")
+	index := st.Fields[1].Type.(*Map)
+	Assert(t, LocalName(index.Key) == "WithAnonymousCollisionsIndexKey" && LocalName(index.Elem) == "WithAnonymousCollisionsIndexElem")
+	named := st.Fields[2].Type.(*Named).Underlying.(*Map)
+	Assert(t, LocalName(named.Key) == "IndexKey" && LocalName(named.Elem) == "IndexElem")
+
+	// top level anonymous structs
+	anonymous := types.NewStruct([]*types.Var{types.NewField(0, nil, "A", types.Typ[types.Int], false)}, nil)
+	an = NewAnalysisFromTypes(testPkg, []types.Type{anonymous}, nil)
+	Assert(t, LocalName(an.Types[anonymous]) == "Anonymous1")
+}
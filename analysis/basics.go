@@ -1,6 +1,7 @@
 package analysis
 
 import (
+	"go/token"
 	"go/types"
 	"strings"
 )
@@ -8,7 +9,8 @@ import (
 // AnonymousType are the types which may be
 // seen without associated names.
 // Contrary to the Go language, this package
-// does not support anonymous structs, enums and unions.
+// does not support anonymous enums and unions, and
+// anonymous structs are given a synthesized name (see [Struct.Anonymous]).
 type AnonymousType interface {
 	Type
 
@@ -49,6 +51,12 @@ func (ma *Map) Type() types.Type { return types.NewMap(ma.Key.Type(), ma.Elem.Ty
 type Named struct {
 	name       *types.Named
 	Underlying AnonymousType
+
+	// TypeParams, TypeArgs and Origin have the same
+	// meaning as for [Struct]
+	TypeParams []*TypeParam
+	TypeArgs   []Type
+	Origin     *Named
 }
 
 func (na *Named) Type() types.Type { return na.name }
@@ -94,8 +102,8 @@ type Basic struct {
 func (b *Basic) Kind() BasicKind {
 	info := b.B.Underlying().(*types.Basic).Info()
 	out, ok := NewBasicKind(info)
-	if !ok {
-		panic("unsupported basic kind")
+	if !ok { // should be rejected by the analysis
+		panic(NewError(token.Position{}, CodeUnsupportedType, "unsupported basic type %s", b.B))
 	}
 	return out
 }
@@ -13,7 +13,7 @@ func TestBasicKind(t *testing.T) {
 	basic3 := Lookup(testPkg, "Basic3")
 	basic4 := Lookup(testPkg, "Basic4")
 
-	an := NewAnalysisFromTypes(testPkg, []types.Type{basic1, basic2, basic3, basic4})
+	an := NewAnalysisFromTypes(testPkg, []types.Type{basic1, basic2, basic3, basic4}, nil)
 
 	b1 := an.Types[basic1].(*Named).Underlying.(*Basic)
 	b2 := an.Types[basic2].(*Named).Underlying.(*Basic)
@@ -3,9 +3,11 @@ package analysis
 import (
 	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
 	"reflect"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
@@ -42,6 +44,7 @@ const (
 type SpecialComment struct {
 	Content string
 	Kind    CommentKind
+	Pos     token.Pos // position of the comment, used in diagnostics
 }
 
 type StructField struct {
@@ -50,15 +53,72 @@ type StructField struct {
 	Tag   reflect.StructTag // returned by Struct.Tag()
 }
 
+// jsonTag splits the 'json' struct tag into the name and options.
+func jsonTag(tag reflect.StructTag) (name string, options []string) {
+	name, opts, _ := strings.Cut(tag.Get("json"), ",")
+	if opts != "" {
+		options = strings.Split(opts, ",")
+	}
+	return name, options
+}
+
+func (st StructField) hasJSONOption(option string) bool {
+	_, options := jsonTag(st.Tag)
+	return slices.Contains(options, option)
+}
+
 // JSONName returns the field name used by Go json package,
 // that is, taking into account the json struct tag.
 func (st StructField) JSONName() string {
-	if name := st.Tag.Get("json"); name != "" {
+	if name, _ := jsonTag(st.Tag); name != "" {
 		return name
 	}
 	return st.Field.Name()
 }
 
+// OmitEmpty returns true if the field may be absent from the JSON object, that is
+// if it has the 'omitzero' json option, or the 'omitempty' option and a type
+// with an empty value (structs are never considered empty by the json package).
+func (st StructField) OmitEmpty() bool {
+	if st.hasJSONOption("omitzero") {
+		return true
+	}
+	return st.hasJSONOption("omitempty") && hasEmptyValue(st.Type)
+}
+
+func hasEmptyValue(ty Type) bool {
+	switch ty := ty.(type) {
+	case *Struct, *Time:
+		return false
+	case *Array:
+		return ty.Len <= 0
+	case *Named:
+		return hasEmptyValue(ty.Underlying)
+	case *External:
+		return isExternalEmpty(ty.typ)
+	default:
+		return true
+	}
+}
+
+// AsString returns true if the field has the 'string' json option
+// and a string, number or boolean type, meaning its value
+// is encoded inside a JSON string.
+func (st StructField) AsString() bool {
+	if !st.hasJSONOption("string") {
+		return false
+	}
+	switch ty := st.Type.(type) {
+	case *Basic, *Enum:
+		return true
+	case *Named:
+		_, isBasic := ty.Underlying.(*Basic)
+		return isBasic
+	default:
+		return false
+	}
+}
+
 // Exported returns `true` is the field is exported and should be
 // included in the generated code.
 // Ignored field are either :
@@ -99,9 +159,28 @@ type Struct struct {
 	Fields     []StructField
 	Comments   []SpecialComment
 	Implements []*Union
+
+	// TypeParams is not empty for generic declarations, like Page[T any],
+	// whose fields may then use the parameters.
+	TypeParams []*TypeParam
+	// TypeArgs is not empty for instantiated types, like Page[User],
+	// whose fields use the type arguments.
+	// Origin is then the generic declaration.
+	TypeArgs []Type
+	Origin   *Struct
+
+	// Anonymous is not nil for anonymous structs, like struct{ A int },
+	// whose Name is then synthesized from the parent type and field, like ParentField.
+	Anonymous *types.Struct
 }
 
-func (cl *Struct) Type() types.Type { return cl.Name }
+// Type returns the named type, or the anonymous struct
+func (cl *Struct) Type() types.Type {
+	if cl.Anonymous != nil {
+		return cl.Anonymous
+	}
+	return cl.Name
+}
 
 // setImplements set `Implements` with the union types this class implements,
 // among the ones given.
@@ -125,9 +204,10 @@ func (cl *Struct) setImplements(unions unionsMap, accu map[types.Type]Type) {
 	cl.Implements = out
 }
 
-// findPackage recurses through the imports to find the package `obj` belongs to
+// findPackage recurses through the imports to find the package `obj` belongs to,
+// starting from each of the [roots].
 // it panics if obj if not a user defined type
-func (ps PkgSelector) findPackage(rootPackage *packages.Package, obj *types.TypeName) *packages.Package {
+func (ps PkgSelector) findPackage(roots []*packages.Package, obj *types.TypeName) *packages.Package {
 	var aux func(pa *packages.Package) *packages.Package
 	aux = func(pa *packages.Package) *packages.Package {
 		if obj.Pkg().Path() == pa.PkgPath {
@@ -146,22 +226,25 @@ func (ps PkgSelector) findPackage(rootPackage *packages.Package, obj *types.Type
 		return nil
 	}
 
-	out := aux(rootPackage)
-	if out == nil {
-		panic(fmt.Sprintf("package %s not found", obj.Pkg()))
+	for _, root := range roots {
+		if out := aux(root); out != nil {
+			return out
+		}
 	}
-	return out
+	panic(NewError(token.Position{}, CodeUnsupportedType, "package %s not found", obj.Pkg().Path()))
 }
 
-func fetchStructComments(rootPackage *packages.Package, name *types.Named) (out []SpecialComment) {
-	selector := NewPkgSelector(rootPackage)
+// fetchStructComments returns the special comments of [name],
+// searching its declaration in [roots] (and their imports)
+func fetchStructComments(roots []*packages.Package, name *types.Named, diags *Diagnostics) (out []SpecialComment) {
+	selector := NewPkgSelector(roots[0])
 
 	// ignore non user types
 	if selector.ignorePath(name.Obj().Pkg().Path()) {
 		return nil
 	}
 
-	pa := selector.findPackage(rootPackage, name.Obj())
+	pa := selector.findPackage(roots, name.Obj())
 
 	// make sure pa and name work with the same file set
 	scope := pa.Types.Scope().Lookup(name.Obj().Name())
@@ -177,8 +260,13 @@ func fetchStructComments(rootPackage *packages.Package, name *types.Named) (out
 		return nil
 	}
 	for _, line := range decl.Doc.List {
-		if kind, content := isSpecialComment(line.Text); kind != 0 {
-			out = append(out, SpecialComment{Kind: kind, Content: content})
+		kind, content, err := isSpecialComment(line.Text)
+		if err != nil {
+			diags.Errorf(line.Pos(), CodeInvalidComment, "%s", err)
+			continue
+		}
+		if kind != 0 {
+			out = append(out, SpecialComment{Kind: kind, Content: content, Pos: line.Pos()})
 		}
 	}
 	return out
@@ -188,18 +276,18 @@ var reComment = regexp.MustCompile(`^// gomacro:(\w+) (.+)`)
 
 // isSpecialComment returns a non empty tag if the comment
 // has a special form // gomacro:<tag> <content>
-// it panics if <tag> is unknown
-func isSpecialComment(comment string) (kind CommentKind, content string) {
+// it returns an error if <tag> is unknown
+func isSpecialComment(comment string) (kind CommentKind, content string, err error) {
 	match := reComment.FindStringSubmatch(comment)
 	if len(match) == 0 {
-		return 0, ""
+		return 0, "", nil
 	}
 	switch match[1] {
 	case "SQL":
-		return CommentSQL, match[2]
+		return CommentSQL, match[2], nil
 	case "QUERY":
-		return CommentQuery, match[2]
+		return CommentQuery, match[2], nil
 	default:
-		panic("unknown special comment " + match[1])
+		return 0, "", fmt.Errorf("unknown special comment %s", match[1])
 	}
 }
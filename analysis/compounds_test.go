@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	. "github.com/benoitkugler/gomacro/testutils"
+	"golang.org/x/tools/go/packages"
 )
 
 func TestImplements(t *testing.T) {
@@ -15,7 +16,7 @@ func TestImplements(t *testing.T) {
 	itfType := Lookup(testPkg, "ItfType")
 	itfType2 := Lookup(testPkg, "ItfType2")
 
-	an := NewAnalysisFromTypes(testPkg, []types.Type{itfType, itfType2})
+	an := NewAnalysisFromTypes(testPkg, []types.Type{itfType, itfType2}, nil)
 
 	cl1 := Struct{Name: concretType1}
 	cl2 := Struct{Name: concretType2}
@@ -31,8 +32,8 @@ func TestStructComments(t *testing.T) {
 	concretType1 := Lookup(testPkg, "ConcretType1")
 	concretType2 := Lookup(testPkg, "ConcretType2")
 
-	c1 := fetchStructComments(testPkg, concretType1)
-	c2 := fetchStructComments(testPkg, concretType2)
+	c1 := fetchStructComments([]*packages.Package{testPkg}, concretType1, nil)
+	c2 := fetchStructComments([]*packages.Package{testPkg}, concretType2, nil)
 
 	Assert(t, len(c1) == 0)
 	Assert(t, len(c2) == 2)
@@ -40,9 +41,24 @@ func TestStructComments(t *testing.T) {
 
 func TestJSONTag(t *testing.T) {
 	structT := Lookup(testPkg, "ComplexStruct")
-	an := NewAnalysisFromTypes(testPkg, []types.Type{structT})
+	an := NewAnalysisFromTypes(testPkg, []types.Type{structT}, nil)
 
 	st := an.Types[structT].(*Struct)
 	Assert(t, st.Fields[0].JSONName() == "with_tag")
 	Assert(t, !st.Fields[1].Exported())
 }
+
+func TestJSONOptions(t *testing.T) {
+	structT := Lookup(testPkg, "WithJSONOptions")
+	an := NewAnalysisFromTypes(testPkg, []types.Type{structT}, nil)
+
+	st := an.Types[structT].(*Struct)
+	Assert(t, len(st.Fields) == 7) // Flat is inlined, not Comp
+
+	Assert(t, st.Fields[0].JSONName() == "name" && st.Fields[0].OmitEmpty())
+	Assert(t, st.Fields[1].JSONName() == "Count" && st.Fields[1].AsString() && !st.Fields[1].OmitEmpty())
+	Assert(t, st.Fields[2].JSONName() == "tags" && st.Fields[2].OmitEmpty() && !st.Fields[2].AsString())
+	Assert(t, st.Fields[3].JSONName() == "inner" && !st.Fields[3].OmitEmpty())
+	Assert(t, st.Fields[4].JSONName() == "A" && st.Fields[5].JSONName() == "B")
+	Assert(t, st.Fields[6].JSONName() == "comp")
+}
@@ -0,0 +1,195 @@
+package analysis
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"log"
+	"sort"
+)
+
+// Severity distinguishes errors, which make the generated code incorrect
+// or incomplete, from warnings.
+type Severity uint8
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Code is a stable identifier for a kind of [Diagnostic],
+// which may be used to filter them.
+type Code string
+
+const (
+	// CodeUnsupportedType is used for the Go types which can't be analyzed,
+	// or which are not supported by a generator.
+	CodeUnsupportedType Code = "unsupported-type"
+	// CodeInvalidTag is used for malformed gomacro struct tags.
+	CodeInvalidTag Code = "invalid-tag"
+	// CodeInvalidComment is used for malformed special comments.
+	CodeInvalidComment Code = "invalid-comment"
+	// CodeInvalidSQL is used for inconsistent SQL definitions, like
+	// unknown columns in constraints or invalid foreign keys.
+	CodeInvalidSQL Code = "invalid-sql"
+	// CodeUnsupportedRoute is used for the HTTP routes which can't be scanned.
+	CodeUnsupportedRoute Code = "unsupported-route"
+	// CodeIgnoredField is used for the struct fields which are (partially) ignored.
+	CodeIgnoredField Code = "ignored-field"
+)
+
+// Diagnostic is a problem found when analyzing the source
+// code or generating the output files.
+type Diagnostic struct {
+	Pos      token.Position // may be invalid if unknown
+	Severity Severity
+	Code     Code
+	Message  string
+}
+
+// NewError returns an error diagnostic, which may be raised with panic
+// and then recovered by [Diagnostics.Try].
+// If [pos] is not valid, the position given to [Diagnostics.Try] is used.
+func NewError(pos token.Position, code Code, format string, args ...any) Diagnostic {
+	return Diagnostic{Pos: pos, Severity: SeverityError, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// String returns the diagnostic in the usual
+// file:line:col: <severity>: <message> [<code>] format
+func (d Diagnostic) String() string {
+	out := fmt.Sprintf("%s: %s [%s]", d.Severity, d.Message, d.Code)
+	if d.Pos.IsValid() {
+		out = d.Pos.String() + ": " + out
+	}
+	return out
+}
+
+// Error implements error, so that non recovered
+// diagnostics are properly printed.
+func (d Diagnostic) Error() string { return d.String() }
+
+// Diagnostics collects the problems found when analyzing
+// the source code or generating the output files, so that all of them
+// may be reported at once.
+//
+// A nil *Diagnostics is valid : errors are then raised with panic, and warnings
+// are logged, which is convenient in tests.
+type Diagnostics struct {
+	fset *token.FileSet
+	list []Diagnostic
+}
+
+// NewDiagnostics returns an empty collector, using [fset]
+// (shared by the loaded packages) to resolve the positions.
+func NewDiagnostics(fset *token.FileSet) *Diagnostics {
+	return &Diagnostics{fset: fset}
+}
+
+func (ds *Diagnostics) position(pos token.Pos) token.Position {
+	if ds == nil || ds.fset == nil || !pos.IsValid() {
+		return token.Position{}
+	}
+	return ds.fset.Position(pos)
+}
+
+// Add records [d], or panics if [ds] is nil and [d] is an error.
+func (ds *Diagnostics) Add(d Diagnostic) {
+	if ds == nil {
+		if d.Severity == SeverityError {
+			panic(d)
+		}
+		log.Println("gomacro:", d)
+		return
+	}
+	ds.list = append(ds.list, d)
+}
+
+// Errorf adds an error at [pos].
+func (ds *Diagnostics) Errorf(pos token.Pos, code Code, format string, args ...any) {
+	ds.Add(NewError(ds.position(pos), code, format, args...))
+}
+
+// Warnf adds a warning at [pos].
+func (ds *Diagnostics) Warnf(pos token.Pos, code Code, format string, args ...any) {
+	d := NewError(ds.position(pos), code, format, args...)
+	d.Severity = SeverityWarning
+	ds.Add(d)
+}
+
+// Try calls [f], recovering the [Diagnostic] raised with panic, which are then
+// added to the list, using [pos] as default position.
+// It returns false if [f] has been interrupted.
+// Other panics are not recovered, since they indicate a bug in gomacro.
+// If [ds] is nil, [f] is simply called.
+func (ds *Diagnostics) Try(pos token.Pos, f func()) (ok bool) {
+	if ds == nil {
+		f()
+		return true
+	}
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		d, isDiagnostic := r.(Diagnostic)
+		if !isDiagnostic {
+			panic(r)
+		}
+		if !d.Pos.IsValid() {
+			d.Pos = ds.position(pos)
+		}
+		ds.Add(d)
+		ok = false
+	}()
+	f()
+	return true
+}
+
+// ErrorCount returns the number of errors (ignoring warnings).
+func (ds *Diagnostics) ErrorCount() int {
+	if ds == nil {
+		return 0
+	}
+	out := 0
+	for _, d := range ds.list {
+		if d.Severity == SeverityError {
+			out++
+		}
+	}
+	return out
+}
+
+// List returns the diagnostics, sorted by position.
+func (ds *Diagnostics) List() []Diagnostic {
+	if ds == nil {
+		return nil
+	}
+	out := append([]Diagnostic(nil), ds.list...)
+	sort.SliceStable(out, func(i, j int) bool {
+		pi, pj := out[i].Pos, out[j].Pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		return pi.Column < pj.Column
+	})
+	return out
+}
+
+// DeclarationPos returns the position of the declaration of [typ],
+// or [token.NoPos] for types without names.
+func DeclarationPos(typ types.Type) token.Pos {
+	if named, isNamed := typ.(interface{ Obj() *types.TypeName }); isNamed {
+		return named.Obj().Pos()
+	}
+	return token.NoPos
+}
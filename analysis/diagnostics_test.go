@@ -0,0 +1,60 @@
+package analysis
+
+import (
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/benoitkugler/gomacro/testutils"
+)
+
+func TestDiagnostics(t *testing.T) {
+	sourceFile := "../testutils/testsource/invalid/invalid.go"
+	pa, err := LoadSource(sourceFile)
+	Assert(t, err == nil)
+
+	diags := NewDiagnostics(pa.Fset)
+	an := NewAnalysisFromFile(pa, sourceFile, diags)
+
+	list := diags.List()
+	Assert(t, len(list) == 7 && diags.ErrorCount() == 7)
+	codes := []Code{CodeInvalidComment, CodeUnsupportedType, CodeInvalidTag, CodeUnsupportedType, CodeUnsupportedType, CodeUnsupportedType, CodeUnsupportedType} // sorted by position
+	for i, d := range list {
+		Assert(t, d.Code == codes[i])
+		Assert(t, filepath.Base(d.Pos.Filename) == "invalid.go" && d.Pos.Line > 0 && d.Pos.Column > 0)
+		Assert(t, strings.HasPrefix(d.String(), d.Pos.String()+": error: "))
+	}
+	Assert(t, list[0].Pos.Line == 13 && list[1].Pos.Line == 16 && list[2].Pos.Line == 17)
+	// complex types are rejected, and Phase is not an enum
+	Assert(t, list[3].Pos.Line == 22 && list[4].Pos.Line == 29 && list[5].Pos.Line == 30)
+	Assert(t, strings.Contains(list[4].Message, "unsupported basic type complex64"))
+	// instantiations must have distinct monomorphized names
+	Assert(t, list[6].Pos.Line == 46 && strings.Contains(list[6].Message, "same name PairIntListInt"))
+
+	// the valid fields and types are still analyzed
+	broken := an.Types[Lookup(pa, "Broken")].(*Struct)
+	Assert(t, len(broken.Fields) == 2)
+	Assert(t, broken.Fields[0].Field.Name() == "ID" && broken.Fields[1].Field.Name() == "Event")
+	measure := an.Types[Lookup(pa, "Measure")].(*Struct)
+	Assert(t, len(measure.Fields) == 2)
+	Assert(t, measure.Fields[0].Field.Name() == "Value" && measure.Fields[1].Field.Name() == "Offset")
+	instantiations := an.Types[Lookup(pa, "Instantiations")].(*Struct)
+	Assert(t, len(instantiations.Fields) == 2 && instantiations.Fields[0].Type == instantiations.Fields[1].Type)
+	Assert(t, len(an.Source) == 6)
+}
+
+func TestDiagnosticsNil(t *testing.T) {
+	var diags *Diagnostics
+	ShouldPanic(t, func() { diags.Errorf(0, CodeInvalidTag, "invalid") })
+	diags.Warnf(0, CodeIgnoredField, "only logged")
+	Assert(t, diags.ErrorCount() == 0 && diags.List() == nil)
+
+	// Try only recovers diagnostics
+	diags = NewDiagnostics(nil)
+	ok := diags.Try(0, func() { panic(NewError(token.Position{}, CodeUnsupportedType, "unsupported")) })
+	Assert(t, !ok && diags.ErrorCount() == 1)
+	ShouldPanic(t, func() { diags.Try(0, func() { panic("internal error") }) })
+	Assert(t, diags.Try(0, func() {}))
+	Assert(t, diags.List()[0].String() == "error: unsupported [unsupported-type]")
+}
@@ -1,9 +1,9 @@
 package analysis
 
 import (
-	"fmt"
 	"go/ast"
 	"go/constant"
+	"go/token"
 	"go/types"
 	"sort"
 	"strings"
@@ -51,8 +51,8 @@ func (e *Enum) Underlying() *types.Basic {
 func (e *Enum) Kind() BasicKind {
 	info := e.Underlying().Info()
 	out, ok := NewBasicKind(info)
-	if !ok {
-		panic("unsupported basic kind")
+	if !ok { // should be rejected by fetchPkgEnums
+		panic(NewError(token.Position{}, CodeUnsupportedType, "unsupported basic type %s for enum %s", e.Underlying(), e.name))
 	}
 	return out
 }
@@ -62,14 +62,14 @@ func (e *Enum) Kind() BasicKind {
 func (e *Enum) IsInteger() bool { return e.Kind() == BKInt }
 
 // Get return the enum value with Go name [name].
-// It panics if [name] is not found
+// It panics with a [Diagnostic] if [name] is not found
 func (e *Enum) Get(name string) EnumMember {
 	for _, m := range e.Members {
 		if m.Const.Name() == name {
 			return m
 		}
 	}
-	panic(fmt.Sprintf("enum value %s not found", name))
+	panic(NewError(token.Position{}, CodeInvalidComment, "enum value %s.%s not found", e.name.Obj().Name(), name))
 }
 
 type sortBy struct {
@@ -147,6 +147,9 @@ func fetchPkgEnums(pa *packages.Package) enumsMap {
 			continue
 		}
 		// per the spec, only basic types may be constant
+		if _, ok := NewBasicKind(named.Underlying().(*types.Basic).Info()); !ok {
+			continue // not supported, reported when analyzing the type
+		}
 
 		comment := fetchConstComment(pa, decl)
 		if strings.Contains(comment, IgnoreDeclComment) { // this value does not implies an enum
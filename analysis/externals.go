@@ -0,0 +1,204 @@
+package analysis
+
+import (
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// JSONKind is the kind of JSON value produced
+// by the Go json package for an [External] type.
+type JSONKind string
+
+const (
+	JSONAny     JSONKind = "" // any JSON value
+	JSONString  JSONKind = "string"
+	JSONNumber  JSONKind = "number"
+	JSONBoolean JSONKind = "boolean"
+)
+
+// ExternalType describes how a Go type not handled by the analysis
+// (like uuid.UUID or decimal.Decimal) is represented by the generators.
+// Empty fields are deduced from [JSON].
+type ExternalType struct {
+	// JSON is the kind of the JSON value
+	JSON JSONKind
+
+	// TypeScript is the TS type, like "string"
+	TypeScript string
+
+	// Dart is the Dart type, like "String"
+	Dart string
+	// DartFromJSON and DartToJSON are the names of the Dart
+	// functions converting from and to JSON, defaulting to a cast.
+	DartFromJSON, DartToJSON string
+	// DartImport is the Dart file defining [DartFromJSON] and [DartToJSON], if any.
+	DartImport string
+
+	// SQL is the SQL column type, like "uuid".
+	// The Go type must then implement sql.Scanner and driver.Valuer.
+	SQL string
+
+	// Randdata is the name of a function returning a random value,
+	// which must be defined in the package of the generated code.
+	// If empty, the zero value is used.
+	Randdata string
+}
+
+// TypeScriptType returns the TS type, or its default value.
+func (ext ExternalType) TypeScriptType() string {
+	if ext.TypeScript != "" {
+		return ext.TypeScript
+	}
+	switch ext.JSON {
+	case JSONString:
+		return "string"
+	case JSONNumber:
+		return "number"
+	case JSONBoolean:
+		return "boolean"
+	default:
+		return "unknown"
+	}
+}
+
+// DartType returns the Dart type, or its default value.
+func (ext ExternalType) DartType() string {
+	if ext.Dart != "" {
+		return ext.Dart
+	}
+	switch ext.JSON {
+	case JSONString:
+		return "String"
+	case JSONNumber:
+		return "num"
+	case JSONBoolean:
+		return "bool"
+	default:
+		return "dynamic"
+	}
+}
+
+// SQLType returns the SQL type, or its default value.
+func (ext ExternalType) SQLType() string {
+	if ext.SQL != "" {
+		return ext.SQL
+	}
+	switch ext.JSON {
+	case JSONString:
+		return "text"
+	case JSONNumber:
+		return "numeric"
+	case JSONBoolean:
+		return "boolean"
+	default:
+		return "jsonb"
+	}
+}
+
+// ExternalTypes maps fully qualified Go types, such as
+// "github.com/google/uuid.UUID", to their representation.
+type ExternalTypes map[string]ExternalType
+
+// externalTypes is the registry used by the analysis,
+// see [RegisterExternalType]
+var externalTypes = ExternalTypes{
+	"github.com/google/uuid.UUID":           {JSON: JSONString, SQL: "uuid"},
+	"github.com/shopspring/decimal.Decimal": {JSON: JSONString, SQL: "numeric"},
+	"encoding/json.RawMessage":              {JSON: JSONAny, SQL: "jsonb"},
+	"net/netip.Addr":                        {JSON: JSONString, SQL: "inet"},
+	"math/big.Int":                          {JSON: JSONNumber, SQL: "numeric"},
+}
+
+// RegisterExternalType adds (or replaces) the mapping for the Go type [qualifiedName],
+// like "github.com/google/uuid.UUID", which is then used by all the generators
+// instead of analyzing the type.
+// It must be called before the analysis.
+func RegisterExternalType(qualifiedName string, ext ExternalType) {
+	externalTypes[qualifiedName] = ext
+}
+
+// qualifiedName returns <pkg path>.<name> for named types and aliases,
+// or an empty string
+func qualifiedName(typ types.Type) string {
+	named, isNamed := typ.(interface{ Obj() *types.TypeName })
+	if !isNamed || named.Obj().Pkg() == nil {
+		return ""
+	}
+	return named.Obj().Pkg().Path() + "." + named.Obj().Name()
+}
+
+// lookupExternal returns the registered mapping for [typ],
+// looking for the alias name first
+func lookupExternal(typ types.Type) (ExternalType, bool) {
+	if ext, has := externalTypes[qualifiedName(typ)]; has {
+		return ext, true
+	}
+	ext, has := externalTypes[qualifiedName(types.Unalias(typ))]
+	return ext, has
+}
+
+// newExternalFromTag returns the [External] type defined by
+// a 'gomacro-type' tag, completing the registered mapping, if any.
+// The tag is a comma separated list of <key>:<value>, with keys
+// json, typescript, dart, dart-from-json, dart-to-json, dart-import, sql and randdata.
+// For pointers, the tag applies to the element type.
+func newExternalFromTag(typ types.Type, tag string) Type {
+	ext, _ := lookupExternal(typ)
+	if ptr, isPointer := types.Unalias(typ).(*types.Pointer); isPointer {
+		return &Pointer{Elem: newExternalFromTag(ptr.Elem(), tag)}
+	}
+	if qualifiedName(typ) == "" {
+		panic(NewError(token.Position{}, CodeInvalidTag, "invalid gomacro-type tag %q: %s is not a named type", tag, typ))
+	}
+
+	for _, chunk := range strings.Split(tag, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(chunk), ":")
+		if !ok {
+			panic(NewError(token.Position{}, CodeInvalidTag, "invalid gomacro-type tag %q: expected <key>:<value>", tag))
+		}
+		switch key {
+		case "json":
+			ext.JSON = JSONKind(value)
+		case "typescript":
+			ext.TypeScript = value
+		case "dart":
+			ext.Dart = value
+		case "dart-from-json":
+			ext.DartFromJSON = value
+		case "dart-to-json":
+			ext.DartToJSON = value
+		case "dart-import":
+			ext.DartImport = value
+		case "sql":
+			ext.SQL = value
+		case "randdata":
+			ext.Randdata = value
+		default:
+			panic(NewError(token.Position{}, CodeInvalidTag, "invalid gomacro-type tag %q: unknown key %s", tag, key))
+		}
+	}
+	return &External{typ: typ, Mapping: ext}
+}
+
+// External is a Go type whose representation is provided by
+// an [ExternalType], either registered or defined with a 'gomacro-type' struct tag.
+type External struct {
+	typ types.Type
+
+	Mapping ExternalType
+}
+
+func (ex *External) Type() types.Type { return ex.typ }
+
+// isExternalEmpty mimics the json package behavior for 'omitempty'
+func isExternalEmpty(typ types.Type) bool {
+	switch typ := typ.Underlying().(type) {
+	case *types.Struct:
+		return false
+	case *types.Array:
+		return typ.Len() == 0
+	default:
+		return true
+	}
+}
@@ -0,0 +1,50 @@
+package analysis
+
+import (
+	"go/types"
+	"testing"
+
+	. "github.com/benoitkugler/gomacro/testutils"
+)
+
+func TestExternals(t *testing.T) {
+	structT := Lookup(testPkg, "WithExternals")
+	an := NewAnalysisFromTypes(testPkg, []types.Type{structT}, nil)
+
+	st := an.Types[structT].(*Struct)
+	Assert(t, len(st.Fields) == 5)
+
+	raw := st.Fields[0].Type.(*External)
+	Assert(t, raw.Mapping.JSON == JSONAny && raw.Mapping.SQLType() == "jsonb" && raw.Mapping.TypeScriptType() == "unknown")
+	Assert(t, raw.Type().String() == "encoding/json.RawMessage" && LocalName(raw) == "RawMessage") // the alias is kept
+
+	addr := st.Fields[1].Type.(*External)
+	Assert(t, addr.Mapping.SQL == "inet" && addr.Mapping.DartType() == "String")
+	Assert(t, st.Fields[3].Type.(*Array).Elem == addr)
+
+	amount := st.Fields[2].Type.(*Pointer).Elem.(*External)
+	Assert(t, amount.Mapping.JSON == JSONNumber)
+
+	timeout := st.Fields[4].Type.(*External)
+	Assert(t, timeout.Mapping == ExternalType{
+		JSON: JSONNumber, TypeScript: "Duration", Dart: "Duration",
+		DartFromJSON: "durationFromJson", DartToJSON: "durationToJson", DartImport: "package:app/duration.dart",
+		SQL: "interval", Randdata: "randDuration",
+	})
+	// the tag does not change the analysis of the type
+	_, isNamed := an.Types[timeout.Type()]
+	Assert(t, !isNamed)
+}
+
+func TestRegisterExternalType(t *testing.T) {
+	defer delete(externalTypes, "github.com/benoitkugler/gomacro/testutils/testsource.Comp")
+
+	RegisterExternalType("github.com/benoitkugler/gomacro/testutils/testsource.Comp", ExternalType{JSON: JSONString})
+
+	structT := Lookup(testPkg, "WithJSONOptions")
+	an := NewAnalysisFromTypes(testPkg, []types.Type{structT}, nil)
+	st := an.Types[structT].(*Struct)
+	Assert(t, len(st.Fields) == 6) // Flat is not inlined anymore
+	_, isExternal := st.Fields[3].Type.(*External)
+	Assert(t, isExternal)
+}
@@ -0,0 +1,117 @@
+package analysis
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// TypeParam is a type parameter of a generic declaration,
+// like T in Page[T any].
+// It is only found in the fields (or underlying type) of
+// the declaration, instantiated types using the actual type arguments.
+type TypeParam struct {
+	T *types.TypeParam
+}
+
+func (tp *TypeParam) Type() types.Type { return tp.T }
+
+// Name returns the name of the parameter, like T
+func (tp *TypeParam) Name() string { return tp.T.Obj().Name() }
+
+func (*TypeParam) isAnonymous() {}
+
+// IsGenericDeclaration returns true for the generic types which are not instantiated,
+// like Page[T any], for which most generators only handle the instantiations.
+func IsGenericDeclaration(ty Type) bool {
+	switch ty := ty.(type) {
+	case *Struct:
+		return len(ty.TypeParams) != 0
+	case *Named:
+		return len(ty.TypeParams) != 0
+	default:
+		return false
+	}
+}
+
+// handleTypeParams returns the type parameters of the generic declaration [name],
+// or nil
+func (an *Analysis) handleTypeParams(name *types.Named, ctx context) (out []*TypeParam) {
+	if name.TypeArgs().Len() != 0 { // instantiated type
+		return nil
+	}
+	params := name.TypeParams()
+	for i := 0; i < params.Len(); i++ {
+		out = append(out, an.handleType(params.At(i), ctx).(*TypeParam))
+	}
+	return out
+}
+
+// handleTypeArgs returns the type arguments of the instantiated type [name],
+// or nil
+// It panics if the [MonomorphizedName] of [name] is already used by another instantiation.
+func (an *Analysis) handleTypeArgs(name *types.Named, ctx context) (out []Type) {
+	args := name.TypeArgs()
+	if args.Len() != 0 {
+		an.checkMonomorphizedName(name)
+	}
+	for i := 0; i < args.Len(); i++ {
+		out = append(out, an.handleType(args.At(i), ctx)) // recurse
+	}
+	return out
+}
+
+// checkMonomorphizedName registers the instantiation [name], making sure
+// that its monomorphized name is not shared with another instantiation,
+// like Pair[ListInt, int] and Pair[[]int, int].
+func (an *Analysis) checkMonomorphizedName(name *types.Named) {
+	if an.instantiations == nil {
+		an.instantiations = make(map[string]*types.Named)
+	}
+	key := name.Obj().Pkg().Path() + "." + MonomorphizedName(name)
+	if other, has := an.instantiations[key]; has && !types.Identical(other, name) {
+		panic(NewError(token.Position{}, CodeUnsupportedType, "instantiations %s and %s have the same name %s", other, name, MonomorphizedName(name)))
+	}
+	an.instantiations[key] = name
+}
+
+// MonomorphizedName returns the local name of [typ], followed by its
+// type arguments, if any, so that it may be used by targets without generics.
+// For instance, Page[User] is mapped to PageUser, and Pair[string, []int] to PairStringListInt.
+func MonomorphizedName(typ types.Type) string {
+	switch typ := typ.(type) {
+	case *types.Named:
+		out := typ.Obj().Name()
+		args := typ.TypeArgs()
+		for i := 0; i < args.Len(); i++ {
+			out += typeArgName(args.At(i))
+		}
+		return out
+	case *types.Alias:
+		return typ.Obj().Name()
+	default:
+		return typeArgName(typ)
+	}
+}
+
+func typeArgName(typ types.Type) string {
+	switch typ := typ.(type) {
+	case *types.Named, *types.Alias:
+		return MonomorphizedName(typ)
+	case *types.Basic:
+		return strings.ToUpper(typ.Name()[:1]) + typ.Name()[1:]
+	case *types.TypeParam:
+		return typ.Obj().Name()
+	case *types.Pointer:
+		return "Ptr" + typeArgName(typ.Elem())
+	case *types.Slice:
+		return "List" + typeArgName(typ.Elem())
+	case *types.Array:
+		return fmt.Sprintf("Array%d%s", typ.Len(), typeArgName(typ.Elem()))
+	case *types.Map:
+		return "Map" + typeArgName(typ.Key()) + typeArgName(typ.Elem())
+	default:
+		panic(NewError(token.Position{}, CodeUnsupportedType, "unsupported type argument %s", typ))
+	}
+}
@@ -0,0 +1,62 @@
+package analysis
+
+import (
+	"go/types"
+	"path/filepath"
+	"testing"
+
+	. "github.com/benoitkugler/gomacro/testutils"
+)
+
+func TestGenerics(t *testing.T) {
+	structT := Lookup(testPkg, "WithGenerics")
+	an := NewAnalysisFromTypes(testPkg, []types.Type{structT}, nil)
+
+	st := an.Types[structT].(*Struct)
+	Assert(t, len(st.Fields) == 5)
+
+	comps := st.Fields[0].Type.(*Struct)
+	Assert(t, len(comps.TypeParams) == 0 && len(comps.TypeArgs) == 1)
+	Assert(t, comps.TypeArgs[0] == an.Types[Lookup(testPkg, "Comp")])
+	// fields of instantiated types use the type arguments
+	Assert(t, comps.Fields[0].Type.(*Array).Elem == comps.TypeArgs[0])
+
+	// the generic declaration is also analyzed
+	page := comps.Origin
+	Assert(t, page == an.Types[Lookup(testPkg, "Page")])
+	Assert(t, len(page.TypeParams) == 1 && page.TypeParams[0].Name() == "T")
+	Assert(t, page.Fields[0].Type.(*Array).Elem == page.TypeParams[0])
+	Assert(t, page.Fields[1].Type.(*Pointer).Elem == page.TypeParams[0])
+	Assert(t, st.Fields[1].Type.(*Struct).Origin == page)
+
+	pair := st.Fields[2].Type.(*Struct)
+	Assert(t, len(pair.TypeArgs) == 2 && len(pair.Origin.TypeParams) == 2)
+
+	names := st.Fields[3].Type.(*Named)
+	Assert(t, names.TypeArgs[0] == String || names.TypeArgs[0].Type() == types.Typ[types.String])
+	Assert(t, names.Origin.Underlying.(*Array).Elem == names.Origin.TypeParams[0])
+
+	nested := st.Fields[4].Type.(*Struct)
+	Assert(t, nested.Origin == page && nested.TypeArgs[0].(*Named).Origin == names.Origin)
+}
+
+func TestMonomorphizedName(t *testing.T) {
+	structT := Lookup(testPkg, "WithGenerics").Underlying().(*types.Struct)
+	for i, exp := range []string{"PageComp", "PageInt", "PairStringEnumInt", "BatchString", "PageBatchComp"} {
+		Assert(t, MonomorphizedName(structT.Field(i).Type()) == exp)
+	}
+	Assert(t, MonomorphizedName(Lookup(testPkg, "Page")) == "Page")
+	Assert(t, MonomorphizedName(types.NewSlice(types.NewMap(types.Typ[types.String], types.Typ[types.Int]))) == "ListMapStringInt")
+}
+
+func TestGenericsDeclarations(t *testing.T) {
+	// generic declarations are supported as source
+	fn, err := filepath.Abs("../testutils/testsource/other_file.go")
+	Assert(t, err == nil)
+	an := NewAnalysisFromFile(testPkg, fn, nil)
+
+	generic := an.Types[Lookup(testPkg, "Generic")].(*Struct)
+	Assert(t, len(generic.TypeParams) == 1)
+	_, isParam := generic.Fields[0].Type.(*TypeParam)
+	Assert(t, isParam)
+}
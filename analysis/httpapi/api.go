@@ -1,17 +1,62 @@
 package httpapi
 
 import (
+	"fmt"
+	"go/token"
 	"go/types"
+	"sort"
+	"strings"
 
 	"github.com/benoitkugler/gomacro/analysis"
 )
 
 // Endpoint describes one server endpoint
 type Endpoint struct {
+	pos token.Pos // route registration, used in diagnostics
+
 	Url       string
 	Method    string // GET, POST, etc
 	IsUrlOnly bool
 	Contract  Contract
+
+	// Invalidates lists the URLs of the GET endpoints whose data
+	// is modified by this endpoint, as defined by an
+	// 'invalidates: <url>, <url>' comment on the route line.
+	Invalidates []string
+}
+
+// Pos returns the position of the route registration,
+// to be used when reporting diagnostics about [ep].
+func (ep Endpoint) Pos() token.Pos { return ep.pos }
+
+// checkTypes returns an error if one the types used by [ep] is not supported,
+// either because its analysis failed, or because it can't be used as
+// a query or path parameter.
+func (ep Endpoint) checkTypes() error {
+	ct := ep.Contract
+	if ct.inputT != nil && ct.InputBody == nil {
+		return fmt.Errorf("unsupported body type %s", ct.inputT)
+	}
+	if ct.returnT != nil && ct.Return == nil {
+		return fmt.Errorf("unsupported return type %s", ct.returnT)
+	}
+	for code, ty := range ct.responsesT {
+		if ty != nil && ct.Responses[code] == nil {
+			return fmt.Errorf("unsupported response type %s", ty)
+		}
+	}
+	if ct.InputForm.JSON.Name != "" && ct.InputForm.JSON.Type == nil {
+		return fmt.Errorf("unsupported form type %s", ct.InputForm.JSON.type_)
+	}
+	for _, param := range append(ct.InputQueryParams[:len(ct.InputQueryParams):len(ct.InputQueryParams)], ct.PathParams...) {
+		if param.type_ == nil {
+			return fmt.Errorf("unresolved type for parameter %s", param.Name)
+		}
+		if !param.isBasic() {
+			return fmt.Errorf("unsupported type %s for parameter %s", param.type_, param.Name)
+		}
+	}
+	return nil
 }
 
 type TypedParam struct {
@@ -24,14 +69,29 @@ func (tp *TypedParam) resolveType(an *analysis.Analysis) {
 	tp.Type = an.Types[tp.type_]
 }
 
+// isBasic returns true if the parameter may be converted to a string,
+// that is if it is a basic type, an enum, or a named basic type.
+func (tp TypedParam) isBasic() bool {
+	switch ty := tp.Type.(type) {
+	case *analysis.Basic, *analysis.Enum:
+		return true
+	case *analysis.Named:
+		_, isBasic := ty.Underlying.(*analysis.Basic)
+		return isBasic
+	default:
+		return false
+	}
+}
+
 // Contract describes the expected and returned
 // types for one endpoint.
 type Contract struct {
 	Name string
 
 	// field used during parsing
-	inputT  types.Type
-	returnT types.Type
+	inputT     types.Type
+	returnT    types.Type
+	responsesT map[int]types.Type
 
 	InputBody analysis.Type
 
@@ -41,9 +101,139 @@ type Contract struct {
 	InputForm        Form
 	InputQueryParams []TypedParam
 
+	// PathParams are the parameters defined in the URL,
+	// such as {id} for /items/{id}, in order of appearance.
+	PathParams []TypedParam
+
 	IsReturnBlob bool // [Return] is a []byte, interpreted as a file
 
 	IsReturnStream bool // JSON stream of type [Return]
+
+	// Responses maps the status codes found in the handler
+	// to the type of their JSON payload, which is nil
+	// for empty responses or untyped error messages.
+	Responses map[int]analysis.Type
+}
+
+// addResponse registers the response for [code], overriding
+// a previous response without payload.
+// Dynamic payloads, like map[string]any, are not typed.
+func (ct *Contract) addResponse(code int, ty types.Type) {
+	if ty != nil && isDynamic(ty) {
+		ty = nil
+	}
+	if ct.responsesT == nil {
+		ct.responsesT = make(map[int]types.Type)
+	}
+	if ty != nil || ct.responsesT[code] == nil {
+		ct.responsesT[code] = ty
+	}
+}
+
+// isDynamic returns true for interface{}, and maps and slices of interface{}
+func isDynamic(ty types.Type) bool {
+	switch u := ty.Underlying().(type) {
+	case *types.Interface:
+		return u.Empty()
+	case *types.Map:
+		return isDynamic(u.Elem())
+	case *types.Slice:
+		return isDynamic(u.Elem())
+	default:
+		return false
+	}
+}
+
+// ErrorResponses returns the status codes (>= 400) of [Responses],
+// sorted in increasing order.
+func (ct Contract) ErrorResponses() []int {
+	var out []int
+	for code := range ct.Responses {
+		if code >= 400 {
+			out = append(out, code)
+		}
+	}
+	sort.Ints(out)
+	return out
+}
+
+// parseSegment returns the parameter name defined by [segment],
+// which may use the following syntaxes :
+//   - :name and * (Echo), *name (Gin)
+//   - {name}, {name...} (net/http) and {name:regexp} (Chi)
+//
+// The anonymous wildcard * is returned as is. [isWildcard] is true
+// if the parameter may contain slashes.
+func parseSegment(segment string) (name string, isWildcard, isParam bool) {
+	switch {
+	case strings.HasPrefix(segment, ":"):
+		return segment[1:], false, true
+	case segment == "*":
+		return segment, true, true
+	case strings.HasPrefix(segment, "*"):
+		return segment[1:], true, true
+	case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+		name = segment[1 : len(segment)-1]
+		name, _, _ = strings.Cut(name, ":")
+		name, isWildcard = strings.CutSuffix(name, "...")
+		return name, isWildcard, true
+	default:
+		return "", false, false
+	}
+}
+
+// pathParamNames returns the names of the parameters in [path]
+func pathParamNames(path string) []string {
+	var out []string
+	for _, segment := range strings.Split(path, "/") {
+		if name, _, isParam := parseSegment(segment); isParam {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// PathChunk is a part of an URL path : either a constant string
+// or a path parameter.
+type PathChunk struct {
+	Static string
+	Param  *TypedParam // nil for static chunks
+
+	IsWildcard bool // true if [Param] may contain slashes
+}
+
+// PathChunks splits [Url] into static strings and path parameters,
+// so that clients may interpolate the parameters.
+func (e Endpoint) PathChunks() []PathChunk {
+	var (
+		out    []PathChunk
+		static string
+	)
+	for i, segment := range strings.Split(e.Url, "/") {
+		if i != 0 {
+			static += "/"
+		}
+		name, isWildcard, isParam := parseSegment(segment)
+		var param *TypedParam
+		for j, p := range e.Contract.PathParams {
+			if isParam && p.Name == name {
+				param = &e.Contract.PathParams[j]
+			}
+		}
+		if param == nil { // static segment
+			static += segment
+			continue
+		}
+		if static != "" {
+			out = append(out, PathChunk{Static: static})
+			static = ""
+		}
+		out = append(out, PathChunk{Param: param, IsWildcard: isWildcard})
+	}
+	if static != "" {
+		out = append(out, PathChunk{Static: static})
+	}
+	return out
 }
 
 type Form struct {
@@ -71,17 +261,38 @@ const (
 	_ specialComment = iota
 	ignore
 	urlOnly
+	invalidates // invalidates: <url>, <url>
 )
 
+const invalidatesPrefix = "invalidates:"
+
 func newSpecialComment(comment string) specialComment {
-	switch comment {
-	case "":
+	switch {
+	case comment == "":
 		return 0
-	case "ignore":
+	case comment == "ignore":
 		return ignore
-	case "url-only":
+	case comment == "url-only":
 		return urlOnly
+	case strings.HasPrefix(comment, invalidatesPrefix):
+		return invalidates
 	default:
-		panic("invalid special comment " + comment)
+		panic(analysis.NewError(token.Position{}, analysis.CodeInvalidComment, "invalid special comment %s", comment))
+	}
+}
+
+// parseInvalidates returns the URLs listed in a
+// 'invalidates: <url>, <url>' comment, or nil
+func parseInvalidates(comment string) []string {
+	list, ok := strings.CutPrefix(comment, invalidatesPrefix)
+	if !ok {
+		return nil
+	}
+	var out []string
+	for _, url := range strings.Split(list, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			out = append(out, url)
+		}
 	}
+	return out
 }
@@ -0,0 +1,238 @@
+package httpapi
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// routerGroup is the URL prefix and the middlewares
+// shared by the routes of a group
+type routerGroup struct {
+	prefix      string
+	middlewares []ast.Expr
+}
+
+func (rg routerGroup) isZero() bool { return rg.prefix == "" && len(rg.middlewares) == 0 }
+
+// routerGroups tracks the router variables (groups), created with
+//   - g := r.Group(prefix, middlewares...) (Echo, Gin)
+//   - r.Route(prefix, func(sub chi.Router) { ... }) and r.Group(func(sub chi.Router) { ... }) (Chi)
+//   - r.With(middlewares...) (Chi)
+//
+// Middlewares added with r.Use(middlewares...) are also recorded.
+type routerGroups struct {
+	pkg    *packages.Package
+	diags  *analysis.Diagnostics // used to report invalid routes
+	groups map[types.Object]routerGroup
+}
+
+func newRouterGroups(pkg *packages.Package, diags *analysis.Diagnostics) routerGroups {
+	return routerGroups{pkg: pkg, diags: diags, groups: make(map[types.Object]routerGroup)}
+}
+
+// middlewareArgs returns the arguments which are not
+// function litterals (used by chi)
+func middlewareArgs(args []ast.Expr) []ast.Expr {
+	var out []ast.Expr
+	for _, arg := range args {
+		if _, isFunc := arg.(*ast.FuncLit); !isFunc {
+			out = append(out, arg)
+		}
+	}
+	return out
+}
+
+// group returns the group for the router [expr]
+func (rg routerGroups) group(expr ast.Expr) routerGroup {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return rg.group(expr.X)
+	case *ast.Ident:
+		return rg.groups[resolveIdentifier(expr, rg.pkg.TypesInfo)]
+	case *ast.CallExpr:
+		selector, ok := expr.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return routerGroup{}
+		}
+		switch selector.Sel.Name {
+		case "Group", "Route":
+			parent := rg.group(selector.X)
+			out := routerGroup{prefix: parent.prefix, middlewares: parent.middlewares}
+			args := expr.Args
+			if len(args) != 0 {
+				if path, err := resolveConstString(args[0], rg.pkg); err == nil {
+					out.prefix += path
+					args = args[1:]
+				}
+			}
+			out.middlewares = append(out.middlewares[:len(out.middlewares):len(out.middlewares)], middlewareArgs(args)...)
+			return out
+		case "With":
+			parent := rg.group(selector.X)
+			return routerGroup{
+				prefix:      parent.prefix,
+				middlewares: append(parent.middlewares[:len(parent.middlewares):len(parent.middlewares)], expr.Args...),
+			}
+		}
+	}
+	return routerGroup{}
+}
+
+// prefix returns the URL prefix for the router [expr]
+func (rg routerGroups) prefix(expr ast.Expr) string { return rg.group(expr).prefix }
+
+// register updates the groups with the definitions found in [n]
+func (rg routerGroups) register(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.AssignStmt:
+		if len(n.Lhs) != len(n.Rhs) {
+			return
+		}
+		for i, rh := range n.Rhs {
+			rg.registerVar(n.Lhs[i], rh)
+		}
+	case *ast.ValueSpec:
+		if len(n.Names) != len(n.Values) {
+			return
+		}
+		for i, value := range n.Values {
+			rg.registerVar(n.Names[i], value)
+		}
+	case *ast.CallExpr:
+		selector, ok := n.Fun.(*ast.SelectorExpr)
+		if !ok || len(n.Args) == 0 {
+			return
+		}
+		switch selector.Sel.Name {
+		case "Use": // add the middlewares to the router
+			if ident, ok := selector.X.(*ast.Ident); ok {
+				obj := resolveIdentifier(ident, rg.pkg.TypesInfo)
+				group := rg.groups[obj]
+				group.middlewares = append(group.middlewares[:len(group.middlewares):len(group.middlewares)], n.Args...)
+				rg.groups[obj] = group
+			}
+		case "Group", "Route": // closure with a router parameter
+			fn, ok := n.Args[len(n.Args)-1].(*ast.FuncLit)
+			if !ok || len(fn.Type.Params.List) != 1 || len(fn.Type.Params.List[0].Names) != 1 {
+				return
+			}
+			param := fn.Type.Params.List[0].Names[0]
+			rg.groups[resolveIdentifier(param, rg.pkg.TypesInfo)] = rg.group(n)
+		}
+	}
+}
+
+func (rg routerGroups) registerVar(lh ast.Expr, rh ast.Expr) {
+	ident, ok := lh.(*ast.Ident)
+	if !ok {
+		return
+	}
+	if group := rg.group(rh); !group.isZero() {
+		rg.groups[resolveIdentifier(ident, rg.pkg.TypesInfo)] = group
+	}
+}
+
+// inspect walks [fi], calling [visit] for each call expression, which must return true
+// if the call registers an endpoint (and should not be inspected further).
+// The errors raised by [visit] are reported, and the call is then skipped.
+//
+// The groups are tracked along the way, and the functions declared in [fi] and called
+// in [fi] (helpers) are inspected at their call sites, with the groups passed as arguments.
+func (rg routerGroups) inspect(fi *ast.File, visit func(call *ast.CallExpr) bool) {
+	// collect the helpers
+	decls := map[types.Object]*ast.FuncDecl{}
+	for _, decl := range fi.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+			decls[rg.pkg.TypesInfo.Defs[fn.Name]] = fn
+		}
+	}
+	helpers := map[*ast.FuncDecl]bool{}
+	ast.Inspect(fi, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if fn := calledFunc(call, rg.pkg.TypesInfo); fn != nil && decls[fn.Origin()] != nil {
+				helpers[decls[fn.Origin()]] = true
+			}
+		}
+		return true
+	})
+
+	inProgress := map[*ast.FuncDecl]bool{} // avoid infinite recursion
+	var walk func(node ast.Node)
+	walk = func(node ast.Node) {
+		ast.Inspect(node, func(n ast.Node) bool {
+			rg.register(n)
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			isRoute := true // skip the invalid routes
+			rg.diags.Try(call.Pos(), func() { isRoute = visit(call) })
+			if isRoute {
+				return false
+			}
+			fn := calledFunc(call, rg.pkg.TypesInfo)
+			if fn == nil {
+				return true
+			}
+			helper := decls[fn.Origin()]
+			if helper == nil || inProgress[helper] {
+				return true
+			}
+			// bind the groups passed as arguments
+			restore := rg.bindParams(helper, call.Args)
+			inProgress[helper] = true
+			walk(helper.Body)
+			inProgress[helper] = false
+			restore()
+			return true
+		})
+	}
+
+	for _, decl := range fi.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && helpers[fn] {
+			continue // inspected when called
+		}
+		walk(decl)
+	}
+}
+
+// bindParams associates the parameters of [fn] to the groups of [args],
+// returning a function restoring the previous state
+func (rg routerGroups) bindParams(fn *ast.FuncDecl, args []ast.Expr) (restore func()) {
+	var params []*ast.Ident
+	for _, field := range fn.Type.Params.List {
+		params = append(params, field.Names...)
+	}
+
+	type saved struct {
+		obj   types.Object
+		group routerGroup
+		has   bool
+	}
+	var previous []saved
+	for i, param := range params {
+		if i >= len(args) {
+			break
+		}
+		group := rg.group(args[i])
+		if group.isZero() {
+			continue
+		}
+		obj := resolveIdentifier(param, rg.pkg.TypesInfo)
+		old, has := rg.groups[obj]
+		previous = append(previous, saved{obj, old, has})
+		rg.groups[obj] = group
+	}
+	return func() {
+		for _, s := range previous {
+			if s.has {
+				rg.groups[s.obj] = s.group
+			} else {
+				delete(rg.groups, s.obj)
+			}
+		}
+	}
+}
@@ -6,19 +6,26 @@ import (
 	"go/constant"
 	"go/token"
 	"go/types"
+	"strings"
 
 	"github.com/benoitkugler/gomacro/analysis"
 	"golang.org/x/tools/go/packages"
 )
 
-// selectFileByPath returns the ast of `filePathAbs`
+// selectFileByPath returns the ast of `filePathAbs`, or nil
 func selectFileByPath(pkg *packages.Package, filePathAbs string) *ast.File {
 	for _, file := range pkg.Syntax {
 		if pkg.Fset.File(file.Package).Name() == filePathAbs {
 			return file
 		}
 	}
-	panic(fmt.Sprintf("file %s not found", filePathAbs))
+	return nil
+}
+
+// routeError returns the error raised when a route can't be scanned,
+// which is then reported with the position of the route
+func routeError(format string, args ...any) analysis.Diagnostic {
+	return analysis.NewError(token.Position{}, analysis.CodeUnsupportedRoute, format, args...)
 }
 
 // selectFileByPos returns the ast of the file containing `pos`
@@ -47,7 +54,7 @@ func selectFileByPos(rootPackage *packages.Package, pos token.Pos) *ast.File {
 
 	fi := aux(rootPackage)
 	if fi == nil {
-		panic(fmt.Sprintf("file %s not found", rootPackage.Fset.Position(pos).Filename))
+		panic(routeError("file %s not found", rootPackage.Fset.Position(pos).Filename))
 	}
 	return fi
 }
@@ -75,7 +82,7 @@ func selectPackage(rootPackage *packages.Package, target *types.Package) *packag
 
 	out := aux(rootPackage)
 	if out == nil {
-		panic(fmt.Sprintf("can't find package %s", target))
+		panic(routeError("can't find package %s", target))
 	}
 	return out
 }
@@ -107,7 +114,7 @@ func selectMethod(named *types.Named, methodName string) *types.Func {
 			return fn
 		}
 	}
-	panic(fmt.Sprintf("method %s not found on type %s", methodName, named))
+	panic(routeError("method %s not found on type %s", methodName, named))
 }
 
 func resolveFunc(rootPackage *packages.Package, fn *types.Func) (body *ast.BlockStmt, name string, sourcePkg *packages.Package) {
@@ -129,7 +136,7 @@ func resolveFunc(rootPackage *packages.Package, fn *types.Func) (body *ast.Block
 		return true
 	})
 	if body == nil {
-		panic(fmt.Sprintf("can't find body for %s", fn))
+		panic(routeError("can't find body for %s", fn))
 	}
 	return body, fn.Name(), selectPackage(rootPackage, fn.Pkg())
 }
@@ -152,7 +159,7 @@ func parseEndpointFunc(arg ast.Expr, pkg *packages.Package) (body *ast.BlockStmt
 				}
 				return resolveFunc(pkg, selectMethod(ty, method.Sel.Name))
 			default:
-				panic(fmt.Sprintf("unsupported identifier %s", xObj))
+				panic(routeError("unsupported identifier %s", xObj))
 			}
 		}
 	} else if ident, ok := arg.(*ast.Ident); ok {
@@ -160,18 +167,148 @@ func parseEndpointFunc(arg ast.Expr, pkg *packages.Package) (body *ast.BlockStmt
 		if fn, isFn := obj.(*types.Func); isFn {
 			return resolveFunc(pkg, fn)
 		} else {
-			panic(fmt.Sprintf("unsupported identifier %s for %s", obj, ident))
+			panic(routeError("unsupported identifier %s for %s", obj, ident))
 		}
 	} else if fnLitt, ok := arg.(*ast.FuncLit); ok {
-		return fnLitt.Body, fmt.Sprintf("Anonymous%d", arg.Pos()), pkg
+		// use the line number, which is stable (contrary to [token.Pos])
+		return fnLitt.Body, fmt.Sprintf("Anonymous%d", pkg.Fset.Position(arg.Pos()).Line), pkg
 	}
 
-	panic(fmt.Sprintf("unsupported handler function %s", arg))
+	panic(routeError("unsupported handler function %s", types.ExprString(arg)))
+}
+
+// lineComments returns the one line comments of [fi],
+// indexed by line
+func lineComments(pkg *packages.Package, fi *ast.File) map[int]string {
+	comments := map[int]string{} // line to comment
+	for _, cm := range fi.Comments {
+		if len(cm.List) != 1 {
+			continue
+		}
+		line := pkg.Fset.Position(cm.Pos()).Line
+		comments[line] = strings.TrimSpace(cm.List[0].Text[2:])
+	}
+	return comments
+}
+
+// typedPathParams returns the path parameters for [path], using
+// the types in [paramTypes], or string
+func typedPathParams(path string, paramTypes map[string]types.Type) []TypedParam {
+	var out []TypedParam
+	for _, param := range pathParamNames(path) {
+		ty := paramTypes[param]
+		if ty == nil {
+			ty = types.Typ[types.String]
+		}
+		out = append(out, TypedParam{Name: param, type_: ty})
+	}
+	return out
+}
+
+// calledFunc returns the function or method called by [call],
+// or nil
+func calledFunc(call *ast.CallExpr, pkg *types.Info) *types.Func {
+	var ident *ast.Ident
+	switch fn := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		ident = fn.Sel
+	case *ast.Ident:
+		ident = fn
+	default:
+		return nil
+	}
+	out, _ := pkg.Uses[ident].(*types.Func)
+	return out
+}
+
+// paramReader returns the name of the parameter read by [call],
+// or an empty string
+type paramReader func(call *ast.CallExpr) string
+
+// parseConvertedParams looks for the calls accepted by [read] in [body], and returns
+// the type of each parameter.
+// The type is string, unless the value is converted by one of the strconv functions,
+// either directly (strconv.Atoi(c.Param("id"))) or through a variable.
+func parseConvertedParams(body *ast.BlockStmt, pkg *packages.Package, read paramReader) map[string]types.Type {
+	out := map[string]types.Type{}
+	vars := map[types.Object]string{} // variables storing a param
+
+	// returns the param name for [expr], or ""
+	paramOf := func(expr ast.Expr) string {
+		switch expr := expr.(type) {
+		case *ast.CallExpr:
+			return read(expr)
+		case *ast.Ident:
+			return vars[resolveIdentifier(expr, pkg.TypesInfo)]
+		}
+		return ""
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				break
+			}
+			for i, rh := range n.Rhs {
+				call, isCall := rh.(*ast.CallExpr)
+				ident, isIdent := n.Lhs[i].(*ast.Ident)
+				if !isCall || !isIdent {
+					continue
+				}
+				if name := read(call); name != "" {
+					vars[resolveIdentifier(ident, pkg.TypesInfo)] = name
+				}
+			}
+		case *ast.CallExpr:
+			if name := read(n); name != "" {
+				if _, has := out[name]; !has {
+					out[name] = types.Typ[types.String]
+				}
+			} else if isStrconvParse(n, pkg.TypesInfo) && len(n.Args) != 0 {
+				if name := paramOf(n.Args[0]); name != "" {
+					out[name] = pkg.TypesInfo.TypeOf(n).(*types.Tuple).At(0).Type()
+				}
+			}
+		}
+		return true
+	})
+	return out
+}
+
+// statusCode returns the value of [expr],
+// if it is a constant integer
+func statusCode(expr ast.Expr, pkg *types.Info) (int, bool) {
+	value := pkg.Types[expr].Value
+	if value == nil || value.Kind() != constant.Int {
+		return 0, false
+	}
+	code, _ := constant.Int64Val(value)
+	return int(code), true
+}
+
+// isStrconvParse returns true for strconv.Atoi and strconv.ParseXXX calls
+func isStrconvParse(call *ast.CallExpr, pkg *types.Info) bool {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	fn, ok := pkg.Uses[selector.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "strconv" {
+		return false
+	}
+	switch fn.Name() {
+	case "Atoi", "ParseInt", "ParseUint", "ParseFloat", "ParseBool":
+		return true
+	default:
+		return false
+	}
 }
 
 // resolveTypes list the required types, perform their analysis,
-// and update `endpoints`
-func resolveTypes(rootPkg *packages.Package, endpoints []Endpoint) {
+// and update `endpoints`.
+// The endpoints using types which are not supported are reported and removed.
+func resolveTypes(rootPkg *packages.Package, endpoints []Endpoint, diags *analysis.Diagnostics) []Endpoint {
 	// collect the required types
 	var required []types.Type
 	for _, endpoint := range endpoints {
@@ -183,17 +320,28 @@ func resolveTypes(rootPkg *packages.Package, endpoints []Endpoint) {
 			required = append(required, ty)
 		}
 		for _, param := range endpoint.Contract.InputQueryParams {
-			if param.type_ == nil {
-				panic(fmt.Sprint(param))
+			if param.type_ != nil { // reported by checkTypes otherwise
+				required = append(required, param.type_)
 			}
+		}
+		for _, param := range endpoint.Contract.PathParams {
 			required = append(required, param.type_)
 		}
+		if ty := endpoint.Contract.InputForm.JSON.type_; ty != nil {
+			required = append(required, ty)
+		}
+		for _, ty := range endpoint.Contract.responsesT {
+			if ty != nil {
+				required = append(required, ty)
+			}
+		}
 	}
 
 	// performs the analysis
-	an := analysis.NewAnalysisFromTypes(rootPkg, required)
+	an := analysis.NewAnalysisFromTypes(rootPkg, required, diags)
 
 	// update back the endpoints
+	var out []Endpoint
 	for i := range endpoints {
 		ct := &endpoints[i].Contract
 		ct.InputBody = an.Types[ct.inputT]
@@ -201,27 +349,68 @@ func resolveTypes(rootPkg *packages.Package, endpoints []Endpoint) {
 		for j := range ct.InputQueryParams {
 			ct.InputQueryParams[j].resolveType(an)
 		}
+		for j := range ct.PathParams {
+			ct.PathParams[j].resolveType(an)
+		}
 		if ct.InputForm.JSON.Name != "" {
 			ct.InputForm.JSON.resolveType(an)
 		}
+		if ct.responsesT != nil {
+			ct.Responses = make(map[int]analysis.Type, len(ct.responsesT))
+			for code, ty := range ct.responsesT {
+				ct.Responses[code] = an.Types[ty]
+			}
+		}
+		if err := endpoints[i].checkTypes(); err != nil {
+			diags.Errorf(endpoints[i].pos, analysis.CodeUnsupportedType, "route %s %s ignored: %s", endpoints[i].Method, endpoints[i].Url, err)
+			continue
+		}
+		out = append(out, endpoints[i])
 	}
+	return out
 }
 
 // parse applies the given parser to extract the endpoints, and resolves the types found.
-func parse(pkg *packages.Package, absFilePath string, parser extractor) []Endpoint {
+// The routes which can't be scanned are reported to [diags] and ignored.
+func parse(pkg *packages.Package, absFilePath string, parser extractor, diags *analysis.Diagnostics) []Endpoint {
 	fi := selectFileByPath(pkg, absFilePath)
-	apis := parser.extract(pkg, fi)
-	resolveTypes(pkg, apis)
-	return apis
+	if fi == nil {
+		diags.Add(analysis.NewError(token.Position{Filename: absFilePath}, analysis.CodeUnsupportedRoute, "file not found in package %s", pkg.PkgPath))
+		return nil
+	}
+	apis := parser.extract(pkg, fi, diags)
+	return resolveTypes(pkg, apis, diags)
 }
 
 type extractor interface {
-	extract(pkg *packages.Package, fi *ast.File) []Endpoint
+	extract(pkg *packages.Package, fi *ast.File, diags *analysis.Diagnostics) []Endpoint
 }
 
 type echoExtractor struct{}
 
 // ParseEcho scans a file using the Echo framework.
-func ParseEcho(pkg *packages.Package, absFilePath string) []Endpoint {
-	return parse(pkg, absFilePath, echoExtractor{})
+func ParseEcho(pkg *packages.Package, absFilePath string, diags *analysis.Diagnostics) []Endpoint {
+	return parse(pkg, absFilePath, echoExtractor{}, diags)
+}
+
+type chiExtractor struct{}
+
+// ParseChi scans a file using the chi framework.
+func ParseChi(pkg *packages.Package, absFilePath string, diags *analysis.Diagnostics) []Endpoint {
+	return parse(pkg, absFilePath, chiExtractor{}, diags)
+}
+
+type ginExtractor struct{}
+
+// ParseGin scans a file using the gin framework.
+func ParseGin(pkg *packages.Package, absFilePath string, diags *analysis.Diagnostics) []Endpoint {
+	return parse(pkg, absFilePath, ginExtractor{}, diags)
+}
+
+type netHTTPExtractor struct{}
+
+// ParseNetHTTP scans a file using the standard library [net/http.ServeMux],
+// with the method and path patterns introduced in Go 1.22.
+func ParseNetHTTP(pkg *packages.Package, absFilePath string, diags *analysis.Diagnostics) []Endpoint {
+	return parse(pkg, absFilePath, netHTTPExtractor{}, diags)
 }
@@ -0,0 +1,97 @@
+package httpapi
+
+import (
+	"go/ast"
+	"log"
+	"strings"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// implements a parser for the chi framework, whose
+// handlers are standard net/http handlers
+
+// chiMethods maps the chi.Router methods to HTTP methods
+var chiMethods = map[string]string{
+	"Get":     "GET",
+	"Post":    "POST",
+	"Put":     "PUT",
+	"Delete":  "DELETE",
+	"Patch":   "PATCH",
+	"Head":    "HEAD",
+	"Options": "OPTIONS",
+}
+
+// chiExtractor scans a file using the chi framework, looking for method calls .Get .Post .Put .Delete .Patch .Head .Options,
+// and resolving the prefixes defined by .Route and .Group.
+func (chiExtractor) extract(pkg *packages.Package, fi *ast.File, diags *analysis.Diagnostics) []Endpoint {
+	comments := lineComments(pkg, fi)
+	groups := newRouterGroups(pkg, diags)
+
+	var out []Endpoint
+	groups.inspect(fi, func(callExpr *ast.CallExpr) bool {
+		fn := calledFunc(callExpr, pkg.TypesInfo)
+		if fn == nil || fn.Pkg() == nil || fn.Pkg().Name() != "chi" || len(callExpr.Args) != 2 {
+			return false
+		}
+		method, isMethod := chiMethods[fn.Name()]
+		if !isMethod {
+			return false
+		}
+
+		line := pkg.Fset.Position(callExpr.Pos()).Line
+		comment := newSpecialComment(comments[line])
+		if comment == ignore {
+			log.Println("ignoring route at line", line)
+			return false
+		}
+
+		path, err := resolveConstString(callExpr.Args[0], pkg)
+		if err != nil {
+			panic(routeError("invalid endpoint URL: %s", err))
+		}
+		if prefix := groups.prefix(callExpr.Fun.(*ast.SelectorExpr).X); prefix != "" && path == "/" {
+			path = prefix // sub routers are mounted : "/" matches the prefix itself
+		} else {
+			path = joinPath(prefix, path)
+		}
+
+		body, name, sourcePkg := resolveHandler(callExpr.Args[1], pkg)
+		contract := newContractFromNetHTTPBody(sourcePkg, body, name)
+
+		// type the path parameters using the chi.URLParam calls
+		paramTypes := parseConvertedParams(body, sourcePkg, func(call *ast.CallExpr) string {
+			return parseChiURLParam(call, sourcePkg)
+		})
+		contract.PathParams = typedPathParams(path, paramTypes)
+
+		out = append(out, Endpoint{pos: callExpr.Pos(), Url: path, Method: method, IsUrlOnly: comment == urlOnly, Contract: contract, Invalidates: parseInvalidates(comments[line])})
+
+		return true
+	})
+
+	return out
+}
+
+// joinPath concatenates the group [prefix] and [path],
+// avoiding duplicated slashes
+func joinPath(prefix, path string) string {
+	if strings.HasSuffix(prefix, "/") && strings.HasPrefix(path, "/") {
+		return prefix + path[1:]
+	}
+	return prefix + path
+}
+
+// parseChiURLParam returns the key of a chi.URLParam(r, <key>) call
+func parseChiURLParam(call *ast.CallExpr, pkg *packages.Package) string {
+	fn := calledFunc(call, pkg.TypesInfo)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Name() != "chi" || fn.Name() != "URLParam" || len(call.Args) != 2 {
+		return ""
+	}
+	arg, err := resolveConstString(call.Args[1], pkg)
+	if err != nil {
+		panic(routeError("invalid URLParam argument: %s", err))
+	}
+	return arg
+}
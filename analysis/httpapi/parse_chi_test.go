@@ -0,0 +1,68 @@
+package httpapi
+
+import (
+	"go/types"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	tu "github.com/benoitkugler/gomacro/testutils"
+)
+
+func TestPathParamNames(t *testing.T) {
+	for path, names := range map[string][]string{
+		"/items/:id/files/*":           {"id", "*"},
+		"/items/:id/files/*path":       {"id", "path"},
+		"/items/{id}/files/{path...}":  {"id", "path"},
+		"/items/{id:[0-9]+}/files/{p}": {"id", "p"},
+		"/items":                       nil,
+	} {
+		got := pathParamNames(path)
+		tu.Assert(t, len(got) == len(names))
+		for i := range got {
+			tu.Assert(t, got[i] == names[i])
+		}
+	}
+}
+
+func TestParseChi(t *testing.T) {
+	fn := "test/chiroutes/routes.go"
+	pack, err := analysis.LoadSource(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apis := ParseChi(pack, abs, nil)
+	tu.Assert(t, len(apis) == 6)
+
+	var urls []string
+	for _, api := range apis {
+		urls = append(urls, api.Method+" "+api.Url)
+	}
+	expected := []string{
+		"GET /health",
+		"GET /api/items",
+		"GET /api/items/{id:[0-9]+}",
+		"DELETE /api/items/{id:[0-9]+}",
+		"POST /api/items",
+		"PUT /admin/items/{id}",
+	}
+	for i := range expected {
+		tu.Assert(t, urls[i] == expected[i])
+	}
+
+	tu.Assert(t, len(apis[1].Contract.InputQueryParams) == 1)
+	tu.Assert(t, apis[1].Contract.Return != nil)
+
+	get := apis[2].Contract
+	tu.Assert(t, len(get.PathParams) == 1 && get.PathParams[0].Name == "id")
+	tu.Assert(t, get.PathParams[0].Type.Type() == types.Typ[types.Int64])
+
+	tu.Assert(t, apis[4].Method == http.MethodPost && apis[4].Contract.InputBody != nil)
+	tu.Assert(t, apis[5].Contract.PathParams[0].Type.Type() == types.Typ[types.String])
+}
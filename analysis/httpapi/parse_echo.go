@@ -1,13 +1,13 @@
 package httpapi
 
 import (
-	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
 	"log"
 	"strings"
 
+	"github.com/benoitkugler/gomacro/analysis"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -15,16 +15,68 @@ import (
 
 func isHttpMethod(name string) bool {
 	switch name {
-	case "GET", "PUT", "POST", "DELETE":
+	case "GET", "PUT", "POST", "DELETE", "PATCH", "HEAD", "OPTIONS":
 		return true
 	default:
 		return false
 	}
 }
 
+// anyMethods are the methods registered by .Any, restricted
+// to the ones supported by the generators
+var anyMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// parseEchoMethods returns the methods registered by the route [call], and the
+// remaining arguments (url, handler, middlewares...), or nil if [call] is not a route.
+// Supported calls are
+//   - .<METHOD>(url, handler, middlewares...)
+//   - .Any(url, handler, middlewares...)
+//   - .Match([]string{methods...}, url, handler, middlewares...)
+func parseEchoMethods(call *ast.CallExpr, pkg *packages.Package) (methods []string, args []ast.Expr) {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, nil
+	}
+	switch name := selector.Sel.Name; {
+	case isHttpMethod(name):
+		methods, args = []string{name}, call.Args
+	case name == "Any":
+		methods, args = anyMethods, call.Args
+	case name == "Match" && len(call.Args) != 0:
+		list, ok := call.Args[0].(*ast.CompositeLit)
+		if !ok {
+			panic(routeError("unsupported Match() methods: expected a []string litteral"))
+		}
+		for _, elt := range list.Elts {
+			method, err := resolveConstString(elt, pkg)
+			if err != nil {
+				panic(routeError("invalid Match() method: %s", err))
+			}
+			if !isHttpMethod(method) {
+				panic(routeError("unsupported HTTP method %s", method))
+			}
+			methods = append(methods, method)
+		}
+		args = call.Args[1:]
+	}
+	if len(args) < 2 { // we are looking for (url, handler)
+		return nil, nil
+	}
+	return methods, args
+}
+
+// methodContractName disambiguates the handler [name]
+// when it is registered for several methods
+func methodContractName(name, method string, methods []string) string {
+	if len(methods) == 1 {
+		return name
+	}
+	return name + method[:1] + strings.ToLower(method[1:])
+}
+
 // look for a JWTMiddlewareForQuery middleware
-func hasJWTMiddleware(callExpr *ast.CallExpr) bool {
-	for _, node := range callExpr.Args[2:] {
+func hasJWTMiddleware(middlewares []ast.Expr) bool {
+	for _, node := range middlewares {
 		callExpr, ok := node.(*ast.CallExpr)
 		if !ok {
 			continue
@@ -42,73 +94,70 @@ func hasJWTMiddleware(callExpr *ast.CallExpr) bool {
 }
 
 // echoExtractor scans a file using the Echo framework, looking for method calls .GET .POST .PUT .DELETE
-// inside all top level functions in `f`, and parameters bindings.
-func (ex echoExtractor) extract(pkg *packages.Package, fi *ast.File) []Endpoint {
-	comments := map[int]string{} // line to comment
-	for _, cm := range fi.Comments {
-		if len(cm.List) != 1 {
-			continue
-		}
-		line := pkg.Fset.Position(cm.Pos()).Line
-		comments[line] = strings.TrimSpace(cm.List[0].Text[2:])
-	}
+// .PATCH .HEAD .OPTIONS (and .Any, .Match) inside all top level functions in `f`, and parameters bindings.
+// The prefixes and middlewares of the groups created with .Group are resolved.
+func (ex echoExtractor) extract(pkg *packages.Package, fi *ast.File, diags *analysis.Diagnostics) []Endpoint {
+	comments := lineComments(pkg, fi)
+	groups := newRouterGroups(pkg, diags)
 
 	var out []Endpoint
 
-	ast.Inspect(fi, func(n ast.Node) bool {
-		if n == nil {
-			return false
-		}
-		callExpr, ok := n.(*ast.CallExpr)
-		if !ok {
-			return true
-		}
+	groups.inspect(fi, func(callExpr *ast.CallExpr) bool {
 		line := pkg.Fset.Position(callExpr.Pos()).Line
 		comment := newSpecialComment(comments[line])
 
 		if comment == ignore {
 			log.Println("ignoring route at line", line)
-			return true
+			return false
 		}
 
 		// restrict to <echo>.{GET} methods
-		selector, ok := callExpr.Fun.(*ast.SelectorExpr)
-		if !ok {
-			return true
-		}
-		methodName := selector.Sel.Name
-		if !isHttpMethod(methodName) || len(callExpr.Args) < 2 {
-			// we are looking for .<METHOD>(url, handler)
-			return true
+		methods, args := parseEchoMethods(callExpr, pkg)
+		if len(methods) == 0 {
+			return false
 		}
 		isUrlOnly := comment == urlOnly
 
-		urlNode, handlerNode := callExpr.Args[0], callExpr.Args[1]
+		urlNode, handlerNode := args[0], args[1]
 		path, err := resolveConstString(urlNode, pkg)
 		if err != nil {
-			panic("invalid endpoint URL :" + err.Error())
+			panic(routeError("invalid endpoint URL: %s", err))
 		}
+		group := groups.group(callExpr.Fun.(*ast.SelectorExpr).X)
+		path = joinPath(group.prefix, path)
+		hasJWT := hasJWTMiddleware(args[2:]) || hasJWTMiddleware(group.middlewares)
 
 		body, name, sourcePkg := parseEndpointFunc(handlerNode, pkg)
-		contract := newContractFromEchoBody(sourcePkg, body, name)
-
-		if hasJWTMiddleware(callExpr) {
-			// add a token=<string> query parameters
-			contract.InputQueryParams = append(contract.InputQueryParams, TypedParam{
-				type_: types.Typ[types.String],
-				Name:  "token",
+		// one endpoint for each method
+		for _, method := range methods {
+			contract := newContractFromEchoBody(sourcePkg, body, methodContractName(name, method, methods))
+
+			// type the path parameters using the c.Param calls
+			paramTypes := parseConvertedParams(body, sourcePkg, func(call *ast.CallExpr) string {
+				param, _ := parseCallWithString(call, "Param", sourcePkg)
+				return param
 			})
-		}
+			contract.PathParams = typedPathParams(path, paramTypes)
+
+			if hasJWT {
+				// add a token=<string> query parameters
+				contract.InputQueryParams = append(contract.InputQueryParams, TypedParam{
+					type_: types.Typ[types.String],
+					Name:  "token",
+				})
+			}
 
-		out = append(out, Endpoint{Url: path, Method: methodName, IsUrlOnly: isUrlOnly, Contract: contract})
+			out = append(out, Endpoint{pos: callExpr.Pos(), Url: path, Method: method, IsUrlOnly: isUrlOnly, Contract: contract, Invalidates: parseInvalidates(comments[line])})
+		}
 
-		return false
+		return true
 	})
 
 	return out
 }
 
-// Look for Bind(), QueryParam(), FormValue(), FromFile() and JSON() method calls
+// Look for Bind(), QueryParam(), FormValue(), FromFile() and JSON() method calls.
+// The status codes used with JSON(), NoContent() and echo.NewHTTPError() are collected into [Contract.Responses].
 // Some custom parsing method are also supported :
 //   - .QueryParamBool(c, ...) -> convert string to boolean
 //   - .QueryParamInt64(, ...) -> convert string to int64
@@ -140,21 +189,36 @@ func newContractFromEchoBody(pkg *packages.Package, body *ast.BlockStmt, contrac
 
 func parseReturnStmt(stmt *ast.ReturnStmt, pkg *types.Info, out *Contract) {
 	if len(stmt.Results) != 1 { // should not happend : the method return error
-		panic("expected at least one result in return statement")
+		panic(routeError("expected one result in return statement"))
 	}
 	if call, ok := stmt.Results[0].(*ast.CallExpr); ok {
 		if method, ok := call.Fun.(*ast.SelectorExpr); ok {
 			if method.Sel.Name == "JSON" || method.Sel.Name == "JSONPretty" {
 				if len(call.Args) >= 2 { // c.JSON(200, output)
-					output := call.Args[1]
-					switch output := output.(type) {
+					var ty types.Type
+					switch output := call.Args[1].(type) {
 					case *ast.Ident:
-						out.returnT = resolveVarType(output, pkg)
+						ty = resolveVarType(output, pkg)
 					case *ast.CompositeLit:
-						out.returnT = parseCompositeLit(output, pkg)
+						ty = parseCompositeLit(output, pkg)
 					default:
-						panic("unsupported return value")
+						panic(routeError("unsupported return value %s", types.ExprString(output)))
 					}
+					code, isConst := statusCode(call.Args[0], pkg)
+					if isConst {
+						out.addResponse(code, ty)
+					}
+					if !isConst || 200 <= code && code < 300 { // error payloads are only in Responses
+						out.returnT = ty
+					}
+				}
+			} else if method.Sel.Name == "NoContent" && len(call.Args) == 1 { // c.NoContent(200)
+				if code, ok := statusCode(call.Args[0], pkg); ok {
+					out.addResponse(code, nil)
+				}
+			} else if method.Sel.Name == "NewHTTPError" && len(call.Args) >= 1 { // echo.NewHTTPError(409, payload)
+				if code, ok := statusCode(call.Args[0], pkg); ok {
+					out.addResponse(code, httpErrorPayload(call.Args[1:], pkg))
 				}
 			} else if method.Sel.Name == "Blob" {
 				if len(call.Args) >= 3 { // c.Blob(200, name, bytes)
@@ -171,15 +235,28 @@ func parseReturnStmt(stmt *ast.ReturnStmt, pkg *types.Info, out *Contract) {
 	}
 }
 
+// httpErrorPayload returns the type of the message given to echo.NewHTTPError,
+// or nil for string (or missing) messages, which are not typed
+func httpErrorPayload(args []ast.Expr, pkg *types.Info) types.Type {
+	if len(args) == 0 {
+		return nil
+	}
+	ty := pkg.TypeOf(args[0])
+	if basic, ok := ty.Underlying().(*types.Basic); ok && basic.Info()&types.IsString != 0 {
+		return nil
+	}
+	return ty
+}
+
 // expect iter.Seq2[T, _] and return T
 func extractIter2Value(ty types.Type) types.Type {
 	fnType := ty.Underlying().(*types.Signature)
 	if fnType.Params().Len() != 1 {
-		panic("expected iter.Seq2")
+		panic(routeError("expected iter.Seq2, got %s", ty))
 	}
 	tParams := ty.(*types.Named).TypeArgs()
 	if tParams.Len() != 2 {
-		panic("expected iter.Seq2")
+		panic(routeError("expected iter.Seq2, got %s", ty))
 	}
 	return tParams.At(0)
 }
@@ -230,7 +307,7 @@ func resolveBindTarget(arg ast.Expr, pkg *types.Info) types.Type {
 			return resolveVarType(ident, pkg)
 		}
 	}
-	panic("unsupported Bind() expression")
+	panic(routeError("unsupported Bind() expression %s", types.ExprString(arg)))
 }
 
 // parse a c.Bind(&params) call
@@ -266,20 +343,20 @@ func parseFormValueJSON(expr ast.Expr, pkg *packages.Package) (string, types.Typ
 
 		// "c.<methodName>(<string>)"
 		if len(call.Args) != 3 {
-			panic("invalid argument length for FormValueJSON")
+			panic(routeError("invalid argument length for FormValueJSON"))
 		}
 		arg := call.Args[1]
 		dst := call.Args[2]
 
 		argS, err := resolveConstString(arg, pkg)
 		if err != nil {
-			panic(fmt.Sprintf("invalid first argument for FormValueJSON: %s", err))
+			panic(routeError("invalid first argument for FormValueJSON: %s", err))
 		}
 
 		outType := pkg.TypesInfo.TypeOf(dst)
 		ptr, ok := outType.(*types.Pointer)
 		if !ok {
-			panic("expected pointer in FormValueJSON")
+			panic(routeError("expected pointer in FormValueJSON"))
 		}
 		return argS, ptr.Elem()
 	}
@@ -317,7 +394,7 @@ func parseCallWithString(expr ast.Expr, methodName string, pkg *packages.Package
 
 		argS, err := resolveConstString(arg, pkg)
 		if err != nil {
-			panic(fmt.Sprintf("invalid %s argument: %s", methodName, err))
+			panic(routeError("invalid %s argument: %s", methodName, err))
 		}
 
 		outType := pkg.TypesInfo.TypeOf(expr)
@@ -2,6 +2,8 @@ package httpapi
 
 import (
 	"fmt"
+	"go/types"
+	"net/http"
 	"path/filepath"
 	"testing"
 	"time"
@@ -21,7 +23,7 @@ func TestParse(t *testing.T) {
 	}
 
 	ti := time.Now()
-	apis := ParseEcho(pack, abs)
+	apis := ParseEcho(pack, abs, nil)
 	fmt.Println("Resolved in ", time.Since(ti))
 	if len(apis) != 18 {
 		t.Fatal()
@@ -41,4 +43,191 @@ func TestParse(t *testing.T) {
 	if !apis[17].IsUrlOnly {
 		t.Fatal()
 	}
+
+	if params := apis[8].Contract.PathParams; len(params) != 1 || params[0].Type.Type() != types.Typ[types.Int64] {
+		t.Fatal("path param not typed")
+	}
+	if params := apis[9].Contract.PathParams; len(params) != 1 || params[0].Type.Type() != types.Typ[types.String] {
+		t.Fatal()
+	}
+}
+
+func TestPathChunks(t *testing.T) {
+	ep := Endpoint{Url: "/items/:id/files/*", Contract: Contract{PathParams: []TypedParam{
+		{Name: "id", Type: analysis.Int},
+		{Name: "*", Type: analysis.String},
+	}}}
+	chunks := ep.PathChunks()
+	if len(chunks) != 4 {
+		t.Fatal(chunks)
+	}
+	if chunks[0].Static != "/items/" || chunks[1].Param.Name != "id" || chunks[2].Static != "/files/" {
+		t.Fatal(chunks)
+	}
+	if !chunks[3].IsWildcard || chunks[3].Param.Name != "*" {
+		t.Fatal(chunks)
+	}
+
+	// unknown params are kept as is
+	ep = Endpoint{Url: "/items/:id"}
+	if chunks = ep.PathChunks(); len(chunks) != 1 || chunks[0].Static != "/items/:id" {
+		t.Fatal(chunks)
+	}
+}
+
+func TestParseEchoGroups(t *testing.T) {
+	fn := "test/echogroups/routes.go"
+	pack, err := analysis.LoadSource(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apis := ParseEcho(pack, abs, nil)
+	var urls []string
+	for _, api := range apis {
+		urls = append(urls, api.Method+" "+api.Url)
+	}
+	expected := []string{
+		"GET /health",
+		"GET /api/items",
+		"GET /api/items/:id",
+		"POST /api/v2/items",
+		"GET /api/admin/items",
+		"GET /api/admin/items/:id",
+		"GET /files/download",
+	}
+	if fmt.Sprint(urls[:len(expected)]) != fmt.Sprint(expected) {
+		t.Fatal(urls)
+	}
+
+	// group middlewares
+	for i, api := range apis[:len(expected)] {
+		hasToken := i >= 4
+		if got := len(api.Contract.InputQueryParams) == 1; got != hasToken {
+			t.Fatal(api.Url, api.Contract.InputQueryParams)
+		}
+	}
+
+	if params := apis[5].Contract.PathParams; len(params) != 1 || params[0].Type.Type() != types.Typ[types.Int64] {
+		t.Fatal("path param not typed")
+	}
+
+	if inv := apis[3].Invalidates; fmt.Sprint(inv) != "[/api/items /api/admin/items]" {
+		t.Fatal(inv)
+	}
+}
+
+func TestParseEchoResponses(t *testing.T) {
+	fn := "test/echogroups/routes.go"
+	pack, err := analysis.LoadSource(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apis := ParseEcho(pack, abs, nil)
+
+	getItem := apis[2].Contract
+	if fmt.Sprint(getItem.ErrorResponses()) != "[400 404]" || len(getItem.Responses) != 3 {
+		t.Fatal(getItem.Responses)
+	}
+	if getItem.Responses[400] != nil || getItem.Responses[404] == nil {
+		t.Fatal(getItem.Responses)
+	}
+	if getItem.Return != getItem.Responses[200] {
+		t.Fatal("unexpected return type")
+	}
+
+	createItem := apis[3].Contract
+	if named, ok := createItem.Responses[422].(*analysis.Named); !ok || named.Type().(*types.Named).Obj().Name() != "ValidationErrors" {
+		t.Fatal(createItem.Responses)
+	}
+	if named, ok := createItem.Return.(*analysis.Named); ok && named.Type().(*types.Named).Obj().Name() == "ValidationErrors" {
+		t.Fatal("error payload used as return type")
+	}
+
+	updateItem := apis[7].Contract
+	if _, has := updateItem.Responses[200]; !has || updateItem.Return != nil {
+		t.Fatal(updateItem.Responses)
+	}
+}
+
+func TestParseEchoMethods(t *testing.T) {
+	fn := "test/echogroups/routes.go"
+	pack, err := analysis.LoadSource(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apis := ParseEcho(pack, abs, nil)[7:] // skip the group routes
+	var urls []string
+	for _, api := range apis {
+		urls = append(urls, api.Method+" "+api.Url+" "+api.Contract.Name)
+	}
+	expected := []string{
+		"PATCH /api/items/:id updateItem",
+		"PUT /api/items updateItemPut",
+		"PATCH /api/items updateItemPatch",
+		"GET /ping pingGet",
+		"HEAD /ping pingHead",
+		"POST /ping pingPost",
+		"PUT /ping pingPut",
+		"PATCH /ping pingPatch",
+		"DELETE /ping pingDelete",
+		"OPTIONS /ping pingOptions",
+	}
+	if fmt.Sprint(urls) != fmt.Sprint(expected) {
+		t.Fatal(urls)
+	}
+	if apis[0].Contract.InputBody == nil || apis[2].Contract.InputBody == nil {
+		t.Fatal("missing body")
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	pack, err := analysis.LoadSource("test/routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags := analysis.NewDiagnostics(pack.Fset)
+	if apis := ParseEcho(pack, "/not/in/package.go", diags); len(apis) != 0 {
+		t.Fatal()
+	}
+	list := diags.List()
+	if len(list) != 1 || list[0].Code != analysis.CodeUnsupportedRoute || list[0].Pos.Filename != "/not/in/package.go" {
+		t.Fatal(list)
+	}
+}
+
+func TestResolveUnsupportedParams(t *testing.T) {
+	pack, err := analysis.LoadSource("test/routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags := analysis.NewDiagnostics(pack.Fset)
+	endpoints := resolveTypes(pack, []Endpoint{
+		{Url: "/unresolved", Method: http.MethodGet, Contract: Contract{Name: "unresolved", InputQueryParams: []TypedParam{{Name: "q"}}}},
+		{Url: "/complex", Method: http.MethodGet, Contract: Contract{Name: "complex", InputQueryParams: []TypedParam{{Name: "c", type_: types.Typ[types.Complex64]}}}},
+		{Url: "/valid", Method: http.MethodGet, Contract: Contract{Name: "valid", InputQueryParams: []TypedParam{{Name: "v", type_: types.Typ[types.Int]}}}},
+	}, diags)
+	// the invalid endpoints are reported and removed
+	if len(endpoints) != 1 || endpoints[0].Url != "/valid" {
+		t.Fatal(endpoints)
+	}
+	if diags.ErrorCount() != 3 { // complex64 is reported by the analysis and the route check
+		t.Fatal(diags.List())
+	}
 }
@@ -0,0 +1,134 @@
+package httpapi
+
+import (
+	"go/ast"
+	"go/types"
+	"log"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// implements a parser for the gin framework
+
+// isGinMethod returns true if [fn] is the method [name]
+// defined in the gin package
+func isGinMethod(fn *types.Func, names ...string) bool {
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Name() != "gin" {
+		return false
+	}
+	for _, name := range names {
+		if fn.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ginExtractor scans a file using the gin framework, looking for method calls .GET .POST .PUT .DELETE .PATCH .HEAD .OPTIONS
+// and resolving the prefixes defined by .Group.
+func (ginExtractor) extract(pkg *packages.Package, fi *ast.File, diags *analysis.Diagnostics) []Endpoint {
+	comments := lineComments(pkg, fi)
+	groups := newRouterGroups(pkg, diags)
+
+	var out []Endpoint
+	groups.inspect(fi, func(callExpr *ast.CallExpr) bool {
+		fn := calledFunc(callExpr, pkg.TypesInfo)
+		if !isGinMethod(fn, "GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS") || len(callExpr.Args) < 2 {
+			return false
+		}
+
+		line := pkg.Fset.Position(callExpr.Pos()).Line
+		comment := newSpecialComment(comments[line])
+		if comment == ignore {
+			log.Println("ignoring route at line", line)
+			return false
+		}
+
+		path, err := resolveConstString(callExpr.Args[0], pkg)
+		if err != nil {
+			panic(routeError("invalid endpoint URL: %s", err))
+		}
+		path = joinPath(groups.prefix(callExpr.Fun.(*ast.SelectorExpr).X), path)
+
+		// the middlewares come first
+		handlerNode := callExpr.Args[len(callExpr.Args)-1]
+		body, name, sourcePkg := parseEndpointFunc(handlerNode, pkg)
+		contract := newContractFromGinBody(sourcePkg, body, name)
+		paramTypes := parseConvertedParams(body, sourcePkg, func(call *ast.CallExpr) string {
+			return parseGinCall(call, sourcePkg, "Param")
+		})
+		contract.PathParams = typedPathParams(path, paramTypes)
+
+		out = append(out, Endpoint{pos: callExpr.Pos(), Url: path, Method: fn.Name(), IsUrlOnly: comment == urlOnly, Contract: contract, Invalidates: parseInvalidates(comments[line])})
+
+		return true
+	})
+
+	return out
+}
+
+// parseGinCall returns the first (string) argument of a call to
+// the [methodName] method of gin, or an empty string.
+func parseGinCall(call *ast.CallExpr, pkg *packages.Package, methodName string) string {
+	if !isGinMethod(calledFunc(call, pkg.TypesInfo), methodName) || len(call.Args) == 0 {
+		return ""
+	}
+	arg, err := resolveConstString(call.Args[0], pkg)
+	if err != nil {
+		panic(routeError("invalid %s argument: %s", methodName, err))
+	}
+	return arg
+}
+
+// isSuccessCode returns true if [expr] is a constant 2XX status code
+func isSuccessCode(expr ast.Expr, pkg *types.Info) bool {
+	code, ok := statusCode(expr, pkg)
+	return ok && 200 <= code && code < 300
+}
+
+// Look for ShouldBindJSON(), BindJSON(), Query(), DefaultQuery(), PostForm(), FormFile(),
+// and JSON() and Data() method calls.
+// Only the responses with a 2XX status code are used to define the return type,
+// but all the status codes are collected into [Contract.Responses].
+//
+// pkg is the package where the function is defined
+func newContractFromGinBody(pkg *packages.Package, body *ast.BlockStmt, contractName string) Contract {
+	out := Contract{Name: contractName}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		fn := calledFunc(call, pkg.TypesInfo)
+		switch {
+		case isGinMethod(fn, "ShouldBindJSON", "BindJSON") && len(call.Args) == 1:
+			out.inputT = resolveBindTarget(call.Args[0], pkg.TypesInfo)
+		case isGinMethod(fn, "Query", "DefaultQuery"):
+			out.InputQueryParams = append(out.InputQueryParams, TypedParam{Name: parseGinCall(call, pkg, fn.Name()), type_: types.Typ[types.String]})
+		case isGinMethod(fn, "PostForm"):
+			out.InputForm.ValueNames = append(out.InputForm.ValueNames, parseGinCall(call, pkg, "PostForm"))
+		case isGinMethod(fn, "FormFile"):
+			out.InputForm.File = parseGinCall(call, pkg, "FormFile")
+		case isGinMethod(fn, "JSON", "AbortWithStatusJSON") && len(call.Args) == 2:
+			ty := pkg.TypesInfo.TypeOf(call.Args[1])
+			if code, ok := statusCode(call.Args[0], pkg.TypesInfo); ok {
+				out.addResponse(code, ty)
+			}
+			if isSuccessCode(call.Args[0], pkg.TypesInfo) {
+				out.returnT = ty
+			}
+		case isGinMethod(fn, "Status", "AbortWithStatus") && len(call.Args) == 1:
+			if code, ok := statusCode(call.Args[0], pkg.TypesInfo); ok {
+				out.addResponse(code, nil)
+			}
+		case isGinMethod(fn, "Data") && len(call.Args) == 3 && isSuccessCode(call.Args[0], pkg.TypesInfo):
+			out.returnT = types.NewSlice(types.Typ[types.Byte])
+			out.IsReturnBlob = true
+		}
+		return true
+	})
+
+	return out
+}
@@ -0,0 +1,60 @@
+package httpapi
+
+import (
+	"go/types"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	tu "github.com/benoitkugler/gomacro/testutils"
+)
+
+func TestParseGin(t *testing.T) {
+	fn := "test/ginroutes/routes.go"
+	pack, err := analysis.LoadSource(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apis := ParseGin(pack, abs, nil)
+	tu.Assert(t, len(apis) == 5)
+
+	var urls []string
+	for _, api := range apis {
+		urls = append(urls, api.Method+" "+api.Url)
+	}
+	expected := []string{
+		"GET /health",
+		"GET /api/v1/items",
+		"GET /api/v1/items/:id",
+		"POST /api/v1/admin/items",
+		"PUT /api/v1/admin/files/*path",
+	}
+	for i := range expected {
+		tu.Assert(t, urls[i] == expected[i])
+	}
+
+	list := apis[1].Contract
+	tu.Assert(t, len(list.InputQueryParams) == 2 && list.InputQueryParams[1].Name == "sort")
+	tu.Assert(t, list.Return != nil)
+
+	// the error response is not used as return type
+	get := apis[2].Contract
+	tu.Assert(t, get.Return.Type().String() == "github.com/benoitkugler/gomacro/analysis/httpapi/test/ginroutes.Item")
+	tu.Assert(t, len(get.Responses) == 2 && get.Responses[http.StatusBadRequest] == nil) // gin.H is not typed
+	tu.Assert(t, len(get.PathParams) == 1 && get.PathParams[0].Type.Type() == types.Typ[types.Int])
+
+	create := apis[3].Contract
+	tu.Assert(t, create.Name == "createItem" && create.InputBody != nil)
+	tu.Assert(t, len(create.ErrorResponses()) == 1 && create.ErrorResponses()[0] == http.StatusBadRequest)
+
+	upload := apis[4].Contract
+	tu.Assert(t, upload.IsReturnBlob)
+	tu.Assert(t, upload.InputForm.File == "file" && len(upload.InputForm.ValueNames) == 1)
+	tu.Assert(t, len(upload.PathParams) == 1 && upload.PathParams[0].Name == "path")
+}
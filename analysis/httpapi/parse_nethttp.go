@@ -0,0 +1,200 @@
+package httpapi
+
+import (
+	"go/ast"
+	"go/types"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// implements a parser for the standard library net/http package
+
+// isNetHTTPFunc returns true if [selector] refers to the
+// function or method [name] defined in the net/http package
+func isNetHTTPFunc(selector *ast.SelectorExpr, pkg *types.Info, names ...string) bool {
+	fn, ok := pkg.Uses[selector.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "net/http" {
+		return false
+	}
+	for _, name := range names {
+		if fn.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// splitPattern splits a ServeMux pattern [METHOD ][HOST]/[PATH]
+// into method and path.
+// If no method is specified, GET is returned, with [hasMethod] set to false.
+// The special {$} wildcard is removed.
+func splitPattern(pattern string) (method, path string, hasMethod bool) {
+	method, path, hasMethod = strings.Cut(strings.TrimSpace(pattern), " ")
+	if !hasMethod {
+		method, path = http.MethodGet, method
+	}
+	path = strings.TrimSpace(path)
+	// remove the host, if any
+	if index := strings.IndexByte(path, '/'); index > 0 {
+		path = path[index:]
+	}
+	path = strings.ReplaceAll(path, "{$}", "")
+	return method, path, hasMethod
+}
+
+// resolveHandler supports function values, http.HandlerFunc conversions
+// and types implementing http.Handler
+func resolveHandler(arg ast.Expr, pkg *packages.Package) (body *ast.BlockStmt, name string, sourcePkg *packages.Package) {
+	// http.HandlerFunc(fn)
+	if call, ok := arg.(*ast.CallExpr); ok && len(call.Args) == 1 && pkg.TypesInfo.Types[call.Fun].IsType() {
+		arg = call.Args[0]
+	}
+
+	ty := pkg.TypesInfo.TypeOf(arg)
+	if _, isFunc := ty.Underlying().(*types.Signature); isFunc {
+		return parseEndpointFunc(arg, pkg)
+	}
+
+	// value implementing http.Handler
+	if ptr, isPointer := ty.(*types.Pointer); isPointer {
+		ty = ptr.Elem()
+	}
+	named, ok := ty.(*types.Named)
+	if !ok {
+		panic(routeError("unsupported handler %s", types.ExprString(arg)))
+	}
+	body, _, sourcePkg = resolveFunc(pkg, selectMethod(named, "ServeHTTP"))
+	return body, named.Obj().Name(), sourcePkg
+}
+
+// netHTTPExtractor scans a file using the net/http package, looking for calls to
+// Handle and HandleFunc (either on a ServeMux or at the package level).
+func (netHTTPExtractor) extract(pkg *packages.Package, fi *ast.File, diags *analysis.Diagnostics) []Endpoint {
+	comments := lineComments(pkg, fi)
+
+	var out []Endpoint
+	ast.Inspect(fi, func(n ast.Node) bool {
+		callExpr, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		selector, ok := callExpr.Fun.(*ast.SelectorExpr)
+		if !ok || len(callExpr.Args) != 2 || !isNetHTTPFunc(selector, pkg.TypesInfo, "Handle", "HandleFunc") {
+			return true
+		}
+
+		isIgnored := false
+		diags.Try(callExpr.Pos(), func() { // report invalid routes and skip them
+			line := pkg.Fset.Position(callExpr.Pos()).Line
+			comment := newSpecialComment(comments[line])
+			if comment == ignore {
+				log.Println("ignoring route at line", line)
+				isIgnored = true
+				return
+			}
+
+			pattern, err := resolveConstString(callExpr.Args[0], pkg)
+			if err != nil {
+				panic(routeError("invalid endpoint pattern: %s", err))
+			}
+			method, path, hasMethod := splitPattern(pattern)
+			if !hasMethod {
+				diags.Warnf(callExpr.Pos(), analysis.CodeUnsupportedRoute, "no method in pattern %q: the route matches all the methods, and is typed as GET", pattern)
+			}
+
+			body, name, sourcePkg := resolveHandler(callExpr.Args[1], pkg)
+			contract := newContractFromNetHTTPBody(sourcePkg, body, name)
+
+			// type the wildcards, using the PathValue calls
+			paramTypes := parseConvertedParams(body, sourcePkg, func(call *ast.CallExpr) string {
+				return parseNetHTTPCall(call, sourcePkg, "PathValue")
+			})
+			contract.PathParams = typedPathParams(path, paramTypes)
+
+			out = append(out, Endpoint{pos: callExpr.Pos(), Url: path, Method: method, IsUrlOnly: comment == urlOnly, Contract: contract, Invalidates: parseInvalidates(comments[line])})
+		})
+
+		return !isIgnored
+	})
+
+	return out
+}
+
+// parseNetHTTPCall returns the string argument of a call to
+// the [methodName] method of net/http, or an empty string.
+func parseNetHTTPCall(call *ast.CallExpr, pkg *packages.Package, methodName string) string {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) != 1 || !isNetHTTPFunc(selector, pkg.TypesInfo, methodName) {
+		return ""
+	}
+	arg, err := resolveConstString(call.Args[0], pkg)
+	if err != nil {
+		panic(routeError("invalid %s argument: %s", methodName, err))
+	}
+	return arg
+}
+
+// parseQueryGet returns the argument of a <url.Values>.Get(<string>) call,
+// as in r.URL.Query().Get("id")
+func parseQueryGet(call *ast.CallExpr, pkg *packages.Package) string {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != "Get" || len(call.Args) != 1 {
+		return ""
+	}
+	if ty := pkg.TypesInfo.TypeOf(selector.X); ty == nil || ty.String() != "net/url.Values" {
+		return ""
+	}
+	arg, err := resolveConstString(call.Args[0], pkg)
+	if err != nil {
+		panic(routeError("invalid query parameter: %s", err))
+	}
+	return arg
+}
+
+// isJSONCoderCall matches json.New<coder>(...).<method>(arg),
+// where json is the encoding/json package
+func isJSONCoderCall(call *ast.CallExpr, pkg *types.Info, coder, method string) bool {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != method || len(call.Args) != 1 {
+		return false
+	}
+	inner, ok := selector.X.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	innerSelector, ok := inner.Fun.(*ast.SelectorExpr)
+	if !ok || innerSelector.Sel.Name != coder {
+		return false
+	}
+	fn, ok := pkg.Uses[innerSelector.Sel].(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == "encoding/json"
+}
+
+// Look for json.NewDecoder(r.Body).Decode(&x), r.URL.Query().Get(...),
+// and json.NewEncoder(w).Encode(y) calls.
+//
+// pkg is the package where the function is defined
+func newContractFromNetHTTPBody(pkg *packages.Package, body *ast.BlockStmt, contractName string) Contract {
+	out := Contract{Name: contractName}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if isJSONCoderCall(call, pkg.TypesInfo, "NewDecoder", "Decode") {
+			out.inputT = resolveBindTarget(call.Args[0], pkg.TypesInfo)
+		} else if isJSONCoderCall(call, pkg.TypesInfo, "NewEncoder", "Encode") {
+			out.returnT = pkg.TypesInfo.TypeOf(call.Args[0])
+		} else if queryParam := parseQueryGet(call, pkg); queryParam != "" {
+			out.InputQueryParams = append(out.InputQueryParams, TypedParam{Name: queryParam, type_: types.Typ[types.String]})
+		}
+		return true
+	})
+
+	return out
+}
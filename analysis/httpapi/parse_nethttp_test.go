@@ -0,0 +1,84 @@
+package httpapi
+
+import (
+	"go/types"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	tu "github.com/benoitkugler/gomacro/testutils"
+)
+
+func TestSplitPattern(t *testing.T) {
+	for _, test := range []struct {
+		pattern, method, path string
+		hasMethod             bool
+	}{
+		{"GET /items/{id}", http.MethodGet, "/items/{id}", true},
+		{"POST example.com/items", http.MethodPost, "/items", true},
+		{"/items/{$}", http.MethodGet, "/items/", false},
+		{"DELETE  /files/{path...}", http.MethodDelete, "/files/{path...}", true},
+	} {
+		method, path, hasMethod := splitPattern(test.pattern)
+		tu.Assert(t, method == test.method)
+		tu.Assert(t, path == test.path)
+		tu.Assert(t, hasMethod == test.hasMethod)
+	}
+
+}
+
+func TestParseNetHTTP(t *testing.T) {
+	fn := "test/nethttp/routes.go"
+	pack, err := analysis.LoadSource(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags := analysis.NewDiagnostics(pack.Fset)
+	apis := ParseNetHTTP(pack, abs, diags)
+	tu.Assert(t, len(apis) == 8)
+	for _, api := range apis { // the ignored routes are skipped, with their content
+		tu.Assert(t, !strings.HasPrefix(api.Url, "/ignored") && !strings.HasPrefix(api.Url, "/legacy"), api.Url)
+	}
+	// the method-less pattern is reported
+	list := diags.List()
+	tu.Assert(t, len(list) == 1 && list[0].Severity == analysis.SeverityWarning && list[0].Pos.Line == 69, list)
+	tu.Assert(t, strings.Contains(list[0].Message, `no method in pattern "/health"`))
+
+	items := apis[0]
+	tu.Assert(t, items.Url == "/items/" && items.Method == http.MethodGet)
+	tu.Assert(t, len(items.Contract.InputQueryParams) == 1 && items.Contract.InputQueryParams[0].Name == "filter")
+	tu.Assert(t, items.Contract.Return.Type().String() == "[]github.com/benoitkugler/gomacro/analysis/httpapi/test/nethttp.Item")
+
+	get := apis[1]
+	tu.Assert(t, len(get.Contract.PathParams) == 1)
+	tu.Assert(t, get.Contract.PathParams[0].Type.Type() == types.Typ[types.Int64])
+
+	create := apis[2]
+	tu.Assert(t, create.Method == http.MethodPost && create.Contract.InputBody != nil)
+
+	del := apis[3]
+	tu.Assert(t, del.Contract.Name == "deleteItem")
+	tu.Assert(t, len(del.Contract.PathParams) == 2)
+	tu.Assert(t, del.Contract.PathParams[0].Name == "owner" && del.Contract.PathParams[0].Type.Type() == types.Typ[types.String])
+	tu.Assert(t, del.Contract.PathParams[1].Name == "id" && del.Contract.PathParams[1].Type.Type() == types.Typ[types.Int])
+
+	files := apis[4]
+	tu.Assert(t, files.Url == "/files/{path...}" && files.Contract.Name == "fileHandler")
+	tu.Assert(t, len(files.Contract.PathParams) == 1)
+
+	health := apis[5]
+	tu.Assert(t, health.Method == http.MethodGet && health.Contract.Return == nil)
+
+	put := apis[6] // package level function
+	tu.Assert(t, put.Method == http.MethodPut)
+
+	export := apis[7] // only encoding/json calls are typed
+	tu.Assert(t, export.Contract.Return == nil)
+}
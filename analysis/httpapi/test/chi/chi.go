@@ -0,0 +1,27 @@
+// Package chi is a substitute for the http framework chi package.
+package chi
+
+import "net/http"
+
+type Middlewares []func(http.Handler) http.Handler
+
+type Router interface {
+	http.Handler
+
+	Use(middlewares ...func(http.Handler) http.Handler)
+	With(middlewares ...func(http.Handler) http.Handler) Router
+	Group(fn func(r Router)) Router
+	Route(pattern string, fn func(r Router)) Router
+
+	Get(pattern string, h http.HandlerFunc)
+	Post(pattern string, h http.HandlerFunc)
+	Put(pattern string, h http.HandlerFunc)
+	Delete(pattern string, h http.HandlerFunc)
+	Patch(pattern string, h http.HandlerFunc)
+	Head(pattern string, h http.HandlerFunc)
+	Options(pattern string, h http.HandlerFunc)
+}
+
+func NewRouter() Router { return nil }
+
+func URLParam(r *http.Request, key string) string { return "" }
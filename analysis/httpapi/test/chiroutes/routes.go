@@ -0,0 +1,55 @@
+// Package chiroutes is only used to test the chi extractor.
+package chiroutes
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/benoitkugler/gomacro/analysis/httpapi/test/chi"
+)
+
+type Item struct {
+	ID    int64
+	Label string
+}
+
+func listItems(w http.ResponseWriter, r *http.Request) {
+	_ = r.URL.Query().Get("filter")
+	var out []Item
+	json.NewEncoder(w).Encode(out)
+}
+
+func getItem(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	json.NewEncoder(w).Encode(Item{ID: id})
+}
+
+func createItem(w http.ResponseWriter, r *http.Request) {
+	var in Item
+	json.NewDecoder(r.Body).Decode(&in)
+}
+
+func auth(next http.Handler) http.Handler { return next }
+
+func routes(r chi.Router) {
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	r.Route("/api", func(r chi.Router) {
+		r.Get("/items", listItems)
+		r.Route("/items/{id:[0-9]+}", func(sub chi.Router) {
+			sub.Get("/", getItem)
+			sub.Delete("/", getItem)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth)
+			r.Post("/items", createItem)
+		})
+	})
+
+	admin := r.With(auth).Route("/admin", func(r chi.Router) {})
+	admin.Put("/items/{id}", createItem)
+
+	r.Get("/ignored", listItems) // ignore
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Code generated by gomacro/generator/go/goclient. DO NOT EDIT.
+
+// Anonymous103 performs the request POST /const_url_from_inner_package/endpoint/entoher/const_local_url
+func (c *Client) Anonymous103(ctx context.Context) error {
+	var query url.Values
+	var body clientBody
+	req, err := c.newRequest(ctx, "POST", "/const_url_from_inner_package/endpoint/entoher/const_local_url", query, body)
+	if err != nil {
+		return err
+	}
+	return c.send(req, nil)
+}
+
+// Anonymous113 performs the request GET /func litteral
+func (c *Client) Anonymous113(ctx context.Context) ([][]string, error) {
+	var out [][]string
+	var query url.Values
+	var body clientBody
+	req, err := c.newRequest(ctx, "GET", "/func litteral", query, body)
+	if err != nil {
+		return out, err
+	}
+	err = c.send(req, &out)
+	return out, err
+}
+
+// Anonymous119 performs the request GET /with middleware
+func (c *Client) Anonymous119(ctx context.Context, token string) error {
+	query := url.Values{}
+	query.Set("token", token)
+
+	var body clientBody
+	req, err := c.newRequest(ctx, "GET", "/with middleware", query, body)
+	if err != nil {
+		return err
+	}
+	return c.send(req, nil)
+}
+
+// Handle1 performs the request GET const_local_url
+func (c *Client) Handle1(ctx context.Context, params int) (string, error) {
+	var out string
+	var query url.Values
+	body, err := clientJSON(params)
+	if err != nil {
+		return out, err
+	}
+	req, err := c.newRequest(ctx, "GET", "const_local_url", query, body)
+	if err != nil {
+		return out, err
+	}
+	err = c.send(req, &out)
+	return out, err
+}
+
+// Handle1URL returns the URL for the endpoint POST /url-only
+func (c *Client) Handle1URL() string {
+	var query url.Values
+	if len(query) != 0 {
+		return c.BaseURL + "/url-only" + "?" + query.Encode()
+	}
+	return c.BaseURL + "/url-only"
+}
+
+// HandleExt performs the request POST /const_url_from_inner_package/
+func (c *Client) HandleExt(ctx context.Context, params []int64, query1 string, query2 string) (map[string][]int, error) {
+	var out map[string][]int
+	query := url.Values{}
+	query.Set("query1", query1)
+	query.Set("query2", query2)
+
+	body, err := clientJSON(params)
+	if err != nil {
+		return out, err
+	}
+	req, err := c.newRequest(ctx, "POST", "/const_url_from_inner_package/", query, body)
+	if err != nil {
+		return out, err
+	}
+	err = c.send(req, &out)
+	return out, err
+}
+
+// Handler performs the request GET /const_url_from_package/
+func (c *Client) Handler(ctx context.Context) error {
+	var query url.Values
+	var body clientBody
+	req, err := c.newRequest(ctx, "GET", "/const_url_from_package/", query, body)
+	if err != nil {
+		return err
+	}
+	return c.send(req, nil)
+}
+
+// Handler10 performs the request DELETE /with_generic
+func (c *Client) Handler10(ctx context.Context, paramName IdDossier) ([]byte, error) {
+	query := url.Values{}
+	query.Set("param-name", strconv.FormatInt(int64(paramName), 10))
+
+	var body clientBody
+	req, err := c.newRequest(ctx, "DELETE", "/with_generic", query, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.sendBlob(req)
+}
+
+// Handler11 performs the request GET /with_json_stream
+func (c *Client) Handler11(ctx context.Context) iter.Seq2[int, error] {
+	var query url.Values
+	var body clientBody
+	req, err := c.newRequest(ctx, "GET", "/with_json_stream", query, body)
+	if err != nil {
+		return clientStreamError[int](err)
+	}
+	return clientStream[int](c, req)
+}
+
+// Handler2 performs the request POST /const_url_from_inner_package/endpoint
+func (c *Client) Handler2(ctx context.Context) (controller, error) {
+	var out controller
+	var query url.Values
+	var body clientBody
+	req, err := c.newRequest(ctx, "POST", "/const_url_from_inner_package/endpoint", query, body)
+	if err != nil {
+		return out, err
+	}
+	err = c.send(req, &out)
+	return out, err
+}
+
+// Handler3 performs the request POST host/const_url_from_inner_package/
+func (c *Client) Handler3(ctx context.Context) error {
+	var query url.Values
+	var body clientBody
+	req, err := c.newRequest(ctx, "POST", "host/const_url_from_inner_package/", query, body)
+	if err != nil {
+		return err
+	}
+	return c.send(req, nil)
+}
+
+// Handler4 performs the request POST hostendpoint
+func (c *Client) Handler4(ctx context.Context) error {
+	var query url.Values
+	var body clientBody
+	req, err := c.newRequest(ctx, "POST", "hostendpoint", query, body)
+	if err != nil {
+		return err
+	}
+	return c.send(req, nil)
+}
+
+// Handler5 performs the request POST /string_litteral
+func (c *Client) Handler5(ctx context.Context) error {
+	var query url.Values
+	var body clientBody
+	req, err := c.newRequest(ctx, "POST", "/string_litteral", query, body)
+	if err != nil {
+		return err
+	}
+	return c.send(req, nil)
+}
+
+// Handler6 performs the request PUT /with_param/:param
+func (c *Client) Handler6(ctx context.Context, param int64) error {
+	var query url.Values
+	var body clientBody
+	req, err := c.newRequest(ctx, "PUT", "/with_param/"+url.PathEscape(strconv.FormatInt(param, 10)), query, body)
+	if err != nil {
+		return err
+	}
+	return c.send(req, nil)
+}
+
+// Handler7 performs the request DELETE /special_param_value/:class/route
+func (c *Client) Handler7(ctx context.Context, class string, jsonField uint32, myBool bool, myInt int64) (uint, error) {
+	var out uint
+	query := url.Values{}
+	query.Set("my-bool", clientBool(myBool))
+	query.Set("my-int", strconv.FormatInt(myInt, 10))
+
+	formJSON, err := clientFormJSON(jsonField)
+	if err != nil {
+		return out, err
+	}
+	body, err := clientForm("", ClientFile{}, "json-field", formJSON)
+	if err != nil {
+		return out, err
+	}
+	req, err := c.newRequest(ctx, "DELETE", "/special_param_value/"+url.PathEscape(class)+"/route", query, body)
+	if err != nil {
+		return out, err
+	}
+	err = c.send(req, &out)
+	return out, err
+}
+
+// Handler8 performs the request DELETE /special_param_value/:default/route
+func (c *Client) Handler8(ctx context.Context, defaultParam string, file ClientFile, value1 string, queryParam1 string, queryParam2 string) (uint, error) {
+	var out uint
+	query := url.Values{}
+	query.Set("query_param1", queryParam1)
+	query.Set("query_param2", queryParam2)
+
+	body, err := clientForm("file_2", file, "value_1", value1)
+	if err != nil {
+		return out, err
+	}
+	req, err := c.newRequest(ctx, "DELETE", "/special_param_value/"+url.PathEscape(defaultParam)+"/route", query, body)
+	if err != nil {
+		return out, err
+	}
+	err = c.send(req, &out)
+	return out, err
+}
+
+// Handler9 performs the request POST /download
+func (c *Client) Handler9(ctx context.Context) ([]byte, error) {
+	var query url.Values
+	var body clientBody
+	req, err := c.newRequest(ctx, "POST", "/download", query, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.sendBlob(req)
+}
+
+// TopLevel performs the request GET /extern function
+func (c *Client) TopLevel(ctx context.Context) error {
+	var query url.Values
+	var body clientBody
+	req, err := c.newRequest(ctx, "GET", "/extern function", query, body)
+	if err != nil {
+		return err
+	}
+	return c.send(req, nil)
+}
+
+// Client provides typed methods to call the HTTP API.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client // if nil, [http.DefaultClient] is used
+	Header     http.Header  // added to every request, for instance to provide authentication
+}
+
+// ClientFile is a file sent in a multipart form.
+type ClientFile struct {
+	Name    string
+	Content io.Reader
+}
+
+type clientBody struct {
+	content     io.Reader
+	contentType string
+}
+
+func clientJSON(v any) (clientBody, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return clientBody{}, err
+	}
+	return clientBody{bytes.NewReader(b), "application/json"}, nil
+}
+
+// clientForm builds a multipart form, with an optional file
+// and (key, value) pairs
+func clientForm(fileField string, file ClientFile, values ...string) (clientBody, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if fileField != "" {
+		fw, err := mw.CreateFormFile(fileField, file.Name)
+		if err != nil {
+			return clientBody{}, err
+		}
+		if _, err = io.Copy(fw, file.Content); err != nil {
+			return clientBody{}, err
+		}
+	}
+	for i := 0; i < len(values); i += 2 {
+		if err := mw.WriteField(values[i], values[i+1]); err != nil {
+			return clientBody{}, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return clientBody{}, err
+	}
+	return clientBody{&buf, mw.FormDataContentType()}, nil
+}
+
+// clientFormJSON returns the JSON encoding of v, as a string
+func clientFormJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// clientWildcard escapes a path parameter which may contain slashes
+func clientWildcard(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "%2F", "/")
+}
+
+// clientBool matches the encoding used by the TypeScript clients
+func clientBool(b bool) string {
+	if b {
+		return "ok"
+	}
+	return ""
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body clientBody) (*http.Request, error) {
+	fullURL := c.BaseURL + path
+	if len(query) != 0 {
+		fullURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, body.content)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range c.Header {
+		req.Header[k] = append(req.Header[k], vs...)
+	}
+	if body.contentType != "" {
+		req.Header.Set("Content-Type", body.contentType)
+	}
+	return req, nil
+}
+
+// do performs the request, returning an error for non 2XX status codes
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s %s: %s (%s)", req.Method, req.URL, resp.Status, bytes.TrimSpace(body))
+	}
+	return resp, nil
+}
+
+// send performs the request and decodes the JSON response into [out], if not nil
+func (c *Client) send(req *http.Request, out any) error {
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// sendBlob performs the request and returns the (binary) response
+func (c *Client) sendBlob(req *http.Request) ([]byte, error) {
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// clientStream performs the request and decodes the JSON values,
+// written one per line
+func clientStream[T any](c *Client, req *http.Request) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		resp, err := c.do(req)
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var item T
+			err := dec.Decode(&item)
+			if err == io.EOF {
+				return
+			}
+			if !yield(item, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// clientStreamError returns a sequence yielding only [err]
+func clientStreamError[T any](err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		yield(zero, err)
+	}
+}
@@ -12,16 +12,48 @@ type Context interface {
 	Bind(interface{}) error
 	JSON(int, interface{}) error
 	QueryParam(string) string
+	Param(string) string
 	Blob(code int, contentType string, b []byte) error
+	NoContent(code int) error
 	FormValue(name string) string
 	FormFile(name string) (*multipart.FileHeader, error)
 }
 
 type Echo struct{}
 
-func (Echo) GET(string, func(Context) error, ...any) {}
-func (Echo) POST(string, func(Context) error)        {}
-func (Echo) PUT(string, func(Context) error)         {}
-func (Echo) DELETE(string, func(Context) error)      {}
+func (Echo) GET(string, func(Context) error, ...any)             {}
+func (Echo) POST(string, func(Context) error)                    {}
+func (Echo) PUT(string, func(Context) error)                     {}
+func (Echo) DELETE(string, func(Context) error)                  {}
+func (Echo) PATCH(string, func(Context) error, ...any)           {}
+func (Echo) HEAD(string, func(Context) error, ...any)            {}
+func (Echo) OPTIONS(string, func(Context) error, ...any)         {}
+func (Echo) Any(string, func(Context) error, ...any)             {}
+func (Echo) Match([]string, string, func(Context) error, ...any) {}
+func (Echo) Group(string, ...any) *Group                         { return nil }
+func (Echo) Use(...any)                                          {}
+
+type Group struct{}
+
+func (*Group) GET(string, func(Context) error, ...any)             {}
+func (*Group) POST(string, func(Context) error, ...any)            {}
+func (*Group) PUT(string, func(Context) error, ...any)             {}
+func (*Group) DELETE(string, func(Context) error, ...any)          {}
+func (*Group) PATCH(string, func(Context) error, ...any)           {}
+func (*Group) HEAD(string, func(Context) error, ...any)            {}
+func (*Group) OPTIONS(string, func(Context) error, ...any)         {}
+func (*Group) Any(string, func(Context) error, ...any)             {}
+func (*Group) Match([]string, string, func(Context) error, ...any) {}
+func (*Group) Group(string, ...any) *Group                         { return nil }
+func (*Group) Use(...any)                                          {}
+
+type HTTPError struct {
+	Code    int
+	Message any
+}
+
+func (*HTTPError) Error() string { return "" }
+
+func NewHTTPError(code int, message ...any) *HTTPError { return nil }
 
 func StreamJSON[K any](*http.Response, iter.Seq2[K, error]) error { return nil }
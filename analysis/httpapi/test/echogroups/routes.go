@@ -0,0 +1,100 @@
+// Package echogroups is only used to test the support
+// for Echo groups and methods.
+package echogroups
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/benoitkugler/gomacro/analysis/httpapi/test/echo"
+)
+
+type Item struct {
+	ID    int64
+	Label string
+}
+
+type ErrPayload struct {
+	Reason string
+}
+
+type ValidationErrors []string
+
+type controller struct{}
+
+func (controller) JWTMiddlewareForQuery() bool { return false }
+
+func logger() bool { return false }
+
+func getItem(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+	if id == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, ErrPayload{Reason: "not found"})
+	}
+	return c.JSON(200, Item{ID: id})
+}
+
+func listItems(c echo.Context) error {
+	var out []Item
+	return c.JSON(200, out)
+}
+
+func createItem(c echo.Context) error {
+	var in Item
+	if err := c.Bind(&in); err != nil {
+		return err
+	}
+	if in.Label == "" {
+		return c.JSON(http.StatusUnprocessableEntity, ValidationErrors{"Label"})
+	}
+	return c.JSON(200, in)
+}
+
+func download(c echo.Context) error {
+	return c.Blob(200, "text/plain", nil)
+}
+
+const apiPrefix = "/api"
+
+// registerItems is called with different groups
+func registerItems(g *echo.Group) {
+	g.GET("/items", listItems)
+	g.GET("/items/:id", getItem)
+}
+
+func routes(e echo.Echo, ct controller) {
+	e.GET("/health", listItems)
+
+	api := e.Group(apiPrefix, logger())
+	registerItems(api)
+
+	v2 := api.Group("/v2")
+	v2.POST("/items", createItem) // invalidates: /api/items, /api/admin/items
+
+	admin := api.Group("/admin", ct.JWTMiddlewareForQuery())
+	registerItems(admin)
+
+	files := e.Group("/files")
+	files.Use(ct.JWTMiddlewareForQuery())
+	files.GET("/download", download)
+}
+
+func updateItem(c echo.Context) error {
+	var in Item
+	if err := c.Bind(&in); err != nil {
+		return err
+	}
+	return c.NoContent(200)
+}
+
+func ping(c echo.Context) error { return c.NoContent(200) }
+
+func methodRoutes(e echo.Echo) {
+	api := e.Group(apiPrefix)
+	api.PATCH("/items/:id", updateItem)
+	api.Match([]string{http.MethodPut, "PATCH"}, "/items", updateItem)
+	e.Any("/ping", ping)
+}
@@ -0,0 +1,43 @@
+// Package gin is a substitute for the http framework gin package.
+package gin
+
+import "mime/multipart"
+
+type H map[string]any
+
+type Context struct{}
+
+func (c *Context) ShouldBindJSON(obj any) error                        { return nil }
+func (c *Context) BindJSON(obj any) error                              { return nil }
+func (c *Context) Query(key string) string                             { return "" }
+func (c *Context) DefaultQuery(key, defaultValue string) string        { return "" }
+func (c *Context) Param(key string) string                             { return "" }
+func (c *Context) PostForm(key string) string                          { return "" }
+func (c *Context) FormFile(name string) (*multipart.FileHeader, error) { return nil, nil }
+func (c *Context) JSON(code int, obj any)                              {}
+func (c *Context) Data(code int, contentType string, data []byte)      {}
+func (c *Context) AbortWithStatus(code int)                            {}
+func (c *Context) AbortWithStatusJSON(code int, obj any)               {}
+func (c *Context) Status(code int)                                     {}
+
+type HandlerFunc func(*Context)
+
+type RouterGroup struct{}
+
+func (group *RouterGroup) Group(relativePath string, handlers ...HandlerFunc) *RouterGroup {
+	return nil
+}
+
+func (group *RouterGroup) GET(relativePath string, handlers ...HandlerFunc)     {}
+func (group *RouterGroup) POST(relativePath string, handlers ...HandlerFunc)    {}
+func (group *RouterGroup) PUT(relativePath string, handlers ...HandlerFunc)     {}
+func (group *RouterGroup) DELETE(relativePath string, handlers ...HandlerFunc)  {}
+func (group *RouterGroup) PATCH(relativePath string, handlers ...HandlerFunc)   {}
+func (group *RouterGroup) HEAD(relativePath string, handlers ...HandlerFunc)    {}
+func (group *RouterGroup) OPTIONS(relativePath string, handlers ...HandlerFunc) {}
+
+type Engine struct {
+	RouterGroup
+}
+
+func New() *Engine { return nil }
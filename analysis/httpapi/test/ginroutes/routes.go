@@ -0,0 +1,66 @@
+// Package ginroutes is only used to test the gin extractor.
+package ginroutes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/benoitkugler/gomacro/analysis/httpapi/test/gin"
+)
+
+type Item struct {
+	ID    int64
+	Label string
+}
+
+type controller struct{}
+
+func (controller) listItems(c *gin.Context) {
+	_ = c.Query("filter")
+	_ = c.DefaultQuery("sort", "id")
+	var out []Item
+	c.JSON(http.StatusOK, out)
+}
+
+func (controller) getItem(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, Item{ID: int64(id)})
+}
+
+func (controller) createItem(c *gin.Context) {
+	var in Item
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	c.JSON(http.StatusOK, in)
+}
+
+func (controller) upload(c *gin.Context) {
+	_ = c.PostForm("label")
+	_, _ = c.FormFile("file")
+	var content []byte
+	c.Data(http.StatusOK, "application/octet-stream", content)
+}
+
+func auth(c *gin.Context) {}
+
+func routes(r *gin.Engine, ct controller) {
+	r.GET("/health", func(c *gin.Context) {})
+
+	v1 := r.Group("/api/v1")
+	{
+		v1.GET("/items", ct.listItems)
+		v1.GET("/items/:id", ct.getItem)
+
+		admin := v1.Group("/admin", auth)
+		admin.POST("/items", auth, ct.createItem)
+		admin.PUT("/files/*path", ct.upload)
+	}
+
+	r.GET("/ignored", ct.listItems) // ignore
+}
@@ -0,0 +1,79 @@
+// Package nethttp is only used to test the net/http extractor.
+package nethttp
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+)
+
+type Item struct {
+	ID    int64
+	Label string
+}
+
+func listItems(w http.ResponseWriter, r *http.Request) {
+	filter := r.URL.Query().Get("filter")
+	_ = filter
+	var out []Item
+	json.NewEncoder(w).Encode(out)
+}
+
+func getItem(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(Item{ID: id})
+}
+
+func createItem(w http.ResponseWriter, r *http.Request) {
+	var in Item
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(in)
+}
+
+// exportItems is not a JSON endpoint
+func exportItems(w http.ResponseWriter, r *http.Request) {
+	var out []Item
+	xml.NewEncoder(w).Encode(out)
+}
+
+type controller struct{}
+
+func (controller) deleteItem(w http.ResponseWriter, r *http.Request) {
+	idS := r.PathValue("id")
+	id, _ := strconv.Atoi(idS)
+	owner := r.PathValue("owner")
+	_, _ = id, owner
+}
+
+type fileHandler struct{}
+
+func (fileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("path")
+	_ = name
+}
+
+func routes(mux *http.ServeMux, ct controller) {
+	mux.HandleFunc("GET /items/{$}", listItems)
+	mux.HandleFunc("GET /items/{id}", getItem)
+	mux.HandleFunc("POST /items", createItem)
+	mux.HandleFunc("DELETE /owners/{owner}/items/{id}", ct.deleteItem)
+	mux.Handle("GET example.com/files/{path...}", fileHandler{})
+	mux.Handle("/health", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	mux.HandleFunc("GET /ignored", listItems) // ignore
+	mux.HandleFunc("GET /legacy", func(w http.ResponseWriter, r *http.Request) { // ignore
+		// skipped with the enclosing route
+		mux.HandleFunc("GET /legacy/nested", listItems)
+	})
+
+	http.HandleFunc("PUT /items/{id}", createItem)
+	http.HandleFunc("GET /export", exportItems)
+}
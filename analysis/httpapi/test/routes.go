@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"iter"
+	"strconv"
 
 	"github.com/benoitkugler/gomacro/analysis/httpapi/test/echo"
 	"github.com/benoitkugler/gomacro/analysis/httpapi/test/inner"
@@ -40,7 +41,14 @@ func (controller) handler2(c echo.Context) error {
 func (controller) handler3(echo.Context) error { return nil }
 func (controller) handler4(echo.Context) error { return nil }
 func (controller) handler5(echo.Context) error { return nil }
-func (controller) handler6(echo.Context) error { return nil }
+func (controller) handler6(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("param"), 10, 64)
+	if err != nil {
+		return err
+	}
+	_ = id
+	return nil
+}
 
 // special converters
 func (ct controller) handler7(c echo.Context) error {
@@ -4,6 +4,7 @@ package sql
 
 import (
 	"fmt"
+	"go/token"
 	"go/types"
 	"reflect"
 	"regexp"
@@ -13,13 +14,14 @@ import (
 )
 
 // SelectTables returns the SQL tables found in the given analysis.
-func SelectTables(ana *an.Analysis) (out []Table) {
+// See [NewTable] for the errors reported to [diags].
+func SelectTables(ana *an.Analysis, diags *an.Diagnostics) (out []Table) {
 	for _, ty := range ana.Source {
 		st, ok := ana.Types[ty].(*an.Struct)
-		if !ok {
+		if !ok || len(st.TypeParams) != 0 { // generic declarations are not tables
 			continue
 		}
-		out = append(out, NewTable(st))
+		out = append(out, NewTable(st, diags))
 	}
 	return out
 }
@@ -108,17 +110,19 @@ type ForeignKey struct {
 
 func (ta *Table) newForeignKey(field an.StructField) (ForeignKey, bool) {
 	out := ForeignKey{F: field, IsUnique: ta.uniqueColumns[field.Field.Name()]}
+	// pointers are used for optional keys
+	fieldType := field.Type
+	if ptr, isPointer := fieldType.(*an.Pointer); isPointer {
+		fieldType = ptr.Elem
+	}
 	// look for an ID type
-	if table := isTableID(field.Type); table != "" && string(table) != ta.Name.Obj().Name() {
+	if table := isTableID(fieldType); table != "" && string(table) != ta.Name.Obj().Name() {
 		out.Target = table
 		return out, true
 	}
 
-	// look for a tag
-	if table := reflect.StructTag(field.Tag).Get("gomacro-sql-foreign"); table != "" {
-		if !(IsInt64(field.Type.Type()) || isNullInt64(field.Type)) {
-			panic("invalid type for foreign key " + field.Field.Name())
-		}
+	// look for a tag; invalid types are reported by [NewTable]
+	if table := reflect.StructTag(field.Tag).Get("gomacro-sql-foreign"); table != "" && isForeignKeyType(fieldType) {
 		out.Target = TableName(table)
 		return out, true
 	}
@@ -126,14 +130,23 @@ func (ta *Table) newForeignKey(field an.StructField) (ForeignKey, bool) {
 	return ForeignKey{}, false
 }
 
+// isForeignKeyType returns true for int64 and sql.NullInt64 types
+func isForeignKeyType(ty an.Type) bool {
+	return IsInt64(ty.Type()) || isNullInt64(ty)
+}
+
 // IsNullable returns true if the key is optional.
-// If so, the name field containing the integer is also returned
+// If so, the name field containing the integer is also returned,
+// or an empty string for pointers.
 func (fk ForeignKey) IsNullable() (bool, string) {
 	ty := fk.F.Field.Type()
 	if IsInt64(ty) {
 		return false, ""
 	}
 	ty = types.Unalias(ty)
+	if _, isPointer := ty.(*types.Pointer); isPointer {
+		return true, ""
+	}
 	wrapped := IsNullXXX(ty.(*types.Named))
 	return true, wrapped.Name()
 }
@@ -144,6 +157,9 @@ func (fk ForeignKey) IsNullable() (bool, string) {
 func (fk ForeignKey) TargetIDType() types.Type {
 	ty := fk.F.Field.Type()
 	ty = types.Unalias(ty)
+	if ptr, isPointer := ty.(*types.Pointer); isPointer {
+		return ptr.Elem()
+	}
 	if named, isNamed := ty.(*types.Named); isNamed {
 		if wrapped := IsNullXXX(named); wrapped != nil {
 			return wrapped.Type()
@@ -166,6 +182,16 @@ type Column struct {
 	Field an.StructField
 }
 
+// IsNullable returns `true` if the column accepts NULL values,
+// that is if its Go type is a pointer or comes from a sql.NullXXX type.
+func (col Column) IsNullable() bool {
+	if _, isPointer := col.Field.Type.(*an.Pointer); isPointer {
+		return true
+	}
+	builtin, isBuiltin := col.SQLType.(Builtin)
+	return isBuiltin && builtin.IsNullable()
+}
+
 // Table is a Struct used as SQL table.
 type Table struct {
 	Name *types.Named
@@ -189,7 +215,10 @@ type Table struct {
 	selectKeys [][]string
 }
 
-func NewTable(s *an.Struct) Table {
+// NewTable returns the table defined by [s].
+// Inconsistent definitions, like foreign keys with an invalid type or custom queries
+// using unknown fields, are reported to [diags] and ignored.
+func NewTable(s *an.Struct, diags *an.Diagnostics) Table {
 	out := Table{
 		Name: s.Name,
 	}
@@ -198,13 +227,23 @@ func NewTable(s *an.Struct) Table {
 			continue
 		}
 
+		if fi.Tag.Get("gomacro-sql-foreign") != "" {
+			fieldType := fi.Type
+			if ptr, isPointer := fieldType.(*an.Pointer); isPointer {
+				fieldType = ptr.Elem
+			}
+			if !isForeignKeyType(fieldType) {
+				diags.Errorf(fi.Field.Pos(), an.CodeInvalidSQL, "invalid type %s for foreign key %s: expected int64 or sql.NullInt64", fi.Field.Type(), fi.Field.Name())
+			}
+		}
+
 		out.Columns = append(out.Columns, Column{
 			Field:   fi,
 			SQLType: newType(fi.Type),
 		})
 	}
 
-	out.processComments(s.Comments)
+	out.processComments(s.Comments, diags)
 
 	return out
 }
@@ -214,7 +253,7 @@ func (ta *Table) TableName() TableName {
 	return TableName(ta.Name.Obj().Name())
 }
 
-func (ta *Table) processComments(comments []an.SpecialComment) {
+func (ta *Table) processComments(comments []an.SpecialComment, diags *an.Diagnostics) {
 	ta.uniqueColumns = make(map[string]bool)
 
 	// goName -> type
@@ -225,7 +264,12 @@ func (ta *Table) processComments(comments []an.SpecialComment) {
 
 	for _, comment := range comments {
 		if comment.Kind == an.CommentQuery {
-			ta.CustomQueries = append(ta.CustomQueries, newCustomQuery(byName, comment.Content))
+			query, err := newCustomQuery(byName, comment.Content)
+			if err != nil {
+				diags.Errorf(comment.Pos, an.CodeInvalidSQL, "invalid custom query: %s", err)
+				continue
+			}
+			ta.CustomQueries = append(ta.CustomQueries, query)
 			continue
 		} else if comment.Kind != an.CommentSQL {
 			continue
@@ -303,7 +347,7 @@ func (ta Table) AdditionalUniqueCols() [][]Column {
 			var ok bool
 			cols[i], ok = colsByName[name]
 			if !ok {
-				panic(fmt.Sprintf("unknown column name %s in table %s", name, ta.TableName()))
+				panic(an.NewError(token.Position{}, an.CodeInvalidSQL, "unknown column name %s in table %s", name, ta.TableName()))
 			}
 		}
 
@@ -352,7 +396,7 @@ var (
 	reCustomQueryArrayFields = regexp.MustCompile(`(\w+)\s*=\s*ANY\(\$(\w+)\$\)`)
 )
 
-func newCustomQuery(columsByName map[string]types.Type, comment string) (out CustomQuery) {
+func newCustomQuery(columsByName map[string]types.Type, comment string) (out CustomQuery, _ error) {
 	out.GoFunctionName, out.Query, _ = strings.Cut(comment, " ")
 	// extract fields
 	fieldToIndex := map[string]int{}
@@ -367,7 +411,7 @@ func newCustomQuery(columsByName map[string]types.Type, comment string) (out Cus
 		fieldToIndex[varName] = len(fieldToIndex) + 1
 		ty, ok := columsByName[goField]
 		if !ok {
-			panic("unknown field " + goField)
+			return out, fmt.Errorf("unknown field %s", goField)
 		}
 
 		out.Inputs = append(out.Inputs, CustomQueryInput{varName, ty})
@@ -380,7 +424,7 @@ func newCustomQuery(columsByName map[string]types.Type, comment string) (out Cus
 		fieldToIndex[varName] = len(fieldToIndex) + 1
 		elemTy, ok := columsByName[goField]
 		if !ok {
-			panic("unknown field " + goField)
+			return out, fmt.Errorf("unknown field %s", goField)
 		}
 
 		out.Inputs = append(out.Inputs, CustomQueryInput{varName, types.NewSlice(elemTy)})
@@ -393,5 +437,5 @@ func newCustomQuery(columsByName map[string]types.Type, comment string) (out Cus
 	}
 	out.Query = strings.NewReplacer(oldNew...).Replace(out.Query)
 
-	return out
+	return out, nil
 }
@@ -12,12 +12,12 @@ func TestSQL(t *testing.T) {
 	pkg, err := analysis.LoadSource(fn)
 	Assert(t, err == nil)
 
-	an := analysis.NewAnalysisFromFile(pkg, fn)
+	an := analysis.NewAnalysisFromFile(pkg, fn, nil)
 
 	Assert(t, isTableID(an.Types[Lookup(an.Pkg, "RepasID")]) == "Repas")
 	Assert(t, isTableID(an.Types[Lookup(an.Pkg, "IDInvalid")]) == "")
 
-	table1 := NewTable(an.Types[Lookup(an.Pkg, "Table1")].(*analysis.Struct))
+	table1 := NewTable(an.Types[Lookup(an.Pkg, "Table1")].(*analysis.Struct), nil)
 	Assert(t, len(table1.ForeignKeys()) == 5)
 	Assert(t, table1.Primary() == 0)
 	Assert(t, table1.ForeignKeys()[0].TargetIDType() == Lookup(an.Pkg, "RepasID"))
@@ -40,22 +40,22 @@ func TestSQL(t *testing.T) {
 	Assert(t, len(table1.CustomQueries) == 2)
 	Assert(t, len(table1.CustomQueries[1].Inputs) == 2)
 
-	repas := NewTable(an.Types[Lookup(an.Pkg, "Repas")].(*analysis.Struct))
+	repas := NewTable(an.Types[Lookup(an.Pkg, "Repas")].(*analysis.Struct), nil)
 	Assert(t, len(repas.ForeignKeys()) == 0)
 	Assert(t, repas.Primary() == 1)
 
-	link := NewTable(an.Types[Lookup(an.Pkg, "Link")].(*analysis.Struct))
+	link := NewTable(an.Types[Lookup(an.Pkg, "Link")].(*analysis.Struct), nil)
 	Assert(t, link.Primary() == -1)
 
-	question := NewTable(an.Types[Lookup(an.Pkg, "Question")].(*analysis.Struct))
+	question := NewTable(an.Types[Lookup(an.Pkg, "Question")].(*analysis.Struct), nil)
 	Assert(t, len(question.ForeignKeys()) == 1)
 	isNullable, name := question.ForeignKeys()[0].IsNullable()
 	Assert(t, isNullable && name == "Int64")
 
-	exercicesQuestion := NewTable(an.Types[Lookup(an.Pkg, "ExerciceQuestion")].(*analysis.Struct))
+	exercicesQuestion := NewTable(an.Types[Lookup(an.Pkg, "ExerciceQuestion")].(*analysis.Struct), nil)
 	Assert(t, len(exercicesQuestion.AdditionalUniqueCols()) == 1)
 
-	withOptTime := NewTable(an.Types[Lookup(an.Pkg, "WithOptionalTime")].(*analysis.Struct))
+	withOptTime := NewTable(an.Types[Lookup(an.Pkg, "WithOptionalTime")].(*analysis.Struct), nil)
 	Assert(t, len(withOptTime.Columns) == 3)
 	bt, ok := withOptTime.Columns[1].SQLType.(Builtin)
 	Assert(t, ok)
@@ -68,6 +68,34 @@ func TestSQL(t *testing.T) {
 	Assert(t, isComposite(composite))
 }
 
+func TestPointers(t *testing.T) {
+	fn := "test/models.go"
+	pkg, err := analysis.LoadSource(fn)
+	Assert(t, err == nil)
+
+	an := analysis.NewAnalysisFromFile(pkg, fn, nil)
+
+	table := NewTable(an.Types[Lookup(an.Pkg, "WithPointers")].(*analysis.Struct), nil)
+	Assert(t, len(table.Columns) == 6)
+	Assert(t, !table.Columns[0].IsNullable())
+	for _, col := range table.Columns[1:] {
+		Assert(t, col.IsNullable())
+	}
+	_, ok := table.Columns[2].SQLType.(Builtin)
+	Assert(t, ok)
+	_, ok = table.Columns[4].SQLType.(Enum)
+	Assert(t, ok)
+	_, ok = table.Columns[5].SQLType.(JSON)
+	Assert(t, ok)
+
+	Assert(t, len(table.ForeignKeys()) == 1)
+	fk := table.ForeignKeys()[0]
+	Assert(t, fk.Target == "Repas")
+	Assert(t, fk.TargetIDType() == Lookup(an.Pkg, "RepasID"))
+	isNullable, name := fk.IsNullable()
+	Assert(t, isNullable && name == "")
+}
+
 func TestCustomQueries(t *testing.T) {
 	matches := reCustomQueryFields.FindAllStringSubmatch("UPDATE Participant SET IdPersonne = $v1$ WHERE IdPersonne = $v2$", -1)
 	Assert(t, len(matches) == 2)
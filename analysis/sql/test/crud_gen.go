@@ -1574,6 +1574,146 @@ func DeleteWithOptionalTimesByIDs(tx DB, ids ...int64) ([]int64, error) {
 	return Scanint64Array(rows)
 }
 
+func scanOneWithPointers(row scanner) (WithPointers, error) {
+	var item WithPointers
+	err := row.Scan(
+		&item.Id,
+		&item.IdRepas,
+		&item.Label,
+		&item.Deadline,
+		&item.Flow,
+		&item.Params,
+	)
+	return item, err
+}
+
+func ScanWithPointers(row *sql.Row) (WithPointers, error) { return scanOneWithPointers(row) }
+
+// SelectAll returns all the items in the with_pointerss table.
+func SelectAllWithPointerss(db DB) (WithPointerss, error) {
+	rows, err := db.Query("SELECT id, idrepas, label, deadline, flow, params FROM with_pointerss")
+	if err != nil {
+		return nil, err
+	}
+	return ScanWithPointerss(rows)
+}
+
+// SelectWithPointers returns the entry matching 'id'.
+func SelectWithPointers(tx DB, id int64) (WithPointers, error) {
+	row := tx.QueryRow("SELECT id, idrepas, label, deadline, flow, params FROM with_pointerss WHERE id = $1", id)
+	return ScanWithPointers(row)
+}
+
+// SelectWithPointerss returns the entry matching the given 'ids'.
+func SelectWithPointerss(tx DB, ids ...int64) (WithPointerss, error) {
+	rows, err := tx.Query("SELECT id, idrepas, label, deadline, flow, params FROM with_pointerss WHERE id = ANY($1)", int64ArrayToPQ(ids))
+	if err != nil {
+		return nil, err
+	}
+	return ScanWithPointerss(rows)
+}
+
+type WithPointerss map[int64]WithPointers
+
+func (m WithPointerss) IDs() []int64 {
+	out := make([]int64, 0, len(m))
+	for i := range m {
+		out = append(out, i)
+	}
+	return out
+}
+
+func ScanWithPointerss(rs *sql.Rows) (WithPointerss, error) {
+	var (
+		s   WithPointers
+		err error
+	)
+	defer func() {
+		errClose := rs.Close()
+		if err == nil {
+			err = errClose
+		}
+	}()
+	structs := make(WithPointerss, 16)
+	for rs.Next() {
+		s, err = scanOneWithPointers(rs)
+		if err != nil {
+			return nil, err
+		}
+		structs[s.Id] = s
+	}
+	if err = rs.Err(); err != nil {
+		return nil, err
+	}
+	return structs, nil
+}
+
+// Insert one WithPointers in the database and returns the item with id filled.
+func (item WithPointers) Insert(tx DB) (out WithPointers, err error) {
+	row := tx.QueryRow(`INSERT INTO with_pointerss (
+		idrepas, label, deadline, flow, params
+		) VALUES (
+		$1, $2, $3, $4, $5
+		) RETURNING id, idrepas, label, deadline, flow, params;
+		`, item.IdRepas, item.Label, item.Deadline, item.Flow, item.Params)
+	return ScanWithPointers(row)
+}
+
+// Update WithPointers in the database and returns the new version.
+func (item WithPointers) Update(tx DB) (out WithPointers, err error) {
+	row := tx.QueryRow(`UPDATE with_pointerss SET (
+		idrepas, label, deadline, flow, params
+		) = (
+		$1, $2, $3, $4, $5
+		) WHERE id = $6 RETURNING id, idrepas, label, deadline, flow, params;
+		`, item.IdRepas, item.Label, item.Deadline, item.Flow, item.Params, item.Id)
+	return ScanWithPointers(row)
+}
+
+// Deletes the WithPointers and returns the item
+func DeleteWithPointersById(tx DB, id int64) (WithPointers, error) {
+	row := tx.QueryRow("DELETE FROM with_pointerss WHERE id = $1 RETURNING id, idrepas, label, deadline, flow, params;", id)
+	return ScanWithPointers(row)
+}
+
+// Deletes the WithPointers in the database and returns the ids.
+func DeleteWithPointerssByIDs(tx DB, ids ...int64) ([]int64, error) {
+	rows, err := tx.Query("DELETE FROM with_pointerss WHERE id = ANY($1) RETURNING id", int64ArrayToPQ(ids))
+	if err != nil {
+		return nil, err
+	}
+	return Scanint64Array(rows)
+}
+
+// IdRepass returns the list of non nil IdRepas
+// contained in this table.
+// They are not garanteed to be distinct.
+func (items WithPointerss) IdRepass() []RepasID {
+	var out []RepasID
+	for _, target := range items {
+		if id := target.IdRepas; id != nil {
+			out = append(out, *id)
+		}
+	}
+	return out
+}
+
+func SelectWithPointerssByIdRepass(tx DB, idRepass_ ...RepasID) (WithPointerss, error) {
+	rows, err := tx.Query("SELECT id, idrepas, label, deadline, flow, params FROM with_pointerss WHERE idrepas = ANY($1)", RepasIDArrayToPQ(idRepass_))
+	if err != nil {
+		return nil, err
+	}
+	return ScanWithPointerss(rows)
+}
+
+func DeleteWithPointerssByIdRepass(tx DB, idRepass_ ...RepasID) (WithPointerss, error) {
+	rows, err := tx.Query("DELETE FROM with_pointerss WHERE idrepas = ANY($1) RETURNING id, idrepas, label, deadline, flow, params", RepasIDArrayToPQ(idRepass_))
+	if err != nil {
+		return nil, err
+	}
+	return ScanWithPointerss(rows)
+}
+
 func loadJSON(out any, src any) error {
 	if src == nil {
 		return nil //zero value out
@@ -1985,6 +2125,9 @@ func (s int64Set) Keys() []int64 {
 func (s *Map) Scan(src any) error          { return loadJSON(s, src) }
 func (s Map) Value() (driver.Value, error) { return dumpJSON(s) }
 
+func (s *Settings) Scan(src any) error          { return loadJSON(s, src) }
+func (s Settings) Value() (driver.Value, error) { return dumpJSON(s) }
+
 func (s *defined) Scan(src any) error {
 	var tmp sql.NullInt64
 	err := tmp.Scan(src)
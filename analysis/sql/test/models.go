@@ -146,3 +146,13 @@ type WithOptionalTime struct {
 	Deadine    time.Time
 	DeadineOpt sql.NullTime
 }
+
+// WithPointers uses pointers for nullable columns
+type WithPointers struct {
+	Id       int64
+	IdRepas  *RepasID `gomacro-sql-on-delete:"SET NULL"`
+	Label    *string
+	Deadline *time.Time
+	Flow     *LocalEnum
+	Params   *Settings
+}
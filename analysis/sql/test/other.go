@@ -28,3 +28,9 @@ type defined optGeneric[IdQuestion]
 type OptAlias = defined
 
 type Advance [10]testsource.EnumUInt
+
+// Settings is stored as JSON
+type Settings struct {
+	Limit *int32
+	Label string
+}
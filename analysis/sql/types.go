@@ -66,7 +66,7 @@ func (b Builtin) Name() string { return b.name }
 // IsNullable returns `true` if the type comes
 // from a sql.NullXXX type.
 func (b Builtin) IsNullable() bool {
-	named, ok := b.t.Type().(*types.Named)
+	named, ok := types.Unalias(b.t.Type()).(*types.Named)
 	return ok && IsNullXXX(named) != nil
 }
 
@@ -156,6 +156,9 @@ func newType(ty an.Type) Type {
 	case *an.Map, *an.Union: // use the general JSON type
 		return JSON{t: ty}
 	case *an.Struct:
+		if ty.Anonymous != nil { // always stored as JSON
+			return JSON{t: ty}
+		}
 		// special case for NullXXX types
 		if elem := IsNullXXX(ty.Name); elem != nil {
 			if basic, isBasic := elem.Type().Underlying().(*types.Basic); isBasic {
@@ -173,8 +176,10 @@ func newType(ty an.Type) Type {
 			return Composite{t: ty}
 		}
 		return JSON{t: ty}
-	case *an.Pointer:
-		panic("Pointer types not supported in SQL generator")
+	case *an.External:
+		return Builtin{t: ty, name: ty.Mapping.SQLType()}
+	case *an.Pointer: // nullable, see [Column.IsNullable]
+		return newType(ty.Elem)
 	case *an.Named:
 		return newType(ty.Underlying)
 	default:
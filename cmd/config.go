@@ -0,0 +1,424 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"gopkg.in/yaml.v3"
+)
+
+// configuration file based API
+
+// Config maps a list of inputs to the actions to apply.
+// An input is a Go file, a file glob (like models/*.go) or a package pattern (like ./models/...),
+// whose files are analyzed together (see [analysis.ExpandSources]).
+type Config struct {
+	Inputs map[string]Actions
+
+	DartOutputDir string // directory of the files generated by the Dart actions
+	DartFormatter string // optional custom command used to format the Dart files
+
+	// Types should be registered before running the actions
+	Types analysis.ExternalTypes
+}
+
+// inputs returns the sorted config inputs
+func (conf Config) inputs() []string {
+	var inputs []string
+	for input := range conf.Inputs {
+		inputs = append(inputs, input)
+	}
+	sort.Strings(inputs) // ensure deterministic execution order
+	return inputs
+}
+
+// enableSets turns on the set generation for all the go/sqlcrud actions
+func (conf Config) enableSets() {
+	for _, actions := range conf.Inputs {
+		for i := range actions {
+			if actions[i].Mode == goSqlcrudGen {
+				actions[i].GenerateSets = true
+			}
+		}
+	}
+}
+
+// loadConfig reads [filename], which is either in the structured format
+// (see [configFile]), written in YAML or JSON, or in the legacy JSON format,
+// mapping each input to its actions.
+func loadConfig(filename string) (Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var probe map[string]any
+	if err := yaml.Unmarshal(data, &probe); err != nil {
+		return Config{}, fmt.Errorf("%s: %s", filename, err)
+	}
+	if _, isStructured := probe["inputs"]; !isStructured {
+		conf, err := newConfigFromJSON(data)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s: %s", filename, err)
+		}
+		return conf, nil
+	}
+
+	var file configFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return Config{}, fmt.Errorf("%s: %s", filename, err)
+	}
+	if err := file.validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config file %s:\n%s", filename, err)
+	}
+	return file.resolve(), nil
+}
+
+// legacy JSON format
+
+// externalTypesKey is the special config entry
+// used to map the types not supported by the analysis
+const externalTypesKey = "_types"
+
+// dartKey is the special config entry whose (first) output
+// is the directory of the Dart files
+const dartKey = "_dart"
+
+// newConfigFromJSON parses the legacy config format, mapping each input
+// to its actions, with the special [externalTypesKey] and [dartKey] entries
+func newConfigFromJSON(data []byte) (Config, error) {
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return Config{}, err
+	}
+
+	var conf Config
+	if raw, has := entries[externalTypesKey]; has {
+		var types map[string]typeMapping
+		if err := json.Unmarshal(raw, &types); err != nil {
+			return Config{}, fmt.Errorf("%s: %s", externalTypesKey, err)
+		}
+		for name, ty := range types {
+			if err := ty.check(); err != nil {
+				return Config{}, fmt.Errorf("%s: %s: %s", externalTypesKey, name, err)
+			}
+		}
+		conf.Types = resolveTypes(types)
+		delete(entries, externalTypesKey)
+	}
+
+	conf.Inputs = make(map[string]Actions, len(entries))
+	for file, raw := range entries {
+		var actions Actions
+		if err := json.Unmarshal(raw, &actions); err != nil {
+			return Config{}, fmt.Errorf("%s: %s", file, err)
+		}
+		if file == dartKey {
+			if len(actions) != 0 {
+				conf.DartOutputDir = actions[0].Output
+			}
+			continue
+		}
+		for _, act := range actions {
+			if err := checkMode(act.Mode); err != nil {
+				return Config{}, fmt.Errorf("%s: %s", file, err)
+			}
+			if err := checkFramework(act.Framework); err != nil {
+				return Config{}, fmt.Errorf("%s: %s", file, err)
+			}
+		}
+		conf.Inputs[file] = actions
+	}
+
+	return conf, nil
+}
+
+// structured format
+
+// configFile is the structured config format, as in
+//
+//	outputDir: web/src
+//	dartOutputDir: app/lib/models
+//	defaults:
+//	  framework: chi
+//	formatters:
+//	  typescript: npx prettier --write
+//	types:
+//	  github.com/google/uuid.UUID: { json: string, sql: uuid }
+//	inputs:
+//	  - source: server/models.go
+//	    actions:
+//	      - { mode: typescript/types, output: types.ts }
+//	      - { mode: go/sqlcrud, output: server/crud.go, generateSets: true }
+type configFile struct {
+	// OutputDir is the directory of the relative outputs,
+	// unless overridden by [inputConfig.OutputDir]
+	OutputDir     string `yaml:"outputDir"`
+	DartOutputDir string `yaml:"dartOutputDir"`
+
+	// Defaults apply to all the actions supporting them,
+	// when not set by the action
+	Defaults actionOptions `yaml:"defaults"`
+
+	// Formatters maps a language (go, typescript, dart or sql)
+	// to a custom format command
+	Formatters map[string]string `yaml:"formatters"`
+
+	// Types maps the fully qualified Go types to their representation,
+	// see [analysis.ExternalType]
+	Types map[string]typeMapping `yaml:"types"`
+
+	Inputs []inputConfig `yaml:"inputs"`
+}
+
+type inputConfig struct {
+	Source    string         `yaml:"source"`
+	OutputDir string         `yaml:"outputDir"`
+	Actions   []actionConfig `yaml:"actions"`
+}
+
+// actionOptions are the options which may be set globally,
+// pointers are used to detect unset values
+type actionOptions struct {
+	Framework    string `yaml:"framework"`
+	QueryPackage string `yaml:"queryPackage"`
+	UrlOnly      *bool  `yaml:"urlOnly"`
+	GenerateSets *bool  `yaml:"generateSets"`
+	Package      string `yaml:"package"`
+	Header       string `yaml:"header"`
+}
+
+type actionConfig struct {
+	Mode          mode   `yaml:"mode"`
+	Output        string `yaml:"output"`
+	actionOptions `yaml:",inline"`
+	Formatter     string `yaml:"formatter"`
+}
+
+// typeMapping uses the keys of the gomacro-type tag,
+// in both the structured and the legacy formats
+type typeMapping struct {
+	JSON         analysis.JSONKind `yaml:"json" json:"json"`
+	TypeScript   string            `yaml:"typescript" json:"typescript"`
+	Dart         string            `yaml:"dart" json:"dart"`
+	DartFromJSON string            `yaml:"dart-from-json" json:"dart-from-json"`
+	DartToJSON   string            `yaml:"dart-to-json" json:"dart-to-json"`
+	DartImport   string            `yaml:"dart-import" json:"dart-import"`
+	SQL          string            `yaml:"sql" json:"sql"`
+	Randdata     string            `yaml:"randdata" json:"randdata"`
+}
+
+// check returns an error if the JSON kind is not valid
+func (ty typeMapping) check() error {
+	switch ty.JSON {
+	case analysis.JSONAny, analysis.JSONString, analysis.JSONNumber, analysis.JSONBoolean:
+		return nil
+	default:
+		return fmt.Errorf("invalid json kind %q (expected string, number, boolean or empty)", ty.JSON)
+	}
+}
+
+// resolveTypes converts the (valid) mappings to the analysis format
+func resolveTypes(types map[string]typeMapping) analysis.ExternalTypes {
+	out := make(analysis.ExternalTypes, len(types))
+	for name, ty := range types {
+		out[name] = analysis.ExternalType(ty)
+	}
+	return out
+}
+
+var formatterLanguages = []string{"go", "typescript", "dart", "sql"}
+
+// check returns an error for the options not supported by [m]
+func (opts actionOptions) check(m mode) error {
+	var errs []error
+	unsupported := func(option string) {
+		errs = append(errs, fmt.Errorf("option %s is not supported by mode %s", option, m))
+	}
+	act := action{Mode: m}
+	if opts.Framework != "" {
+		if !act.usesRoutes() {
+			unsupported("framework")
+		} else if err := checkFramework(opts.Framework); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if opts.QueryPackage != "" && m != typescriptQueryGen {
+		unsupported("queryPackage")
+	}
+	if opts.UrlOnly != nil && m != typescriptApiGen {
+		unsupported("urlOnly")
+	}
+	if opts.GenerateSets != nil && m != goSqlcrudGen {
+		unsupported("generateSets")
+	}
+	if opts.Package != "" && m != goClientGen {
+		unsupported("package")
+	}
+	if opts.Header != "" && !m.supportsHeader() {
+		unsupported("header")
+	}
+	return errors.Join(errs...)
+}
+
+// supportsFormatter returns false for the Dart actions, whose files
+// are generated (and formatted) together, see [configFile.Formatters]
+func (m mode) supportsFormatter() bool { return m != dartGen }
+
+// supportsHeader returns false for the JSON and Dart outputs,
+// where a header can't be added
+func (m mode) supportsHeader() bool {
+	switch m.language() {
+	case "json", "dart":
+		return false
+	default:
+		return true
+	}
+}
+
+// validate returns all the errors found in the config,
+// prefixed by their location
+func (file configFile) validate() error {
+	var errs []error
+	errorf := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if file.Defaults.Framework != "" {
+		if err := checkFramework(file.Defaults.Framework); err != nil {
+			errorf("defaults: %s", err)
+		}
+	}
+	for language := range file.Formatters {
+		if !slices.Contains(formatterLanguages, language) {
+			errorf("formatters: invalid language %q (expected one of %v)", language, formatterLanguages)
+		}
+	}
+	for name, ty := range file.Types {
+		if err := ty.check(); err != nil {
+			errorf("types: %s: %s", name, err)
+		}
+	}
+
+	if len(file.Inputs) == 0 {
+		errorf("inputs: no input defined")
+	}
+	var (
+		sources = make(map[string]bool)
+		hasDart bool
+	)
+	for i, input := range file.Inputs {
+		loc := fmt.Sprintf("inputs[%d]", i)
+		if input.Source == "" {
+			errorf("%s: missing source", loc)
+		} else {
+			loc += " (" + input.Source + ")"
+			if sources[input.Source] {
+				errorf("%s: duplicated source", loc)
+			}
+			sources[input.Source] = true
+		}
+		if len(input.Actions) == 0 {
+			errorf("%s: no action defined", loc)
+		}
+
+		for j, act := range input.Actions {
+			actLoc := fmt.Sprintf("%s: actions[%d]", loc, j)
+			if err := checkMode(act.Mode); err != nil {
+				errorf("%s: invalid mode %q", actLoc, act.Mode)
+				continue
+			}
+			if act.Mode == dartGen {
+				hasDart = true
+			} else if act.Output == "" {
+				errorf("%s: missing output for mode %s", actLoc, act.Mode)
+			}
+			if err := act.actionOptions.check(act.Mode); err != nil {
+				errorf("%s: %s", actLoc, err)
+			}
+			if act.Formatter != "" && !act.Mode.supportsFormatter() {
+				errorf("%s: option formatter is not supported by mode %s (use formatters.dart instead)", actLoc, act.Mode)
+			}
+		}
+	}
+	if hasDart && file.DartOutputDir == "" {
+		errorf("dartOutputDir: required by the dart actions")
+	}
+
+	return errors.Join(errs...)
+}
+
+// resolve applies the defaults and the output directories.
+// It should only be called on a valid config.
+func (file configFile) resolve() Config {
+	conf := Config{
+		Inputs:        make(map[string]Actions, len(file.Inputs)),
+		DartOutputDir: file.DartOutputDir,
+		DartFormatter: file.Formatters["dart"],
+		Types:         resolveTypes(file.Types),
+	}
+
+	defaults := file.Defaults
+	for _, input := range file.Inputs {
+		outputDir := file.OutputDir
+		if input.OutputDir != "" {
+			outputDir = input.OutputDir
+		}
+
+		actions := make(Actions, len(input.Actions))
+		for i, ac := range input.Actions {
+			// only apply the defaults supported by the mode
+			opts := ac.actionOptions
+			if opts.Framework == "" && (action{Mode: ac.Mode}).usesRoutes() {
+				opts.Framework = defaults.Framework
+			}
+			if opts.QueryPackage == "" && ac.Mode == typescriptQueryGen {
+				opts.QueryPackage = defaults.QueryPackage
+			}
+			if opts.UrlOnly == nil && ac.Mode == typescriptApiGen {
+				opts.UrlOnly = defaults.UrlOnly
+			}
+			if opts.GenerateSets == nil && ac.Mode == goSqlcrudGen {
+				opts.GenerateSets = defaults.GenerateSets
+			}
+			if opts.Package == "" && ac.Mode == goClientGen {
+				opts.Package = defaults.Package
+			}
+			if opts.Header == "" && ac.Mode.supportsHeader() {
+				opts.Header = defaults.Header
+			}
+
+			output := ac.Output
+			if output != "" && !filepath.IsAbs(output) {
+				output = filepath.Join(outputDir, output)
+			}
+			formatter := ac.Formatter
+			if formatter == "" {
+				formatter = file.Formatters[ac.Mode.language()]
+			}
+
+			actions[i] = action{
+				Mode:         ac.Mode,
+				Output:       output,
+				UrlOnly:      opts.UrlOnly != nil && *opts.UrlOnly,
+				Framework:    opts.Framework,
+				QueryPackage: opts.QueryPackage,
+				GenerateSets: opts.GenerateSets != nil && *opts.GenerateSets,
+				Package:      opts.Package,
+				Header:       opts.Header,
+				Formatter:    formatter,
+			}
+		}
+		conf.Inputs[input.Source] = actions
+	}
+	return conf
+}
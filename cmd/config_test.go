@@ -0,0 +1,149 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	tu "github.com/benoitkugler/gomacro/testutils"
+)
+
+// writeConfig writes [content] in a temporary file, returning its path
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadConfigFiles(t *testing.T) {
+	for _, file := range []string{"test/test.json", "test/test.yaml"} {
+		conf, err := loadConfig(file)
+		tu.Assert(t, err == nil, err)
+		tu.Assert(t, conf.DartOutputDir == "test/")
+		tu.Assert(t, len(conf.Inputs) == 1)
+		actions := conf.Inputs["test/source.go"]
+		tu.Assert(t, len(actions) == 2 && actions.hasDart())
+		tu.Assert(t, actions[1].Mode == goUnionsGen && filepath.Clean(actions[1].Output) == "test/out.go", actions[1])
+	}
+}
+
+func TestLegacyTypes(t *testing.T) {
+	file := writeConfig(t, "config.json", `{
+		"_types": {
+			"time.Duration": { "json": "number", "dart": "Duration", "dart-from-json": "durationFromJson", "randdata": "randDuration" }
+		},
+		"models.go": [{ "Mode": "go/unions", "Output": "unions.go" }]
+	}`)
+	conf, err := loadConfig(file)
+	tu.Assert(t, err == nil, err)
+	tu.Assert(t, conf.Types["time.Duration"] == analysis.ExternalType{
+		JSON: analysis.JSONNumber, Dart: "Duration", DartFromJSON: "durationFromJson", Randdata: "randDuration",
+	}, conf.Types)
+	tu.Assert(t, len(conf.Inputs["models.go"]) == 1)
+
+	file = writeConfig(t, "config.json", `{
+		"_types": { "time.Duration": { "json": "object" } },
+		"models.go": [{ "Mode": "go/unions", "Output": "unions.go" }]
+	}`)
+	_, err = loadConfig(file)
+	tu.Assert(t, err != nil && strings.Contains(err.Error(), `_types: time.Duration: invalid json kind "object"`), err)
+}
+
+func TestResolveConfig(t *testing.T) {
+	file := writeConfig(t, "config.yaml", `
+outputDir: web
+dartOutputDir: app
+defaults:
+  framework: chi
+  header: "// license"
+  generateSets: true
+formatters:
+  typescript: prettier --write
+types:
+  github.com/google/uuid.UUID: { json: string, sql: uuid }
+inputs:
+  - source: server/routes.go
+    actions:
+      - { mode: typescript/fetch, output: api.ts }
+      - { mode: go/sqlcrud, output: /abs/crud.go, generateSets: false }
+      - { mode: go/client, output: client/client.go, package: client }
+      - { mode: dart }
+  - source: server/models.go
+    outputDir: other
+    actions:
+      - { mode: openapi, output: api.json, formatter: jq }
+`)
+	conf, err := loadConfig(file)
+	tu.Assert(t, err == nil, err)
+	tu.Assert(t, conf.DartOutputDir == "app" && conf.DartFormatter == "")
+	tu.Assert(t, conf.Types["github.com/google/uuid.UUID"] == analysis.ExternalType{JSON: analysis.JSONString, SQL: "uuid"})
+
+	routes := conf.Inputs["server/routes.go"]
+	tu.Assert(t, len(routes) == 4)
+	tu.Assert(t, routes[0] == action{
+		Mode: typescriptFetchGen, Output: "web/api.ts", Framework: chiFramework,
+		Header: "// license", Formatter: "prettier --write",
+	}, routes[0])
+	tu.Assert(t, routes[1] == action{Mode: goSqlcrudGen, Output: "/abs/crud.go", Header: "// license"}, routes[1])
+	tu.Assert(t, routes[2] == action{Mode: goClientGen, Output: "web/client/client.go", Framework: chiFramework, Package: "client", Header: "// license"}, routes[2])
+	tu.Assert(t, routes[3] == action{Mode: dartGen}, routes[3])
+
+	models := conf.Inputs["server/models.go"]
+	tu.Assert(t, len(models) == 1)
+	tu.Assert(t, models[0] == action{Mode: openapiGen, Output: "other/api.json", Framework: chiFramework, Formatter: "jq"}, models[0])
+}
+
+func TestValidateConfig(t *testing.T) {
+	for _, test := range []struct {
+		config   string
+		expected string
+	}{
+		{"defaults: { framework: rails }\ninputs: [{ source: a.go, actions: [{ mode: sql, output: a.sql }] }]", "defaults: invalid framework rails"},
+		{"formatters: { rust: rustfmt }\ninputs: [{ source: a.go, actions: [{ mode: sql, output: a.sql }] }]", `formatters: invalid language "rust"`},
+		{"types: { a.B: { json: object } }\ninputs: [{ source: a.go, actions: [{ mode: sql, output: a.sql }] }]", `types: a.B: invalid json kind "object"`},
+		{"inputs: []", "inputs: no input defined"},
+		{"inputs: [{ actions: [{ mode: sql, output: a.sql }] }]", "inputs[0]: missing source"},
+		{"inputs: [{ source: a.go, actions: [{ mode: sql, output: a.sql }] }, { source: a.go, actions: [{ mode: sql, output: b.sql }] }]", "inputs[1] (a.go): duplicated source"},
+		{"inputs: [{ source: a.go }]", "inputs[0] (a.go): no action defined"},
+		{"inputs: [{ source: a.go, actions: [{ mode: cobol, output: a.cob }] }]", `inputs[0] (a.go): actions[0]: invalid mode "cobol"`},
+		{"inputs: [{ source: a.go, actions: [{ mode: sql }] }]", "inputs[0] (a.go): actions[0]: missing output for mode sql"},
+		{"inputs: [{ source: a.go, actions: [{ mode: sql, output: a.sql, framework: chi }] }]", "actions[0]: option framework is not supported by mode sql"},
+		{"inputs: [{ source: a.go, actions: [{ mode: go/client, output: a.go, framework: rails }] }]", "actions[0]: invalid framework rails"},
+		{"inputs: [{ source: a.go, actions: [{ mode: sql, output: a.sql, queryPackage: q }] }]", "actions[0]: option queryPackage is not supported by mode sql"},
+		{"inputs: [{ source: a.go, actions: [{ mode: sql, output: a.sql, urlOnly: true }] }]", "actions[0]: option urlOnly is not supported by mode sql"},
+		{"inputs: [{ source: a.go, actions: [{ mode: sql, output: a.sql, generateSets: true }] }]", "actions[0]: option generateSets is not supported by mode sql"},
+		{"inputs: [{ source: a.go, actions: [{ mode: go/sqlcrud, output: a.go, package: p }] }]", "actions[0]: option package is not supported by mode go/sqlcrud"},
+		{"inputs: [{ source: a.go, actions: [{ mode: openapi, output: a.json, header: h }] }]", "actions[0]: option header is not supported by mode openapi"},
+		{"dartOutputDir: app\ninputs: [{ source: a.go, actions: [{ mode: dart, formatter: fmt }] }]", "actions[0]: option formatter is not supported by mode dart (use formatters.dart instead)"},
+		{"inputs: [{ source: a.go, actions: [{ mode: dart }] }]", "dartOutputDir: required by the dart actions"},
+		{"outputdir: web\ninputs: [{ source: a.go, actions: [{ mode: sql, output: a.sql }] }]", "field outputdir not found"},
+	} {
+		_, err := loadConfig(writeConfig(t, "config.yaml", test.config))
+		tu.Assert(t, err != nil && strings.Contains(err.Error(), test.expected), test.config, err)
+	}
+}
+
+func TestCustomize(t *testing.T) {
+	act := action{Mode: goClientGen, Header: "// license\n"}
+	tu.Assert(t, act.customize("package client\n") == "// license\npackage client\n")
+
+	act = action{Mode: openapiGen, Header: "// license"}
+	tu.Assert(t, act.customize("{}\n") == "{}\n")
+}
+
+func TestWithDartInputs(t *testing.T) {
+	conf := Config{Inputs: map[string]Actions{
+		"a.go": {{Mode: dartGen}},
+		"b.go": {{Mode: sqlGen, Output: "b.sql"}},
+		"c.go": {{Mode: dartGen}, {Mode: goUnionsGen, Output: "c_gen.go"}},
+	}}
+	tu.Assert(t, slices.Equal(conf.withDartInputs([]string{"b.go"}), []string{"b.go"}))
+	tu.Assert(t, slices.Equal(conf.withDartInputs([]string{"c.go"}), []string{"a.go", "c.go"}))
+	tu.Assert(t, slices.Equal(conf.withDartInputs([]string{"b.go", "c.go"}), []string{"a.go", "b.go", "c.go"}))
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
-	"encoding/json"
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
+	"go/token"
+	"go/types"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strings"
 	"sync"
 
@@ -15,9 +19,12 @@ import (
 	"github.com/benoitkugler/gomacro/analysis/httpapi"
 	"github.com/benoitkugler/gomacro/generator"
 	"github.com/benoitkugler/gomacro/generator/dart"
+	"github.com/benoitkugler/gomacro/generator/go/goclient"
 	"github.com/benoitkugler/gomacro/generator/go/gounions"
 	"github.com/benoitkugler/gomacro/generator/go/randdata"
 	"github.com/benoitkugler/gomacro/generator/go/sqlcrud"
+	"github.com/benoitkugler/gomacro/generator/jsonschema"
+	"github.com/benoitkugler/gomacro/generator/openapi"
 	"github.com/benoitkugler/gomacro/generator/sql"
 	"github.com/benoitkugler/gomacro/generator/typescript"
 	"golang.org/x/tools/go/packages"
@@ -31,18 +38,100 @@ const (
 	goUnionsGen        = "go/unions"
 	goSqlcrudGen       = "go/sqlcrud"
 	goRanddataGen      = "go/randdata"
+	goClientGen        = "go/client"
 	sqlGen             = "sql"
 	typescriptApiGen   = "typescript/api"
 	typescriptTypesGen = "typescript/types"
+	typescriptZodGen   = "typescript/zod"
+	typescriptFetchGen = "typescript/fetch"
+	typescriptQueryGen = "typescript/query"
+	typescriptMSWGen   = "typescript/msw"
 	dartGen            = "dart"
+	openapiGen         = "openapi"
+	jsonschemaGen      = "jsonschema"
+)
+
+// supported HTTP frameworks
+const (
+	echoFramework    = "echo"
+	netHTTPFramework = "net/http"
+	chiFramework     = "chi"
+	ginFramework     = "gin"
 )
 
 var fmts generator.Formatters
 
 type action struct {
-	Mode    mode
-	Output  string
-	UrlOnly bool // only for [typescriptApiGen]
+	Mode         mode
+	Output       string
+	UrlOnly      bool   // only for [typescriptApiGen]
+	Framework    string // only for the modes using HTTP routes; default to [echoFramework]
+	QueryPackage string // only for [typescriptQueryGen]; default to [typescript.DefaultQueryPackage]
+	GenerateSets bool   // only for [goSqlcrudGen]
+	Package      string // only for [goClientGen]; name of the generated package, if it differs from the source one
+	Header       string // text added at the begining of the output, like a license
+	Formatter    string // custom format command, to which the output file is appended
+}
+
+func checkFramework(framework string) error {
+	switch framework {
+	case "", echoFramework, netHTTPFramework, chiFramework, ginFramework:
+		return nil
+	default:
+		return fmt.Errorf("invalid framework %s (supported: %q, %q, %q, %q)", framework, echoFramework, netHTTPFramework, chiFramework, ginFramework)
+	}
+}
+
+// parseEndpoints scans the HTTP routes, using the framework
+// defined by [act]
+func (act action) parseEndpoints(pkg *packages.Package, fullPath string, diags *analysis.Diagnostics) []httpapi.Endpoint {
+	fmt.Println("Parsing http routes...")
+	var api []httpapi.Endpoint
+	switch act.Framework {
+	case "", echoFramework:
+		api = httpapi.ParseEcho(pkg, fullPath, diags)
+	case netHTTPFramework:
+		api = httpapi.ParseNetHTTP(pkg, fullPath, diags)
+	case chiFramework:
+		api = httpapi.ParseChi(pkg, fullPath, diags)
+	case ginFramework:
+		api = httpapi.ParseGin(pkg, fullPath, diags)
+	default:
+		panic("framework should be validated") // see checkFramework
+	}
+	fmt.Println("Done. Generating", len(api), "routes")
+	return api
+}
+
+func checkMode(m mode) error {
+	switch m {
+	case goUnionsGen, goSqlcrudGen, goRanddataGen, goClientGen,
+		sqlGen, typescriptApiGen, typescriptTypesGen, typescriptZodGen, typescriptFetchGen, typescriptQueryGen, typescriptMSWGen, dartGen, openapiGen, jsonschemaGen:
+		return nil
+	default:
+		const usage = `
+		Supported modes : 
+		"go/unions","go/sqlcrud","go/randdata","go/client","sql","typescript/api","typescript/types","typescript/zod","typescript/fetch","typescript/query","typescript/msw","dart","openapi","jsonschema"
+	`
+		return fmt.Errorf("invalid mode %s %s", m, usage)
+	}
+}
+
+// language returns the language of the outputs of [m],
+// used to select the formatter
+func (m mode) language() string {
+	switch m {
+	case goUnionsGen, goSqlcrudGen, goRanddataGen, goClientGen:
+		return "go"
+	case sqlGen:
+		return "sql"
+	case dartGen:
+		return "dart"
+	case openapiGen, jsonschemaGen:
+		return "json"
+	default:
+		return "typescript"
+	}
 }
 
 func newAction(value string) (action, error) {
@@ -51,15 +140,8 @@ func newAction(value string) (action, error) {
 		return action{}, fmt.Errorf("expected colon separated <mode>:<output>, got %s", value)
 	}
 	m := action{Mode: mode(md), Output: output}
-	switch m.Mode {
-	case goUnionsGen, goSqlcrudGen, goRanddataGen,
-		sqlGen, typescriptApiGen, typescriptTypesGen, dartGen:
-	default:
-		const usage = `
-		Supported modes : 
-		"go/unions","go/sqlcrud","go/randdata","sql","typescript/api","typescript/types","dart"
-	`
-		return action{}, fmt.Errorf("invalid mode %s %s", m.Mode, usage)
+	if err := checkMode(m.Mode); err != nil {
+		return action{}, err
 	}
 	if m.Output == "" {
 		return action{}, fmt.Errorf("output not specified for mode %s", m.Mode)
@@ -68,8 +150,45 @@ func newAction(value string) (action, error) {
 	return m, nil
 }
 
+// customize applies the [action.Header] option to [code]
+func (act action) customize(code string) string {
+	if act.Header != "" && act.Mode.supportsHeader() {
+		code = strings.TrimRight(act.Header, "\n") + "\n" + code
+	}
+	return code
+}
+
 type Actions []action
 
+// usesRoutes returns true for the modes scanning the HTTP routes
+func (act action) usesRoutes() bool {
+	switch act.Mode {
+	case goClientGen, typescriptApiGen, typescriptFetchGen, typescriptQueryGen, typescriptMSWGen, openapiGen:
+		return true
+	default:
+		return false
+	}
+}
+
+// isGo returns true for the modes generating code in the source package
+func (act action) isGo() bool {
+	switch act.Mode {
+	case goUnionsGen, goSqlcrudGen, goRanddataGen, goClientGen:
+		return true
+	default:
+		return false
+	}
+}
+
+func isSinglePackage(pkgs []*packages.Package) bool {
+	for _, pkg := range pkgs {
+		if pkg != pkgs[0] {
+			return false
+		}
+	}
+	return true
+}
+
 func (acs Actions) hasDart() bool {
 	for _, ac := range acs {
 		if ac.Mode == dartGen {
@@ -96,77 +215,77 @@ func (i *Actions) Set(value string) error {
 }
 
 type outputFile struct {
-	format  generator.Format
-	file    string
-	content string
+	format    generator.Format
+	formatter string // custom command, overriding [format]
+	file      string
+	content   string
+}
+
+// formatFile formats the output, once written
+func (out outputFile) formatFile() error {
+	if out.formatter != "" {
+		return generator.FormatFileWith(out.formatter, out.file)
+	}
+	return fmts.FormatFile(out.format, out.file)
 }
 
+// runActions merges the types defined in [files] (matched by [input]) in one analysis,
+// and runs the actions on it.
 // special case for dart actions, which are returned for latter processsing
-func runActions(source string, pkg *packages.Package, actions Actions, dartOnly, generateSets bool) (*analysis.Analysis, []outputFile, error) {
+// The problems found are added to [diags], and the actions reporting errors are skipped.
+func runActions(input string, files []string, pkgs []*packages.Package, actions Actions, dartOnly bool, diags *analysis.Diagnostics) (*analysis.Analysis, []outputFile, error) {
 	if dartOnly && !actions.hasDart() {
 		return nil, nil, nil
 	}
 
-	fullPath, err := filepath.Abs(source)
-	if err != nil {
-		return nil, nil, err
+	// the HTTP routes are scanned in one file
+	fullPath := ""
+	if len(files) == 1 {
+		var err error
+		fullPath, err = filepath.Abs(files[0])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, act := range actions {
+		if act.usesRoutes() && fullPath == "" {
+			return nil, nil, fmt.Errorf("%s: mode %s requires a single source file", input, act.Mode)
+		}
+		if act.isGo() && !isSinglePackage(pkgs) {
+			return nil, nil, fmt.Errorf("%s: mode %s requires source files from one package", input, act.Mode)
+		}
 	}
 
-	fmt.Printf("Analyzing types for %s...\n", source)
+	fmt.Printf("Analyzing types for %s...\n", input)
 
-	ana := analysis.NewAnalysisFromFile(pkg, source)
+	ana := analysis.NewAnalysisFromFiles(pkgs, files, diags)
 
 	fmt.Println("Code analysis completed. Running actions..")
 
 	hasDart := false
 	var outs []outputFile
 	for _, act := range actions {
-		var (
-			code   string
-			format generator.Format // format if true
-			output = act.Output
-		)
-
 		if dartOnly && act.Mode != dartGen {
 			continue
 		}
-
-		switch act.Mode {
-		case goUnionsGen:
-			code = generator.WriteDeclarations(gounions.Generate(ana))
-			format = generator.Go
-		case goSqlcrudGen:
-			code = generator.WriteDeclarations(sqlcrud.Generate(ana, generateSets))
-			format = generator.Go
-		case goRanddataGen:
-			code = generator.WriteDeclarations(randdata.Generate(ana))
-			format = generator.Go
-		case sqlGen:
-			code = generator.WriteDeclarations(sql.Generate(ana))
-			format = generator.Psql
-		case typescriptTypesGen:
-			code = generator.WriteDeclarations(typescript.Generate(ana))
-			format = generator.TypeScript
-		case typescriptApiGen:
-			fmt.Println("Parsing http routes...")
-			api := httpapi.ParseEcho(ana.Pkg, fullPath)
-			fmt.Println("Done. Generating", len(api), "routes")
-			if act.UrlOnly {
-				code = typescript.GenerateURLs(api)
-			} else {
-				code = typescript.GenerateAxios(api)
-			}
-			format = generator.TypeScript
-		case dartGen:
+		if act.Mode == dartGen {
 			hasDart = true
-			// code = generator.WriteDeclarations(dart.Generate(ana))
-			// format = generator.Dart
-		default:
-			panic(act.Mode)
+			continue
+		}
+
+		nbErrors := diags.ErrorCount()
+		var (
+			code   string
+			format generator.Format
+		)
+		diags.Try(token.NoPos, func() { code, format = act.generate(ana, fullPath, diags) })
+		if diags.ErrorCount() > nbErrors {
+			fmt.Printf("\tSkipping %s (%s): errors found.\n", act.Output, act.Mode)
+			continue
 		}
 
 		if code != "" {
-			outs = append(outs, outputFile{format: format, file: output, content: code})
+			outs = append(outs, outputFile{format: format, formatter: act.Formatter, file: act.Output, content: act.customize(code)})
 		}
 	}
 	if hasDart {
@@ -175,22 +294,113 @@ func runActions(source string, pkg *packages.Package, actions Actions, dartOnly,
 	return nil, outs, nil
 }
 
-func saveOutputs(commonDir, dartOutputDir string, dartAnalysis []*analysis.Analysis, outputs []outputFile) error {
-	for _, out := range dart.Generate(commonDir, dartAnalysis) {
+// generate returns the code for [act], which must not be a Dart action
+func (act action) generate(ana *analysis.Analysis, fullPath string, diags *analysis.Diagnostics) (code string, format generator.Format) {
+	switch act.Mode {
+	case goUnionsGen:
+		code = generator.WriteDeclarations(gounions.Generate(ana, diags))
+		format = generator.Go
+	case goSqlcrudGen:
+		code = generator.WriteDeclarations(sqlcrud.Generate(ana, act.GenerateSets, diags))
+		format = generator.Go
+	case goRanddataGen:
+		code = generator.WriteDeclarations(randdata.Generate(ana, diags))
+		format = generator.Go
+	case goClientGen:
+		api := act.parseEndpoints(ana.Pkg, fullPath, diags)
+		target := ana.Pkg.Types
+		if act.Package != "" && act.Package != target.Name() {
+			// the client lives in another package, and imports the source types
+			target = types.NewPackage(act.Package, act.Package)
+		}
+		code = generator.WriteDeclarations(goclient.Generate(api, target))
+		format = generator.Go
+	case sqlGen:
+		code = generator.WriteDeclarations(sql.Generate(ana, diags))
+		format = generator.Psql
+	case typescriptTypesGen:
+		code = generator.WriteDeclarations(typescript.Generate(ana, diags))
+		format = generator.TypeScript
+	case typescriptZodGen:
+		code = generator.WriteDeclarations(typescript.GenerateZod(ana, diags))
+		format = generator.TypeScript
+	case typescriptApiGen:
+		api := act.parseEndpoints(ana.Pkg, fullPath, diags)
+		if act.UrlOnly {
+			code = typescript.GenerateURLs(api)
+		} else {
+			code = typescript.GenerateAxios(api)
+		}
+		format = generator.TypeScript
+	case typescriptFetchGen:
+		api := act.parseEndpoints(ana.Pkg, fullPath, diags)
+		code = typescript.GenerateFetch(api, diags)
+		format = generator.TypeScript
+	case typescriptQueryGen:
+		api := act.parseEndpoints(ana.Pkg, fullPath, diags)
+		code = typescript.GenerateQuery(api, act.QueryPackage)
+		format = generator.TypeScript
+	case typescriptMSWGen:
+		api := act.parseEndpoints(ana.Pkg, fullPath, diags)
+		code = typescript.GenerateMSW(api, diags)
+		format = generator.TypeScript
+	case openapiGen:
+		api := act.parseEndpoints(ana.Pkg, fullPath, diags)
+		code = openapi.Generate(api, openapi.Info{Title: ana.Pkg.Name, Version: "1.0.0"})
+		format = generator.NoFormat
+	case jsonschemaGen:
+		code = jsonschema.Generate(ana, diags)
+		format = generator.NoFormat
+	default: // dart actions are handled by saveOutputs
+		panic(act.Mode)
+	}
+	return code, format
+}
+
+// outputMode controls how the outputs are saved
+type outputMode uint8
+
+const (
+	writeAll     outputMode = iota // write the outputs, and format them in place
+	writeChanged                   // only write the outputs whose (formatted) content changed
+	checkOnly                      // do not write anything, but compare with the existing files
+)
+
+// saveOutputs generates the Dart files, and writes and formats all the outputs,
+// according to [mode].
+func (conf Config) saveOutputs(commonDir string, dartAnalysis []*analysis.Analysis, outputs []outputFile, diags *analysis.Diagnostics, mode outputMode) error {
+	nbErrors := diags.ErrorCount()
+	dartOutputs := dart.Generate(commonDir, dartAnalysis, diags)
+	if diags.ErrorCount() > nbErrors {
+		fmt.Println("\tSkipping Dart outputs: errors found.")
+		dartOutputs = nil
+	}
+	for _, out := range dartOutputs {
 		outputs = append(outputs, outputFile{
-			format:  generator.Dart,
-			file:    filepath.Join(dartOutputDir, out.Filename),
-			content: generator.WriteDeclarations(out.Content),
+			format:    generator.Dart,
+			formatter: conf.DartFormatter,
+			file:      filepath.Join(conf.DartOutputDir, out.Filename),
+			content:   generator.WriteDeclarations(out.Content),
 		})
 	}
 
+	switch mode {
+	case checkOnly:
+		return checkOutputs(outputs)
+	case writeChanged:
+		return writeChangedOutputs(outputs)
+	}
+
 	fmt.Println("Code generated. Saving and formatting...")
 
-	var wg sync.WaitGroup
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		formatErrs []error
+	)
 	wg.Add(len(outputs))
 	for _, out := range outputs {
 		output := out.file
-		format := out.format
 		err := os.WriteFile(output, []byte(out.content), os.ModePerm)
 		if err != nil {
 			return err
@@ -199,84 +409,219 @@ func saveOutputs(commonDir, dartOutputDir string, dartAnalysis []*analysis.Analy
 		fmt.Printf("\tCode written to %s (pending formatting).\n", output)
 
 		go func() {
-			err = fmts.FormatFile(format, output)
-			if err != nil {
-				panic(fmt.Sprintf("formatting %s failed: generated code is probably incorrect: %s", output, err))
+			defer wg.Done()
+			if err := out.formatFile(); err != nil {
+				mu.Lock()
+				formatErrs = append(formatErrs, formatError(output, err))
+				mu.Unlock()
 			}
-			wg.Done()
 		}()
 	}
 	fmt.Println("Waiting for formatters...")
 	wg.Wait()
+	return errors.Join(formatErrs...)
+}
+
+// checkOutputs formats the outputs in memory and compares them to the existing files,
+// printing a unified diff for each file which is not up to date.
+func checkOutputs(outputs []outputFile) error {
+	fmt.Println("Code generated. Formatting and comparing...")
+
+	var (
+		wg    sync.WaitGroup
+		diffs = make([][]byte, len(outputs))
+		errs  = make([]error, len(outputs))
+	)
+	wg.Add(len(outputs))
+	for i, out := range outputs {
+		go func() {
+			defer wg.Done()
+			diffs[i], errs[i] = out.diff()
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	nbStale := 0
+	for _, diff := range diffs {
+		if diff != nil {
+			os.Stdout.Write(diff)
+			nbStale++
+		}
+	}
+	if nbStale != 0 {
+		return fmt.Errorf("%d generated file(s) not up to date", nbStale)
+	}
+	fmt.Println("Generated files are up to date.")
 	return nil
 }
 
-// configuration file based API
+// formatError adds a hint to the errors returned by the formatters,
+// which include the offending code when available (see [generator.FormatError])
+func formatError(file string, err error) error {
+	if fe := (*generator.FormatError)(nil); errors.As(err, &fe) {
+		return fmt.Errorf("generated code is probably incorrect: %w", err)
+	}
+	return fmt.Errorf("formatting %s failed: generated code is probably incorrect: %s", file, err)
+}
 
-// Config maps a list of files to the actions to apply
-type Config map[string]Actions
+// formatted returns the formatted content, and the content of the existing file,
+// which is empty if the file does not exist.
+func (out outputFile) formatted() (formatted, current []byte, err error) {
+	if out.formatter != "" {
+		formatted, err = generator.FormatContentWith(out.formatter, out.file, []byte(out.content))
+	} else {
+		formatted, err = fmts.FormatContent(out.format, out.file, []byte(out.content))
+	}
+	if err != nil {
+		return nil, nil, formatError(out.file, err)
+	}
+	current, err = os.ReadFile(out.file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, err
+	}
+	return formatted, current, nil
+}
 
-func newConfigFromJSON(configFile string) (Config, error) {
-	f, err := os.Open(configFile)
+// diff returns the differences between the existing file
+// and the formatted content, or nil if they are equal
+func (out outputFile) diff() ([]byte, error) {
+	formatted, current, err := out.formatted()
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
+	return generator.UnifiedDiff(out.file, out.file+" (generated)", current, formatted), nil
+}
 
-	var conf Config
-	if err = json.NewDecoder(f).Decode(&conf); err != nil {
-		return nil, err
+// writeChangedOutputs formats the outputs in memory, and only writes
+// the files whose content changed, so that the tools watching them
+// are not triggered needlessly.
+func writeChangedOutputs(outputs []outputFile) error {
+	fmt.Println("Code generated. Formatting and saving the changed files...")
+
+	var (
+		wg      sync.WaitGroup
+		errs    = make([]error, len(outputs))
+		changed = make([]bool, len(outputs))
+	)
+	wg.Add(len(outputs))
+	for i, out := range outputs {
+		go func() {
+			defer wg.Done()
+			formatted, current, err := out.formatted()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if changed[i] = !bytes.Equal(formatted, current); changed[i] {
+				errs[i] = os.WriteFile(out.file, formatted, os.ModePerm)
+			}
+		}()
 	}
+	wg.Wait()
 
-	return conf, nil
+	for i, out := range outputs {
+		if changed[i] && errs[i] == nil {
+			fmt.Printf("\tCode written to %s.\n", out.file)
+		}
+	}
+	return errors.Join(errs...)
 }
 
-func (conf Config) run(dartOnly, generateSets bool) error {
-	dartOutputDir := ""
-	if dart, ok := conf["_dart"]; ok {
-		dartOutputDir = dart[0].Output
-		delete(conf, "_dart")
-	}
+// runResult is the result of [Config.runInputs]
+type runResult struct {
+	commonDir string
+	diags     *analysis.Diagnostics // nil if the packages could not be loaded
+	outputs   []outputFile
+	dartAnas  map[string]*analysis.Analysis  // for the inputs with Dart actions
+	pkgs      map[string][]*packages.Package // for each input, the packages containing its files
+}
 
-	// fetch the packages for each file in one call
-	var files []string
-	for file := range conf {
-		files = append(files, file)
+// runInputs loads the packages for [inputs] in one call, and runs their actions.
+func (conf Config) runInputs(inputs []string, dartOnly bool) (runResult, error) {
+	// expand the packages and globs, and fetch the packages for all the files in one call
+	var (
+		files      []string
+		inputFiles = make([][]string, len(inputs))
+	)
+	for i, input := range inputs {
+		matches, err := analysis.ExpandSources(input)
+		if err != nil {
+			return runResult{}, fmt.Errorf("%s: %s", input, err)
+		}
+		inputFiles[i] = matches
+		files = append(files, matches...)
 	}
-	sort.Strings(files) // ensure deterministic execution order
 
 	fmt.Println("Type-checking source files...")
 	pkgs, commonDir, err := analysis.LoadSources(files)
 	if err != nil {
-		return err
+		return runResult{}, err
 	}
 	fmt.Println("Source loading done. Root directory:", commonDir)
 
-	var (
-		allOutputs []outputFile
-		dartAnas   []*analysis.Analysis
-	)
-	for i, file := range files {
-		actions := conf[file]
-		pkg := pkgs[i]
-		dartAna, outs, err := runActions(file, pkg, actions, dartOnly, generateSets)
+	out := runResult{
+		commonDir: commonDir,
+		diags:     analysis.NewDiagnostics(pkgs[0].Fset), // the packages are loaded at once, sharing the file set
+		dartAnas:  make(map[string]*analysis.Analysis),
+		pkgs:      make(map[string][]*packages.Package),
+	}
+	for i, input := range inputs {
+		inputPkgs := pkgs[:len(inputFiles[i])]
+		pkgs = pkgs[len(inputFiles[i]):]
+		out.pkgs[input] = inputPkgs
+		dartAna, outs, err := runActions(input, inputFiles[i], inputPkgs, conf.Inputs[input], dartOnly, out.diags)
 		if err != nil {
-			return err
+			return out, err
 		}
-		allOutputs = append(allOutputs, outs...)
+		out.outputs = append(out.outputs, outs...)
 		if dartAna != nil {
-			dartAnas = append(dartAnas, dartAna)
+			out.dartAnas[input] = dartAna
 		}
 	}
+	return out, nil
+}
 
-	return saveOutputs(commonDir, dartOutputDir, dartAnas, allOutputs)
+// sortedAnalysis returns the values of [m], sorted by input
+func sortedAnalysis(m map[string]*analysis.Analysis) []*analysis.Analysis {
+	var out []*analysis.Analysis
+	for _, input := range slices.Sorted(maps.Keys(m)) {
+		out = append(out, m[input])
+	}
+	return out
+}
+
+// run executes the actions, returning the problems found in the source files,
+// which do not prevent the other outputs from being generated.
+func (conf Config) run(dartOnly bool, mode outputMode) (*analysis.Diagnostics, error) {
+	res, err := conf.runInputs(conf.inputs(), dartOnly)
+	if err != nil {
+		return res.diags, err
+	}
+	return res.diags, conf.saveOutputs(res.commonDir, sortedAnalysis(res.dartAnas), res.outputs, res.diags, mode)
+}
+
+// printDiagnostics prints the problems found, in file:line:col format
+func printDiagnostics(diags *analysis.Diagnostics) {
+	for _, d := range diags.List() {
+		fmt.Fprintln(os.Stderr, d)
+	}
+	if n := diags.ErrorCount(); n > 0 {
+		fmt.Fprintf(os.Stderr, "%d error(s) found.\n", n)
+	}
 }
 
 func main() {
-	isConfig := flag.Bool("config", false, "Use a config file")
+	isConfig := flag.Bool("config", false, "Use a config file (JSON or YAML)")
 	isDartOnly := flag.Bool("dart-only", false, "Only run Dart actions")
 	generateSetsID := flag.Bool("generate-sets", false, "Generate a convenient Set type")
 	httpURLOnly := flag.Bool("url-only", false, "Generates URL instead of Axios calls")
+	framework := flag.String("framework", echoFramework, "HTTP framework used to scan the routes (echo, net/http, chi or gin)")
+	queryPackage := flag.String("query-package", typescript.DefaultQueryPackage, "Package providing the TanStack Query hooks (typescript/query mode)")
+	check := flag.Bool("check", false, "Do not write the outputs, but check that the existing files are up to date")
+	watch := flag.Bool("watch", false, "Watch the source files, and regenerate the outputs when they change")
 	flag.Parse()
 
 	fileArgs := flag.Args()
@@ -289,26 +634,54 @@ func main() {
 		err  error
 	)
 	if *isConfig { // config mode
-		configFile := fileArgs[0]
-		conf, err = newConfigFromJSON(configFile)
+		conf, err = loadConfig(fileArgs[0])
 		if err != nil {
 			log.Fatal(err)
 		}
+		for name, ext := range conf.Types {
+			analysis.RegisterExternalType(name, ext)
+		}
 	} else { // single file mode
+		if err := checkFramework(*framework); err != nil {
+			log.Fatal(err)
+		}
 		inputFile := fileArgs[0]
-		conf = make(Config)
+		conf.Inputs = make(map[string]Actions)
 		for _, actionString := range fileArgs[1:] {
 			action, err := newAction(actionString)
 			if err != nil {
 				log.Fatal(err)
 			}
 			action.UrlOnly = *httpURLOnly
-			conf[inputFile] = append(conf[inputFile], action)
+			action.Framework = *framework
+			action.QueryPackage = *queryPackage
+			conf.Inputs[inputFile] = append(conf.Inputs[inputFile], action)
 		}
 	}
 
-	if err := conf.run(*isDartOnly, *generateSetsID); err != nil {
+	if *generateSetsID {
+		conf.enableSets()
+	}
+
+	if *watch {
+		if *check {
+			log.Fatal("-check and -watch can't be used together")
+		}
+		conf.watch(*isDartOnly)
+		return
+	}
+
+	mode := writeAll
+	if *check {
+		mode = checkOnly
+	}
+	diags, err := conf.run(*isDartOnly, mode)
+	printDiagnostics(diags)
+	if err != nil {
 		log.Fatal(err)
 	}
+	if diags.ErrorCount() > 0 {
+		os.Exit(1)
+	}
 	fmt.Println("Done.")
 }
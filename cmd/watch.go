@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// watch mode : the source files are polled, and only the inputs
+// whose files (or in-module dependencies) changed are reloaded
+
+// watchInterval is the delay between two checks of the watched files
+const watchInterval = 500 * time.Millisecond
+
+type watcher struct {
+	conf     Config
+	dartOnly bool
+
+	commonDir string // of the first successful run, used for the Dart outputs
+
+	watched  map[string][]string  // for each input, the watched files and directories
+	modTimes map[string]time.Time // last modification time of the watched paths
+}
+
+// watch runs all the actions, and then re-runs the actions of the inputs
+// whose source files changed, until the program is stopped.
+func (conf Config) watch(dartOnly bool) {
+	inputs := conf.inputs()
+	w := watcher{
+		conf: conf, dartOnly: dartOnly,
+		watched:  make(map[string][]string),
+		modTimes: make(map[string]time.Time),
+	}
+	for {
+		w.update(inputs)
+		inputs = w.waitForChanges()
+	}
+}
+
+// update runs the actions of [inputs], writing the changed outputs,
+// and updates the watched files
+func (w *watcher) update(inputs []string) {
+	inputs = w.conf.withDartInputs(inputs)
+	fmt.Printf("Running the actions for %v...\n", inputs)
+	res, err := w.conf.runInputs(inputs, w.dartOnly)
+	if err == nil {
+		if w.commonDir == "" {
+			w.commonDir = res.commonDir
+		}
+		err = w.conf.saveOutputs(w.commonDir, sortedAnalysis(res.dartAnas), res.outputs, res.diags, writeChanged)
+	}
+
+	printDiagnostics(res.diags)
+	if err != nil {
+		log.Println(err)
+	}
+
+	for _, input := range inputs {
+		if pkgs, ok := res.pkgs[input]; ok {
+			w.watched[input] = watchedPaths(pkgs)
+		} else if _, ok := w.watched[input]; !ok { // the packages could not be loaded
+			w.watched[input], _ = analysis.ExpandSources(input)
+		}
+	}
+	// also absorb the changes made by the run (output files)
+	for _, paths := range w.watched {
+		for _, path := range paths {
+			w.modTimes[path] = modTime(path)
+		}
+	}
+	fmt.Printf("Watching %d files for changes...\n", len(w.modTimes))
+}
+
+// withDartInputs returns [inputs], completed by all the inputs with
+// Dart actions if one of [inputs] has some : the Dart files are shared
+// between the inputs, and must be generated from analyses loaded together,
+// since the analyses of separate loads do not share their file set.
+func (conf Config) withDartInputs(inputs []string) []string {
+	if !slices.ContainsFunc(inputs, func(input string) bool { return conf.Inputs[input].hasDart() }) {
+		return inputs
+	}
+	out := slices.Clone(inputs)
+	for _, input := range conf.inputs() {
+		if conf.Inputs[input].hasDart() && !slices.Contains(out, input) {
+			out = append(out, input)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// waitForChanges blocks until a watched path is modified, and returns
+// the inputs depending on it
+func (w *watcher) waitForChanges() []string {
+	for {
+		time.Sleep(watchInterval)
+
+		var changed []string
+		for path, last := range w.modTimes {
+			if current := modTime(path); !current.Equal(last) {
+				changed = append(changed, path)
+			}
+		}
+		if len(changed) == 0 {
+			continue
+		}
+
+		var inputs []string
+		for input, paths := range w.watched {
+			for _, path := range changed {
+				if slices.Contains(paths, path) {
+					inputs = append(inputs, input)
+					break
+				}
+			}
+		}
+		slices.Sort(inputs)
+		return inputs
+	}
+}
+
+// modTime returns the modification time of [path],
+// or the zero time if it does not exist (anymore)
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// watchedPaths returns the Go files of [pkgs] and their in-module dependencies
+// (the ones traversed by [analysis.PkgSelector]), and the directories of [pkgs],
+// so that new files are detected.
+func watchedPaths(pkgs []*packages.Package) []string {
+	var (
+		out  []string
+		seen = make(map[*packages.Package]bool)
+	)
+	var visit func(pkg *packages.Package, selector analysis.PkgSelector)
+	visit = func(pkg *packages.Package, selector analysis.PkgSelector) {
+		if seen[pkg] {
+			return
+		}
+		seen[pkg] = true
+		out = append(out, pkg.GoFiles...)
+		for _, imp := range pkg.Imports {
+			if !selector.Ignore(imp) {
+				visit(imp, selector)
+			}
+		}
+	}
+	for _, pkg := range pkgs {
+		if len(pkg.GoFiles) != 0 && !slices.Contains(out, filepath.Dir(pkg.GoFiles[0])) {
+			out = append(out, filepath.Dir(pkg.GoFiles[0]))
+		}
+		visit(pkg, analysis.NewPkgSelector(pkg))
+	}
+	return out
+}
@@ -2,6 +2,7 @@ package dart
 
 import (
 	"fmt"
+	"go/token"
 	"sort"
 	"strings"
 
@@ -19,7 +20,8 @@ type Output struct {
 // also adding JSON convertion functions.
 // [sourceDirectory] is common root directory shared by all [sources]
 // One [Output] is generated by file to write.
-func Generate(sourceDirectory string, sources []*an.Analysis) []Output {
+// The types which are not supported are reported to [diags].
+func Generate(sourceDirectory string, sources []*an.Analysis, diags *an.Diagnostics) []Output {
 	if len(sources) == 0 {
 		return nil
 	}
@@ -29,9 +31,9 @@ func Generate(sourceDirectory string, sources []*an.Analysis) []Output {
 	// create one list of declaration per output file
 	buf := newBuffer(lk)
 
-	for _, an := range sources {
-		for _, typ := range an.Source {
-			buf.generate(an.Types[typ], buf.linker.GetOutput(typ))
+	for _, ana := range sources {
+		for _, typ := range ana.Source {
+			diags.Try(an.DeclarationPos(typ), func() { buf.generate(ana.Types[typ], buf.linker.GetOutput(typ)) })
 		}
 	}
 
@@ -122,10 +124,14 @@ func newBuffer(lk an.Linker) buffer {
 // in the proper declaration list, and returns the name of the file updated
 func (buf buffer) generate(typ an.Type, parentOutputFile string) string {
 	outfile := buf.linker.GetOutput(typ.Type())
-	switch typ.(type) {
-	case *an.Map, *an.Array:
+	switch typ := typ.(type) {
+	case *an.Map, *an.Array, *an.Pointer, *an.External, *an.TypeParam:
 		// use the parentOutputFile
 		outfile = parentOutputFile
+	case *an.Struct:
+		if typ.Anonymous != nil { // defined next to its parent
+			outfile = parentOutputFile
+		}
 	}
 
 	if buf.cache.Check(typ) { // handle recursive types
@@ -133,19 +139,23 @@ func (buf buffer) generate(typ an.Type, parentOutputFile string) string {
 	}
 	file := buf.files[outfile]
 	if file == nil {
-		panic(fmt.Sprintf("missing output file '%s' (parent :'%s') for type %T (%s)", outfile, parentOutputFile, typ, typ.Type()))
+		panic(an.NewError(token.Position{}, an.CodeUnsupportedType, "missing output file '%s' (parent: '%s') for type %s", outfile, parentOutputFile, typ.Type()))
 	}
 
 	switch typ := typ.(type) {
 	case *an.Pointer:
-		panic("pointers not handled by the Dart generator")
+		decl, importElem := buf.codeForPointer(typ, outfile)
+		file.add(decl, importElem)
 	case *an.Named:
-		decl, importS := buf.codeForNamed(typ)
-		file.add(decl, importS)
+		decl, imports := buf.codeForNamed(typ)
+		file.add(decl, imports...)
 	case *an.Basic:
 		file.add(codeForBasic(typ))
 	case *an.Time:
 		file.add(codeForTime(typ))
+	case *an.External:
+		decl, imports := codeForExternal(typ)
+		file.add(decl, imports...)
 	case *an.Array:
 		decl, importElem := buf.codeForArray(typ, outfile)
 		file.add(decl, importElem)
@@ -153,13 +163,15 @@ func (buf buffer) generate(typ an.Type, parentOutputFile string) string {
 		decl, importKey, importElem := buf.codeForMap(typ, outfile)
 		file.add(decl, importKey, importElem)
 	case *an.Struct:
-		decl, imports := buf.codeForStruct(typ)
+		decl, imports := buf.codeForStruct(typ, outfile)
 		file.add(decl, imports...)
 	case *an.Enum:
 		file.add(codeForEnum(typ))
 	case *an.Union:
 		decls, imports := buf.codeForUnion(typ)
 		file.add(decls, imports...)
+	case *an.TypeParam:
+		// nothing to declare
 	default:
 		panic(an.ExhaustiveTypeSwitch)
 	}
@@ -1,11 +1,14 @@
 package dart
 
 import (
+	"go/types"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/benoitkugler/gomacro/analysis"
 	"github.com/benoitkugler/gomacro/generator"
+	"github.com/benoitkugler/gomacro/testutils"
 )
 
 func TestGenerate(t *testing.T) {
@@ -15,9 +18,9 @@ func TestGenerate(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	an := analysis.NewAnalysisFromFile(pkg, source)
+	an := analysis.NewAnalysisFromFile(pkg, source, nil)
 
-	decls := Generate("go/src/github.com/benoitkugler/gomacro/testutils/testsource", []*analysis.Analysis{an})
+	decls := Generate("go/src/github.com/benoitkugler/gomacro/testutils/testsource", []*analysis.Analysis{an}, nil)
 	for _, file := range decls {
 		out := generator.WriteDeclarations(file.Content)
 
@@ -33,3 +36,156 @@ func TestGenerate(t *testing.T) {
 		}
 	}
 }
+
+func TestJSONOptions(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithJSONOptions")
+	st := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil).Types[typ].(*analysis.Struct)
+
+	code := jsonForStruct(st)
+	for _, expected := range []string{
+		"json['name'] == null ? null : stringFromJson(json['name'])",
+		"intFromJson(jsonDecode(json['Count'] as String))",
+		`if (item.name != null) "name" : stringToJson(item.name!)`,
+		`"Count" : jsonEncode(intToJson(item.count))`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestPointers(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithPointers")
+	an := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	var code string
+	for _, file := range Generate("go/src/github.com/benoitkugler/gomacro/testutils/testsource", []*analysis.Analysis{an}, nil) {
+		code += generator.WriteDeclarations(file.Content)
+	}
+	for _, expected := range []string{
+		"final String? label;",
+		"final DateTime? deadline;",
+		"final List<EnumInt?> values;",
+		"final String? opt;",
+		"String? nullableStringFromJson(dynamic json) => json == null ? null : stringFromJson(json);",
+		"dynamic nullableCompToJson(Comp? item) => item == null ? null : compToJson(item);",
+		"listNullableEnumIntFromJson(json['Values'])",
+		`if (item.opt != null) "opt" : nullableStringToJson(item.opt!)`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestExternals(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithExternals")
+	an := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	files := Generate("go/src/github.com/benoitkugler/gomacro/testutils/testsource", []*analysis.Analysis{an}, nil)
+	testutils.Assert(t, len(files) == 2) // predefined and testsource
+	var code string
+	for _, file := range files {
+		code += generator.WriteDeclarations(file.Content)
+	}
+	for _, expected := range []string{
+		"import 'package:app/duration.dart';",
+		"final dynamic raw;",
+		"final String addr;",
+		"final num? amount;",
+		"final List<String> addrs;",
+		"final Duration timeout;",
+		"String netipAddrFromJson(dynamic json) => json as String;",
+		"dynamic netipAddrToJson(String item) => item;",
+		"Duration timeDurationFromJson(dynamic json) => durationFromJson(json);",
+		"dynamic timeDurationToJson(Duration item) => durationToJson(item);",
+		"listNetipAddrFromJson(json['Addrs'])",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestGenerics(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithGenerics")
+	an := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	files := Generate("go/src/github.com/benoitkugler/gomacro/testutils/testsource", []*analysis.Analysis{an}, nil)
+	var code string
+	for _, file := range files {
+		code += generator.WriteDeclarations(file.Content)
+	}
+	for _, expected := range []string{
+		"class Page<T>  {",
+		"final List<T> items;",
+		"final T? next;",
+		"const Page(this.items, this.next, this.total);",
+		"class Pair<K, V>  {",
+		"typedef Batch<T> = List<T>;",
+		"final Page<Comp> comps;",
+		"final Pair<String, EnumInt> pair;",
+		"final Page<Batch<Comp>> nested;",
+		"Page<Comp> pageCompFromJson(dynamic json_) {",
+		"return Page<Comp>(",
+		"Map<String, dynamic> pageCompToJson(Page<Comp> item) {",
+		"Batch<String> batchStringFromJson(dynamic json) { return listStringFromJson(json); }",
+		"pageBatchCompFromJson(json['Nested'])",
+		"listBatchCompFromJson(json['Items'])",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+	// no JSON functions for the generic declarations
+	for _, unexpected := range []string{"pageFromJson", "listTFromJson", "batchFromJson"} {
+		if strings.Contains(code, unexpected) {
+			t.Fatal(unexpected)
+		}
+	}
+}
+
+func TestAnonymousStructs(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithAnonymous")
+	an := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	files := Generate("go/src/github.com/benoitkugler/gomacro/testutils/testsource", []*analysis.Analysis{an}, nil)
+	var code string
+	for _, file := range files {
+		code += generator.WriteDeclarations(file.Content)
+	}
+	for _, expected := range []string{
+		"final WithAnonymousMeta meta;",
+		"final List<WithAnonymousItems> items;",
+		"final WithAnonymousPair? pair;",
+		"class WithAnonymousItemsInner  {",
+		"typedef Rows = List<RowsElem>;",
+		"WithAnonymousMeta withAnonymousMetaFromJson(dynamic json_) {",
+		"listStringFromJson(json['tags'])",
+		"withAnonymousItemsInnerToJson(item.inner)",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package dart
 
 import (
 	"fmt"
+	"go/types"
 	"strings"
 
 	an "github.com/benoitkugler/gomacro/analysis"
@@ -16,22 +17,29 @@ import (
 func jsonID(typ an.Type) string {
 	switch typ := typ.(type) {
 	case *an.Pointer:
-		panic("pointers not handled by the Dart generator")
+		return "nullable" + strings.Title(jsonID(typ.Elem))
 	case *an.Named: // directly call the underlying function
 		switch typ.Underlying.(type) {
-		case *an.Array, *an.Map:
-			return lowerFirst(typeName(typ))
+		case *an.Array, *an.Map: // instantiated generics use their monomorphized name
+			return lowerFirst(an.MonomorphizedName(typ.Type()))
 		default:
 			return jsonID(typ.Underlying)
 		}
 	case *an.Basic, *an.Time:
 		return lowerFirst(typeName(typ))
+	case *an.External: // use the Go name, since the Dart type may be shared
+		obj := typ.Type().(interface{ Obj() *types.TypeName }).Obj() // may be an alias
+		return obj.Pkg().Name() + obj.Name()
 	case *an.Array:
 		return "list" + strings.Title(jsonID(typ.Elem))
 	case *an.Map:
 		return "dict" + strings.Title(jsonID(typ.Key)) + "To" + strings.Title(jsonID(typ.Elem))
-	case *an.Struct, *an.Enum, *an.Union: // these types are always named
+	case *an.Struct: // instantiated generics use their monomorphized name
+		return lowerFirst(an.MonomorphizedName(typ.Name))
+	case *an.Enum, *an.Union: // these types are always named
 		return lowerFirst(typeName(typ))
+	case *an.TypeParam:
+		return lowerFirst(typ.Name())
 	default:
 		panic(an.ExhaustiveTypeSwitch)
 	}
@@ -70,6 +78,22 @@ func jsonForTime() string {
 	`
 }
 
+func jsonForExternal(ext *an.External) string {
+	name, id := typeName(ext), jsonID(ext)
+	from, to := fmt.Sprintf("json as %s", name), "item"
+	if fn := ext.Mapping.DartFromJSON; fn != "" {
+		from = fn + "(json)"
+	}
+	if fn := ext.Mapping.DartToJSON; fn != "" {
+		to = fn + "(item)"
+	}
+	return fmt.Sprintf(`// %s
+	%s %sFromJson(dynamic json) => %s;
+
+	dynamic %sToJson(%s item) => %s;
+	`, ext.Type(), name, id, from, id, name, to)
+}
+
 func jsonForEnum(en *an.Enum) string {
 	valueType := "String"
 	if en.IsInteger() {
@@ -85,6 +109,9 @@ func jsonForEnum(en *an.Enum) string {
 }
 
 func jsonForNamed(na *an.Named) string {
+	if len(na.TypeParams) != 0 { // generated for each instantiation
+		return ""
+	}
 	switch na.Underlying.(type) {
 	case *an.Array, *an.Map:
 	default:
@@ -117,6 +144,16 @@ func jsonForArray(l *an.Array) string {
 	`, name, id, elemID, id, name, elemID)
 }
 
+func jsonForPointer(p *an.Pointer) string {
+	name, id := typeName(p), jsonID(p)
+	elemID := jsonID(p.Elem)
+
+	return fmt.Sprintf(`%s %sFromJson(dynamic json) => json == null ? null : %sFromJson(json);
+
+	dynamic %sToJson(%s item) => item == null ? null : %sToJson(item);
+	`, name, id, elemID, id, name, elemID)
+}
+
 func jsonForMap(ma *an.Map) string {
 	keyName, keyID := typeName(ma.Key), jsonID(ma.Key)
 
@@ -159,8 +196,22 @@ func jsonForStruct(st *an.Struct) string {
 			fieldsFrom = append(fieldsFrom, fmt.Sprintf("json['%s']", fieldName))
 			fieldsTo = append(fieldsTo, fmt.Sprintf("%q :  item.%s", fieldName, dartFieldName))
 		} else {
-			fieldsFrom = append(fieldsFrom, fmt.Sprintf("%sFromJson(json['%s'])", fieldTypeID, fieldName))
-			fieldsTo = append(fieldsTo, fmt.Sprintf("%q : %sToJson(item.%s)", fieldName, fieldTypeID, dartFieldName))
+			value, item := fmt.Sprintf("json['%s']", fieldName), "item."+dartFieldName
+			if field.OmitEmpty() {
+				item += "!"
+			}
+			from, to := fmt.Sprintf("%sFromJson(%s)", fieldTypeID, value), fmt.Sprintf("%sToJson(%s)", fieldTypeID, item)
+			if field.AsString() { // the value is encoded in a JSON string
+				from = fmt.Sprintf("%sFromJson(jsonDecode(%s as String))", fieldTypeID, value)
+				to = fmt.Sprintf("jsonEncode(%s)", to)
+			}
+			if field.OmitEmpty() { // the key may be absent
+				fieldsFrom = append(fieldsFrom, fmt.Sprintf("%s == null ? null : %s", value, from))
+				fieldsTo = append(fieldsTo, fmt.Sprintf("if (item.%s != null) %q : %s", dartFieldName, fieldName, to))
+			} else {
+				fieldsFrom = append(fieldsFrom, from)
+				fieldsTo = append(fieldsTo, fmt.Sprintf("%q : %s", fieldName, to))
+			}
 		}
 	}
 
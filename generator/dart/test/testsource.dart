@@ -1,5 +1,7 @@
 // Code generated by gomacro/generator/dart. DO NOT EDIT
 
+import 'dart:convert';
+import 'package:app/duration.dart';
 import 'predefined.dart';
 import 'testsource_subpackage.dart';
 
@@ -15,6 +17,49 @@ typedef Basic3 = double;
 // github.com/benoitkugler/gomacro/testutils/testsource.Basic4
 typedef Basic4 = String;
 
+// github.com/benoitkugler/gomacro/testutils/testsource.Batch[T any]
+typedef Batch<T> = List<T>;
+
+// github.com/benoitkugler/gomacro/testutils/testsource.Batch[github.com/benoitkugler/gomacro/testutils/testsource.Comp]
+Batch<Comp> batchCompFromJson(dynamic json) {
+  return listCompFromJson(json);
+}
+
+dynamic batchCompToJson(Batch<Comp> item) {
+  return listCompToJson(item);
+}
+
+// github.com/benoitkugler/gomacro/testutils/testsource.Batch[string]
+Batch<String> batchStringFromJson(dynamic json) {
+  return listStringFromJson(json);
+}
+
+dynamic batchStringToJson(Batch<String> item) {
+  return listStringToJson(item);
+}
+
+// github.com/benoitkugler/gomacro/testutils/testsource.Comp
+class Comp {
+  final int a;
+  final int b;
+
+  const Comp(this.a, this.b);
+
+  @override
+  String toString() {
+    return "Comp($a, $b)";
+  }
+}
+
+Comp compFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return Comp(intFromJson(json['A']), intFromJson(json['B']));
+}
+
+Map<String, dynamic> compToJson(Comp item) {
+  return {"A": intToJson(item.a), "B": intToJson(item.b)};
+}
+
 // github.com/benoitkugler/gomacro/testutils/testsource.ComplexStruct
 class ComplexStruct {
   final Map<int, int> with_tag;
@@ -29,8 +74,8 @@ class ComplexStruct {
   final List<List<bool>> f;
   final StructWithComment imported;
   final Map<EnumInt, bool> enumMap;
-  final Generic optID1;
-  final Generic optID2;
+  final Generic<IdCamp> optID1;
+  final Generic<IdFile> optID2;
 
   const ComplexStruct(
     this.with_tag,
@@ -70,8 +115,8 @@ ComplexStruct complexStructFromJson(dynamic json_) {
     listListBoolFromJson(json['F']),
     structWithCommentFromJson(json['Imported']),
     dictEnumIntToBoolFromJson(json['EnumMap']),
-    genericFromJson(json['OptID1']),
-    genericFromJson(json['OptID2']),
+    genericIdCampFromJson(json['OptID1']),
+    genericIdFileFromJson(json['OptID2']),
   );
 }
 
@@ -89,8 +134,8 @@ Map<String, dynamic> complexStructToJson(ComplexStruct item) {
     "F": listListBoolToJson(item.f),
     "Imported": structWithCommentToJson(item.imported),
     "EnumMap": dictEnumIntToBoolToJson(item.enumMap),
-    "OptID1": genericToJson(item.optID1),
-    "OptID2": genericToJson(item.optID2),
+    "OptID1": genericIdCampToJson(item.optID1),
+    "OptID2": genericIdFileToJson(item.optID2),
   };
 }
 
@@ -198,9 +243,9 @@ EnumUInt enumUIntFromJson(dynamic json) => _EnumUIntExt.fromValue(json as int);
 
 dynamic enumUIntToJson(EnumUInt item) => item.toValue();
 
-// github.com/benoitkugler/gomacro/testutils/testsource.Generic[github.com/benoitkugler/gomacro/testutils/testsource.IdFile]
-class Generic {
-  final IdFile id;
+// github.com/benoitkugler/gomacro/testutils/testsource.Generic[T ~int64]
+class Generic<T> {
+  final T id;
 
   const Generic(this.id);
 
@@ -210,12 +255,23 @@ class Generic {
   }
 }
 
-Generic genericFromJson(dynamic json_) {
+// github.com/benoitkugler/gomacro/testutils/testsource.Generic[github.com/benoitkugler/gomacro/testutils/testsource.IdCamp]
+Generic<IdCamp> genericIdCampFromJson(dynamic json_) {
   final json = (json_ as Map<String, dynamic>);
-  return Generic(intFromJson(json['Id']));
+  return Generic<IdCamp>(intFromJson(json['Id']));
 }
 
-Map<String, dynamic> genericToJson(Generic item) {
+Map<String, dynamic> genericIdCampToJson(Generic<IdCamp> item) {
+  return {"Id": intToJson(item.id)};
+}
+
+// github.com/benoitkugler/gomacro/testutils/testsource.Generic[github.com/benoitkugler/gomacro/testutils/testsource.IdFile]
+Generic<IdFile> genericIdFileFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return Generic<IdFile>(intFromJson(json['Id']));
+}
+
+Map<String, dynamic> genericIdFileToJson(Generic<IdFile> item) {
   return {"Id": intToJson(item.id)};
 }
 
@@ -289,6 +345,100 @@ Map<String, dynamic> itfType2ToJson(ItfType2 item) {
 // github.com/benoitkugler/gomacro/testutils/testsource.MyDate
 typedef MyDate = DateTime;
 
+// github.com/benoitkugler/gomacro/testutils/testsource.Page[T any]
+class Page<T> {
+  final List<T> items;
+  final T? next;
+  final int total;
+
+  const Page(this.items, this.next, this.total);
+
+  @override
+  String toString() {
+    return "Page($items, $next, $total)";
+  }
+}
+
+// github.com/benoitkugler/gomacro/testutils/testsource.Page[github.com/benoitkugler/gomacro/testutils/testsource.Batch[github.com/benoitkugler/gomacro/testutils/testsource.Comp]]
+Page<Batch<Comp>> pageBatchCompFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return Page<Batch<Comp>>(
+    listBatchCompFromJson(json['Items']),
+    nullableBatchCompFromJson(json['Next']),
+    intFromJson(json['Total']),
+  );
+}
+
+Map<String, dynamic> pageBatchCompToJson(Page<Batch<Comp>> item) {
+  return {
+    "Items": listBatchCompToJson(item.items),
+    "Next": nullableBatchCompToJson(item.next),
+    "Total": intToJson(item.total),
+  };
+}
+
+// github.com/benoitkugler/gomacro/testutils/testsource.Page[github.com/benoitkugler/gomacro/testutils/testsource.Comp]
+Page<Comp> pageCompFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return Page<Comp>(
+    listCompFromJson(json['Items']),
+    nullableCompFromJson(json['Next']),
+    intFromJson(json['Total']),
+  );
+}
+
+Map<String, dynamic> pageCompToJson(Page<Comp> item) {
+  return {
+    "Items": listCompToJson(item.items),
+    "Next": nullableCompToJson(item.next),
+    "Total": intToJson(item.total),
+  };
+}
+
+// github.com/benoitkugler/gomacro/testutils/testsource.Page[int]
+Page<int> pageIntFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return Page<int>(
+    listIntFromJson(json['Items']),
+    nullableIntFromJson(json['Next']),
+    intFromJson(json['Total']),
+  );
+}
+
+Map<String, dynamic> pageIntToJson(Page<int> item) {
+  return {
+    "Items": listIntToJson(item.items),
+    "Next": nullableIntToJson(item.next),
+    "Total": intToJson(item.total),
+  };
+}
+
+// github.com/benoitkugler/gomacro/testutils/testsource.Pair[K comparable, V any]
+class Pair<K, V> {
+  final K key;
+  final V value;
+
+  const Pair(this.key, this.value);
+
+  @override
+  String toString() {
+    return "Pair($key, $value)";
+  }
+}
+
+// github.com/benoitkugler/gomacro/testutils/testsource.Pair[string, github.com/benoitkugler/gomacro/testutils/testsource.EnumInt]
+Pair<String, EnumInt> pairStringEnumIntFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return Pair<String, EnumInt>(
+    stringFromJson(json['Key']),
+    enumIntFromJson(json['Value']),
+  );
+}
+
+Map<String, dynamic> pairStringEnumIntToJson(Pair<String, EnumInt> item) {
+  return {"Key": stringToJson(item.key), "Value": enumIntToJson(item.value)};
+}
+
 // github.com/benoitkugler/gomacro/testutils/testsource.RecursiveType
 class RecursiveType {
   final List<RecursiveType> children;
@@ -310,6 +460,38 @@ Map<String, dynamic> recursiveTypeToJson(RecursiveType item) {
   return {"Children": listRecursiveTypeToJson(item.children)};
 }
 
+// github.com/benoitkugler/gomacro/testutils/testsource.Rows
+typedef Rows = List<RowsElem>;
+
+Rows rowsFromJson(dynamic json) {
+  return listRowsElemFromJson(json);
+}
+
+dynamic rowsToJson(Rows item) {
+  return listRowsElemToJson(item);
+}
+
+// struct{X int}
+class RowsElem {
+  final int x;
+
+  const RowsElem(this.x);
+
+  @override
+  String toString() {
+    return "RowsElem($x)";
+  }
+}
+
+RowsElem rowsElemFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return RowsElem(intFromJson(json['X']));
+}
+
+Map<String, dynamic> rowsElemToJson(RowsElem item) {
+  return {"X": intToJson(item.x)};
+}
+
 // github.com/benoitkugler/gomacro/testutils/testsource.StructWithExternalRef
 class StructWithExternalRef {
   final NamedSlice field1;
@@ -341,6 +523,275 @@ Map<String, dynamic> structWithExternalRefToJson(StructWithExternalRef item) {
   };
 }
 
+// github.com/benoitkugler/gomacro/testutils/testsource.WithAnonymous
+class WithAnonymous {
+  final WithAnonymousMeta meta;
+  final List<WithAnonymousItems> items;
+  final WithAnonymousPair? pair;
+  final Rows rows;
+
+  const WithAnonymous(this.meta, this.items, this.pair, this.rows);
+
+  @override
+  String toString() {
+    return "WithAnonymous($meta, $items, $pair, $rows)";
+  }
+}
+
+WithAnonymous withAnonymousFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return WithAnonymous(
+    withAnonymousMetaFromJson(json['meta']),
+    listWithAnonymousItemsFromJson(json['Items']),
+    nullableWithAnonymousPairFromJson(json['Pair']),
+    rowsFromJson(json['Rows']),
+  );
+}
+
+Map<String, dynamic> withAnonymousToJson(WithAnonymous item) {
+  return {
+    "meta": withAnonymousMetaToJson(item.meta),
+    "Items": listWithAnonymousItemsToJson(item.items),
+    "Pair": nullableWithAnonymousPairToJson(item.pair),
+    "Rows": rowsToJson(item.rows),
+  };
+}
+
+// struct{Id int64; Label string; Inner struct{Ok bool}}
+class WithAnonymousItems {
+  final int id;
+  final String label;
+  final WithAnonymousItemsInner inner;
+
+  const WithAnonymousItems(this.id, this.label, this.inner);
+
+  @override
+  String toString() {
+    return "WithAnonymousItems($id, $label, $inner)";
+  }
+}
+
+WithAnonymousItems withAnonymousItemsFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return WithAnonymousItems(
+    intFromJson(json['Id']),
+    stringFromJson(json['Label']),
+    withAnonymousItemsInnerFromJson(json['Inner']),
+  );
+}
+
+Map<String, dynamic> withAnonymousItemsToJson(WithAnonymousItems item) {
+  return {
+    "Id": intToJson(item.id),
+    "Label": stringToJson(item.label),
+    "Inner": withAnonymousItemsInnerToJson(item.inner),
+  };
+}
+
+// struct{Ok bool}
+class WithAnonymousItemsInner {
+  final bool ok;
+
+  const WithAnonymousItemsInner(this.ok);
+
+  @override
+  String toString() {
+    return "WithAnonymousItemsInner($ok)";
+  }
+}
+
+WithAnonymousItemsInner withAnonymousItemsInnerFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return WithAnonymousItemsInner(boolFromJson(json['Ok']));
+}
+
+Map<String, dynamic> withAnonymousItemsInnerToJson(
+  WithAnonymousItemsInner item,
+) {
+  return {"Ok": boolToJson(item.ok)};
+}
+
+// struct{Count int; Tags []string "json:\"tags\""}
+class WithAnonymousMeta {
+  final int count;
+  final List<String> tags;
+
+  const WithAnonymousMeta(this.count, this.tags);
+
+  @override
+  String toString() {
+    return "WithAnonymousMeta($count, $tags)";
+  }
+}
+
+WithAnonymousMeta withAnonymousMetaFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return WithAnonymousMeta(
+    intFromJson(json['Count']),
+    listStringFromJson(json['tags']),
+  );
+}
+
+Map<String, dynamic> withAnonymousMetaToJson(WithAnonymousMeta item) {
+  return {"Count": intToJson(item.count), "tags": listStringToJson(item.tags)};
+}
+
+// struct{A string; B string}
+class WithAnonymousPair {
+  final String a;
+  final String b;
+
+  const WithAnonymousPair(this.a, this.b);
+
+  @override
+  String toString() {
+    return "WithAnonymousPair($a, $b)";
+  }
+}
+
+WithAnonymousPair withAnonymousPairFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return WithAnonymousPair(
+    stringFromJson(json['A']),
+    stringFromJson(json['B']),
+  );
+}
+
+Map<String, dynamic> withAnonymousPairToJson(WithAnonymousPair item) {
+  return {"A": stringToJson(item.a), "B": stringToJson(item.b)};
+}
+
+// github.com/benoitkugler/gomacro/testutils/testsource.WithExternals
+class WithExternals {
+  final dynamic raw;
+  final String addr;
+  final num? amount;
+  final List<String> addrs;
+  final Duration timeout;
+
+  const WithExternals(
+    this.raw,
+    this.addr,
+    this.amount,
+    this.addrs,
+    this.timeout,
+  );
+
+  @override
+  String toString() {
+    return "WithExternals($raw, $addr, $amount, $addrs, $timeout)";
+  }
+}
+
+WithExternals withExternalsFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return WithExternals(
+    jsonRawMessageFromJson(json['Raw']),
+    netipAddrFromJson(json['Addr']),
+    nullableBigIntFromJson(json['Amount']),
+    listNetipAddrFromJson(json['Addrs']),
+    timeDurationFromJson(json['Timeout']),
+  );
+}
+
+Map<String, dynamic> withExternalsToJson(WithExternals item) {
+  return {
+    "Raw": jsonRawMessageToJson(item.raw),
+    "Addr": netipAddrToJson(item.addr),
+    "Amount": nullableBigIntToJson(item.amount),
+    "Addrs": listNetipAddrToJson(item.addrs),
+    "Timeout": timeDurationToJson(item.timeout),
+  };
+}
+
+// github.com/benoitkugler/gomacro/testutils/testsource.WithGenerics
+class WithGenerics {
+  final Page<Comp> comps;
+  final Page<int> ints;
+  final Pair<String, EnumInt> pair;
+  final Batch<String> names;
+  final Page<Batch<Comp>> nested;
+
+  const WithGenerics(this.comps, this.ints, this.pair, this.names, this.nested);
+
+  @override
+  String toString() {
+    return "WithGenerics($comps, $ints, $pair, $names, $nested)";
+  }
+}
+
+WithGenerics withGenericsFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return WithGenerics(
+    pageCompFromJson(json['Comps']),
+    pageIntFromJson(json['Ints']),
+    pairStringEnumIntFromJson(json['Pair']),
+    batchStringFromJson(json['Names']),
+    pageBatchCompFromJson(json['Nested']),
+  );
+}
+
+Map<String, dynamic> withGenericsToJson(WithGenerics item) {
+  return {
+    "Comps": pageCompToJson(item.comps),
+    "Ints": pageIntToJson(item.ints),
+    "Pair": pairStringEnumIntToJson(item.pair),
+    "Names": batchStringToJson(item.names),
+    "Nested": pageBatchCompToJson(item.nested),
+  };
+}
+
+// github.com/benoitkugler/gomacro/testutils/testsource.WithJSONOptions
+class WithJSONOptions {
+  final String? name;
+  final int count;
+  final List<String>? tags;
+  final Comp inner;
+  final int a;
+  final int b;
+  final Comp comp;
+
+  const WithJSONOptions(
+    this.name,
+    this.count,
+    this.tags,
+    this.inner,
+    this.a,
+    this.b,
+    this.comp,
+  );
+
+  @override
+  String toString() {
+    return "WithJSONOptions($name, $count, $tags, $inner, $a, $b, $comp)";
+  }
+}
+
+WithJSONOptions withJSONOptionsFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return WithJSONOptions(
+    json['name'] == null ? null : stringFromJson(json['name']),
+    intFromJson(jsonDecode(json['Count'] as String)),
+    json['tags'] == null ? null : listStringFromJson(json['tags']),
+    compFromJson(json['inner']),
+    intFromJson(json['A']),
+    intFromJson(json['B']),
+    compFromJson(json['comp']),
+  );
+}
+
+Map<String, dynamic> withJSONOptionsToJson(WithJSONOptions item) {
+  return {
+    if (item.name != null) "name": stringToJson(item.name!),
+    "Count": jsonEncode(intToJson(item.count)),
+    if (item.tags != null) "tags": listStringToJson(item.tags!),
+    "inner": compToJson(item.inner),
+    "A": intToJson(item.a),
+    "B": intToJson(item.b),
+    "comp": compToJson(item.comp),
+  };
+}
+
 // github.com/benoitkugler/gomacro/testutils/testsource.WithOpaque
 class WithOpaque {
   final dynamic f1;
@@ -372,6 +823,58 @@ Map<String, dynamic> withOpaqueToJson(WithOpaque item) {
   };
 }
 
+// github.com/benoitkugler/gomacro/testutils/testsource.WithPointers
+class WithPointers {
+  final String? label;
+  final int? count;
+  final DateTime? deadline;
+  final Comp? inner;
+  final List<EnumInt?> values;
+  final String? opt;
+
+  const WithPointers(
+    this.label,
+    this.count,
+    this.deadline,
+    this.inner,
+    this.values,
+    this.opt,
+  );
+
+  @override
+  String toString() {
+    return "WithPointers($label, $count, $deadline, $inner, $values, $opt)";
+  }
+}
+
+WithPointers withPointersFromJson(dynamic json_) {
+  final json = (json_ as Map<String, dynamic>);
+  return WithPointers(
+    nullableStringFromJson(json['Label']),
+    nullableIntFromJson(json['Count']),
+    nullableDateTimeFromJson(json['Deadline']),
+    nullableCompFromJson(json['Inner']),
+    listNullableEnumIntFromJson(json['Values']),
+    json['opt'] == null ? null : nullableStringFromJson(json['opt']),
+  );
+}
+
+Map<String, dynamic> withPointersToJson(WithPointers item) {
+  return {
+    "Label": nullableStringToJson(item.label),
+    "Count": nullableIntToJson(item.count),
+    "Deadline": nullableDateTimeToJson(item.deadline),
+    "Inner": nullableCompToJson(item.inner),
+    "Values": listNullableEnumIntToJson(item.values),
+    if (item.opt != null) "opt": nullableStringToJson(item.opt!),
+  };
+}
+
+// math/big.Int
+num bigIntFromJson(dynamic json) => json as num;
+
+dynamic bigIntToJson(num item) => item;
+
 Map<EnumInt, bool> dictEnumIntToBoolFromJson(dynamic json) {
   if (json == null) {
     return {};
@@ -400,6 +903,22 @@ Map<String, dynamic> dictIntToIntToJson(Map<int, int> item) {
   return item.map((k, v) => MapEntry(intToJson(k).toString(), intToJson(v)));
 }
 
+// encoding/json.RawMessage
+dynamic jsonRawMessageFromJson(dynamic json) => json as dynamic;
+
+dynamic jsonRawMessageToJson(dynamic item) => item;
+
+List<Batch<Comp>> listBatchCompFromJson(dynamic json) {
+  if (json == null) {
+    return [];
+  }
+  return (json as List<dynamic>).map(batchCompFromJson).toList();
+}
+
+List<dynamic> listBatchCompToJson(List<Batch<Comp>> item) {
+  return item.map(batchCompToJson).toList();
+}
+
 List<bool> listBoolFromJson(dynamic json) {
   if (json == null) {
     return [];
@@ -411,6 +930,17 @@ List<dynamic> listBoolToJson(List<bool> item) {
   return item.map(boolToJson).toList();
 }
 
+List<Comp> listCompFromJson(dynamic json) {
+  if (json == null) {
+    return [];
+  }
+  return (json as List<dynamic>).map(compFromJson).toList();
+}
+
+List<dynamic> listCompToJson(List<Comp> item) {
+  return item.map(compToJson).toList();
+}
+
 List<int> listIntFromJson(dynamic json) {
   if (json == null) {
     return [];
@@ -444,6 +974,28 @@ List<dynamic> listListBoolToJson(List<List<bool>> item) {
   return item.map(listBoolToJson).toList();
 }
 
+List<String> listNetipAddrFromJson(dynamic json) {
+  if (json == null) {
+    return [];
+  }
+  return (json as List<dynamic>).map(netipAddrFromJson).toList();
+}
+
+List<dynamic> listNetipAddrToJson(List<String> item) {
+  return item.map(netipAddrToJson).toList();
+}
+
+List<EnumInt?> listNullableEnumIntFromJson(dynamic json) {
+  if (json == null) {
+    return [];
+  }
+  return (json as List<dynamic>).map(nullableEnumIntFromJson).toList();
+}
+
+List<dynamic> listNullableEnumIntToJson(List<EnumInt?> item) {
+  return item.map(nullableEnumIntToJson).toList();
+}
+
 List<RecursiveType> listRecursiveTypeFromJson(dynamic json) {
   if (json == null) {
     return [];
@@ -454,3 +1006,93 @@ List<RecursiveType> listRecursiveTypeFromJson(dynamic json) {
 List<dynamic> listRecursiveTypeToJson(List<RecursiveType> item) {
   return item.map(recursiveTypeToJson).toList();
 }
+
+List<RowsElem> listRowsElemFromJson(dynamic json) {
+  if (json == null) {
+    return [];
+  }
+  return (json as List<dynamic>).map(rowsElemFromJson).toList();
+}
+
+List<dynamic> listRowsElemToJson(List<RowsElem> item) {
+  return item.map(rowsElemToJson).toList();
+}
+
+List<String> listStringFromJson(dynamic json) {
+  if (json == null) {
+    return [];
+  }
+  return (json as List<dynamic>).map(stringFromJson).toList();
+}
+
+List<dynamic> listStringToJson(List<String> item) {
+  return item.map(stringToJson).toList();
+}
+
+List<WithAnonymousItems> listWithAnonymousItemsFromJson(dynamic json) {
+  if (json == null) {
+    return [];
+  }
+  return (json as List<dynamic>).map(withAnonymousItemsFromJson).toList();
+}
+
+List<dynamic> listWithAnonymousItemsToJson(List<WithAnonymousItems> item) {
+  return item.map(withAnonymousItemsToJson).toList();
+}
+
+// net/netip.Addr
+String netipAddrFromJson(dynamic json) => json as String;
+
+dynamic netipAddrToJson(String item) => item;
+
+Batch<Comp>? nullableBatchCompFromJson(dynamic json) =>
+    json == null ? null : batchCompFromJson(json);
+
+dynamic nullableBatchCompToJson(Batch<Comp>? item) =>
+    item == null ? null : batchCompToJson(item);
+
+num? nullableBigIntFromJson(dynamic json) =>
+    json == null ? null : bigIntFromJson(json);
+
+dynamic nullableBigIntToJson(num? item) =>
+    item == null ? null : bigIntToJson(item);
+
+Comp? nullableCompFromJson(dynamic json) =>
+    json == null ? null : compFromJson(json);
+
+dynamic nullableCompToJson(Comp? item) =>
+    item == null ? null : compToJson(item);
+
+DateTime? nullableDateTimeFromJson(dynamic json) =>
+    json == null ? null : dateTimeFromJson(json);
+
+dynamic nullableDateTimeToJson(DateTime? item) =>
+    item == null ? null : dateTimeToJson(item);
+
+EnumInt? nullableEnumIntFromJson(dynamic json) =>
+    json == null ? null : enumIntFromJson(json);
+
+dynamic nullableEnumIntToJson(EnumInt? item) =>
+    item == null ? null : enumIntToJson(item);
+
+int? nullableIntFromJson(dynamic json) =>
+    json == null ? null : intFromJson(json);
+
+dynamic nullableIntToJson(int? item) => item == null ? null : intToJson(item);
+
+String? nullableStringFromJson(dynamic json) =>
+    json == null ? null : stringFromJson(json);
+
+dynamic nullableStringToJson(String? item) =>
+    item == null ? null : stringToJson(item);
+
+WithAnonymousPair? nullableWithAnonymousPairFromJson(dynamic json) =>
+    json == null ? null : withAnonymousPairFromJson(json);
+
+dynamic nullableWithAnonymousPairToJson(WithAnonymousPair? item) =>
+    item == null ? null : withAnonymousPairToJson(item);
+
+// time.Duration
+Duration timeDurationFromJson(dynamic json) => durationFromJson(json);
+
+dynamic timeDurationToJson(Duration item) => durationToJson(item);
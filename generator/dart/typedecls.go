@@ -4,6 +4,7 @@ package dart
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	an "github.com/benoitkugler/gomacro/analysis"
@@ -19,8 +20,8 @@ func lowerFirst(s string) string {
 // (not to be confused with the type declaration)
 func typeName(typ an.Type) string {
 	switch typ := typ.(type) {
-	case *an.Pointer:
-		panic("pointers not handled by the Dart generator")
+	case *an.Pointer: // nil pointers are jsonized as null
+		return typeName(typ.Elem) + "?"
 	case *an.Basic: // may be named or not
 		switch typ.Kind() {
 		case an.BKBool:
@@ -36,33 +37,114 @@ func typeName(typ an.Type) string {
 		}
 	case *an.Time:
 		return "DateTime"
+	case *an.External:
+		return typ.Mapping.DartType()
 	case *an.Array:
 		return fmt.Sprintf("List<%s>", typeName(typ.Elem))
 	case *an.Map:
 		return fmt.Sprintf("Map<%s,%s>", typeName(typ.Key), typeName(typ.Elem))
-	case *an.Named, *an.Struct, *an.Enum, *an.Union: // these types are always named
+	case *an.Named:
+		return genericName(typ, typ.TypeArgs)
+	case *an.Struct:
+		return genericName(typ, typ.TypeArgs)
+	case *an.Enum, *an.Union: // these types are always named
 		return strings.Title(an.LocalName(typ)) // Dart convention
+	case *an.TypeParam:
+		return typ.Name()
 	default:
 		panic(an.ExhaustiveTypeSwitch + fmt.Sprintf(": %T", typ))
 	}
 }
 
-func (buf buffer) codeForNamed(typ *an.Named) (gen.Declaration, string) {
+// genericName returns the name of [typ], followed by its type arguments, if any,
+// like Page<User>
+func genericName(typ an.Type, typeArgs []an.Type) string {
+	name := strings.Title(an.LocalName(typ)) // Dart convention
+	if len(typeArgs) == 0 {
+		return name
+	}
+	args := make([]string, len(typeArgs))
+	for i, arg := range typeArgs {
+		args[i] = typeName(arg)
+	}
+	return fmt.Sprintf("%s<%s>", name, strings.Join(args, ", "))
+}
+
+// declName returns the name used in the declaration of [typ],
+// followed by its type parameters, if any, like Page<T>
+func declName(typ an.Type, typeParams []*an.TypeParam) string {
+	name := typeName(typ)
+	if len(typeParams) == 0 {
+		return name
+	}
+	params := make([]string, len(typeParams))
+	for i, param := range typeParams {
+		params[i] = param.Name()
+	}
+	return fmt.Sprintf("%s<%s>", name, strings.Join(params, ", "))
+}
+
+// hasTypeParam returns true if [typ] refers to a type parameter,
+// meaning it is only valid inside a generic declaration,
+// where no JSON functions are generated.
+func hasTypeParam(typ an.Type) bool {
+	switch typ := typ.(type) {
+	case *an.TypeParam:
+		return true
+	case *an.Pointer:
+		return hasTypeParam(typ.Elem)
+	case *an.Array:
+		return hasTypeParam(typ.Elem)
+	case *an.Map:
+		return hasTypeParam(typ.Key) || hasTypeParam(typ.Elem)
+	case *an.Named:
+		return slices.ContainsFunc(typ.TypeArgs, hasTypeParam)
+	case *an.Struct:
+		return slices.ContainsFunc(typ.TypeArgs, hasTypeParam)
+	default:
+		return false
+	}
+}
+
+// codeForInstance returns the JSON functions for the instantiated generic type [typ],
+// whose declaration is shared with the other instantiations
+func (buf buffer) codeForInstance(typ an.Type, origin an.Type, json string, deps []an.Type) (gen.Declaration, []string) {
+	outfile := buf.linker.GetOutput(typ.Type())
+	imports := []string{buf.generate(origin, outfile)}
+	for _, dep := range deps { // recurse
+		imports = append(imports, buf.generate(dep, outfile))
+	}
+	code := fmt.Sprintf(`
+	// %s
+	%s
+	`, gen.Origin(typ), json)
+	return gen.Declaration{ID: typeName(typ), Content: code}, imports
+}
+
+func (buf buffer) codeForNamed(typ *an.Named) (gen.Declaration, []string) {
+	if len(typ.TypeArgs) != 0 {
+		return buf.codeForInstance(typ, typ.Origin, jsonForNamed(typ), []an.Type{typ.Underlying})
+	}
+
 	// type wrapper
 	code := fmt.Sprintf(`
 	// %s
 	typedef %s = %s;
 	
 	%s
-	`, gen.Origin(typ), typeName(typ), typeName(typ.Underlying),
+	`, gen.Origin(typ), declName(typ, typ.TypeParams), typeName(typ.Underlying),
 		jsonForNamed(typ),
 	)
 	out := gen.Declaration{ID: typeName(typ), Content: code}
 
+	if hasTypeParam(typ.Underlying) { // the underlying code is generated by the instantiations
+		return out, nil
+	}
+
 	// recurse for the underlying code
 	importFile := buf.generate(typ.Underlying, buf.linker.GetOutput(typ.Type()))
 
-	return out, importFile
+	return out, []string{importFile}
 }
 
 func codeForBasic(typ *an.Basic) gen.Declaration {
@@ -74,6 +156,13 @@ func codeForTime(typ *an.Time) gen.Declaration {
 	return gen.Declaration{ID: "__DateTime_json", Content: jsonForTime()}
 }
 
+func codeForExternal(typ *an.External) (out gen.Declaration, imports []string) {
+	if imp := typ.Mapping.DartImport; imp != "" { // for the JSON functions
+		imports = append(imports, imp)
+	}
+	return gen.Declaration{ID: jsonID(typ), Content: jsonForExternal(typ)}, imports
+}
+
 func (buf buffer) codeForArray(typ *an.Array, parentOutputFile string) (gen.Declaration, string) {
 	out := gen.Declaration{ID: jsonID(typ), Content: jsonForArray(typ)}
 
@@ -82,6 +171,14 @@ func (buf buffer) codeForArray(typ *an.Array, parentOutputFile string) (gen.Decl
 	return out, importS
 }
 
+func (buf buffer) codeForPointer(typ *an.Pointer, parentOutputFile string) (gen.Declaration, string) {
+	out := gen.Declaration{ID: jsonID(typ), Content: jsonForPointer(typ)}
+
+	// recurse for the element
+	importS := buf.generate(typ.Elem, parentOutputFile)
+	return out, importS
+}
+
 func (buf buffer) codeForMap(typ *an.Map, parentOutputFile string) (gen.Declaration, string, string) {
 	out := gen.Declaration{ID: jsonID(typ), Content: jsonForMap(typ)}
 
@@ -185,7 +282,17 @@ func (buf buffer) codeForUnion(typ *an.Union) (gen.Declaration, []string) {
 	return out, importMembers
 }
 
-func (buf buffer) codeForStruct(typ *an.Struct) (gen.Declaration, []string) {
+func (buf buffer) codeForStruct(typ *an.Struct, outfile string) (gen.Declaration, []string) {
+	if len(typ.TypeArgs) != 0 {
+		var deps []an.Type
+		for _, field := range typ.Fields {
+			if field.Exported() && !field.IsOpaqueFor("dart") {
+				deps = append(deps, field.Type)
+			}
+		}
+		return buf.codeForInstance(typ, typ.Origin, jsonForStruct(typ), deps)
+	}
+
 	var fields, initFields, interpolatedFields, importForFields []string
 	for _, field := range typ.Fields {
 		if !field.Exported() {
@@ -196,12 +303,21 @@ func (buf buffer) codeForStruct(typ *an.Struct) (gen.Declaration, []string) {
 		if field.IsOpaqueFor("dart") {
 			// use dynamic
 			tn = "dynamic"
+		} else if hasTypeParam(field.Type) {
+			// the field code is generated by the instantiations
+			tn = typeName(field.Type)
 		} else {
 			// recurse
-			importField := buf.generate(field.Type, buf.linker.GetOutput(typ.Name))
+			importField := buf.generate(field.Type, outfile)
 			importForFields = append(importForFields, importField)
 
 			tn = typeName(field.Type)
+			if _, isPointer := field.Type.(*an.Pointer); field.OmitEmpty() && !isPointer { // the field may be absent
+				tn += "?"
+			}
+			if field.AsString() { // for jsonEncode and jsonDecode
+				importForFields = append(importForFields, "dart:convert")
+			}
 		}
 
 		dartFieldName := lowerFirst(field.JSONName()) // convert to dart convention
@@ -225,6 +341,12 @@ func (buf buffer) codeForStruct(typ *an.Struct) (gen.Declaration, []string) {
 
 	name := typeName(typ)
 
+	// JSON functions are generated for each instantiation of generic types
+	var json string
+	if len(typ.TypeParams) == 0 {
+		json = jsonForStruct(typ)
+	}
+
 	out := gen.Declaration{
 		ID: name, Content: fmt.Sprintf(`
 		// %s
@@ -240,10 +362,10 @@ func (buf buffer) codeForStruct(typ *an.Struct) (gen.Declaration, []string) {
 		}
 		
 		%s
-	`, gen.Origin(typ), name, implementCode,
+	`, gen.Origin(typ), declName(typ, typ.TypeParams), implementCode,
 			strings.Join(fields, "\n"), name, strings.Join(initFields, ", "),
 			name, strings.Join(interpolatedFields, ", "),
-			jsonForStruct(typ),
+			json,
 		),
 	}
 
@@ -0,0 +1,195 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines
+// printed around each change
+const diffContext = 3
+
+type diffOp byte
+
+const (
+	diffEqual  diffOp = ' '
+	diffDelete diffOp = '-'
+	diffInsert diffOp = '+'
+)
+
+type diffLine struct {
+	op   diffOp
+	text string // including the trailing newline, if any
+}
+
+// UnifiedDiff returns the differences between [old] and [new]
+// in the unified format (as printed by diff -u), or nil if they are equal.
+func UnifiedDiff(oldName, newName string, old, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+
+	lines := diffLines(splitLines(string(old)), splitLines(string(new)))
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	// number of old and new lines before each index
+	oldBefore, newBefore := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	for i, line := range lines {
+		oldBefore[i+1], newBefore[i+1] = oldBefore[i], newBefore[i]
+		if line.op != diffInsert {
+			oldBefore[i+1]++
+		}
+		if line.op != diffDelete {
+			newBefore[i+1]++
+		}
+	}
+
+	for start := 0; start < len(lines); {
+		// find the next change, and extend the hunk while the changes are close
+		first := start
+		for first < len(lines) && lines[first].op == diffEqual {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		last := first
+		for next := first + 1; next < len(lines); next++ {
+			if lines[next].op == diffEqual {
+				continue
+			}
+			if next-last > 2*diffContext {
+				break
+			}
+			last = next
+		}
+
+		hunkStart, hunkEnd := max(first-diffContext, 0), min(last+diffContext+1, len(lines))
+		oldStart, oldCount := oldBefore[hunkStart], oldBefore[hunkEnd]-oldBefore[hunkStart]
+		newStart, newCount := newBefore[hunkStart], newBefore[hunkEnd]-newBefore[hunkStart]
+		if oldCount != 0 {
+			oldStart++
+		}
+		if newCount != 0 {
+			newStart++
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, line := range lines[hunkStart:hunkEnd] {
+			out.WriteByte(byte(line.op))
+			out.WriteString(line.text)
+			if !strings.HasSuffix(line.text, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		start = hunkEnd
+	}
+	return out.Bytes()
+}
+
+// splitLines splits [s], keeping the line terminators
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// maxDiffEdits bounds the number of edits searched by [diffLines],
+// whose memory is quadratic in this number
+const maxDiffEdits = 1000
+
+// replaceLines returns the edit script deleting all of [a],
+// then inserting all of [b]
+func replaceLines(a, b []string) []diffLine {
+	out := make([]diffLine, 0, len(a)+len(b))
+	for _, line := range a {
+		out = append(out, diffLine{diffDelete, line})
+	}
+	for _, line := range b {
+		out = append(out, diffLine{diffInsert, line})
+	}
+	return out
+}
+
+// diffLines returns a shortest edit script from [a] to [b], using
+// the Myers algorithm.
+// If more than [maxDiffEdits] edits are required, the whole content is replaced instead.
+func diffLines(a, b []string) []diffLine {
+	if len(a) == 0 || len(b) == 0 { // new or removed file
+		return replaceLines(a, b)
+	}
+
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1) // furthest x reached for each diagonal k = x - y
+
+	// trace[d] stores v[-d:d+1] before the round d, so that
+	// the path may be recovered (with quadratic memory in the number of edits)
+	var trace [][]int
+search:
+	for d := 0; d <= n+m; d++ {
+		if d > maxDiffEdits {
+			return replaceLines(a, b)
+		}
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // move down (insertion)
+			} else {
+				x = v[offset+k-1] + 1 // move right (deletion)
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// backtrack from the end
+	var out []diffLine
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		prev := trace[d]
+		at := func(k int) int { return prev[k+d] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := 0
+		if d > 0 {
+			prevX = at(prevK)
+		}
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			out = append(out, diffLine{diffEqual, a[x-1]})
+			x, y = x-1, y-1
+		}
+		if d > 0 {
+			if x == prevX {
+				out = append(out, diffLine{diffInsert, b[y-1]})
+				y--
+			} else {
+				out = append(out, diffLine{diffDelete, a[x-1]})
+				x--
+			}
+		}
+	}
+	slices.Reverse(out)
+	return out
+}
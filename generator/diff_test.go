@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/testutils"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	testutils.Assert(t, UnifiedDiff("a", "b", []byte("x\ny\n"), []byte("x\ny\n")) == nil)
+
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n"
+	new := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n"
+	diff := string(UnifiedDiff("old.ts", "new.ts", []byte(old), []byte(new)))
+	testutils.Assert(t, diff == `--- old.ts
++++ new.ts
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+@@ -14,3 +14,4 @@
+ 14
+ 15
+ 16
++17
+`)
+
+	// close changes are merged in one hunk
+	diff = string(UnifiedDiff("old", "new", []byte("a\nb\nc\nd\ne\n"), []byte("A\nb\nc\nd\nE\n")))
+	testutils.Assert(t, strings.Count(diff, "@@ -1,5 +1,5 @@") == 1)
+
+	// new file
+	diff = string(UnifiedDiff("old", "new", nil, []byte("a\nb")))
+	testutils.Assert(t, diff == "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n\\ No newline at end of file\n")
+
+	// missing file, whose diff does not depend on the number of edits
+	content := strings.Repeat("line\n", 100_000)
+	diff = string(UnifiedDiff("old", "new", nil, []byte(content)))
+	testutils.Assert(t, strings.HasPrefix(diff, "--- old\n+++ new\n@@ -0,0 +1,100000 @@\n+line\n"))
+	testutils.Assert(t, strings.Count(diff, "\n+line") == 100_000)
+
+	// removed file
+	diff = string(UnifiedDiff("old", "new", []byte("a\nb\n"), nil))
+	testutils.Assert(t, diff == "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-a\n-b\n")
+
+	// removed content
+	diff = string(UnifiedDiff("old", "new", []byte("a\nb\n"), []byte("b\n")))
+	testutils.Assert(t, diff == "--- old\n+++ new\n@@ -1,2 +1,1 @@\n-a\n b\n")
+}
+
+func TestDiffLines(t *testing.T) {
+	a := splitLines("a\nb\nc\na\nb\nb\na\n")
+	b := splitLines("c\nb\na\nb\na\nc\n")
+	lines := diffLines(a, b)
+
+	// the script is minimal, and transforms a into b
+	var nbEdits int
+	var gotA, gotB []string
+	for _, line := range lines {
+		if line.op != diffEqual {
+			nbEdits++
+		}
+		if line.op != diffInsert {
+			gotA = append(gotA, line.text)
+		}
+		if line.op != diffDelete {
+			gotB = append(gotB, line.text)
+		}
+	}
+	testutils.Assert(t, nbEdits == 5)
+	testutils.Assert(t, strings.Join(gotA, "") == strings.Join(a, "") && strings.Join(gotB, "") == strings.Join(b, ""))
+}
+
+func TestDiffLinesTooManyEdits(t *testing.T) {
+	var a, b []string
+	for i := range maxDiffEdits {
+		a = append(a, fmt.Sprintf("a%d\n", i))
+		b = append(b, fmt.Sprintf("b%d\n", i))
+	}
+	b = append(b, a[0])
+	lines := diffLines(a, b)
+
+	// the whole content is replaced
+	testutils.Assert(t, len(lines) == len(a)+len(b))
+	for i, line := range lines {
+		if i < len(a) {
+			testutils.Assert(t, line == diffLine{diffDelete, a[i]})
+		} else {
+			testutils.Assert(t, line == diffLine{diffInsert, b[i-len(a)]})
+		}
+	}
+}
@@ -1,19 +1,31 @@
 package generator
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/scanner"
 	"log"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
+
+	"golang.org/x/tools/imports"
 )
 
-// utility wrappers around command line tools
-// to format Go, Dart and TypeScript code.
+// Go and SQL code is formatted in-process, while Dart and TypeScript
+// code rely on utility wrappers around command line tools.
 
 // Formatters provides format commands for Go, Dart, TypeScript and SQL.
 // The zero value is a ready to use cache.
 type Formatters struct {
-	lock                                       sync.Mutex
-	hasGoFmt, hasDartFmt, hasTsFmt, hasPsqlFmt *bool
+	lock                 sync.Mutex
+	hasDartFmt, hasTsFmt *bool
 }
 
 type Format uint8
@@ -26,23 +38,6 @@ const (
 	Psql
 )
 
-// check if the goimports command is working
-// and caches the result
-func (fmts *Formatters) hasGo() bool {
-	fmts.lock.Lock()
-	defer fmts.lock.Unlock()
-
-	if fmts.hasGoFmt == nil {
-		err := exec.Command("which", "goimports").Run()
-		if err != nil {
-			log.Printf("No formatter for Go (%s)", err)
-		}
-		fmts.hasGoFmt = new(bool)
-		*fmts.hasGoFmt = err == nil
-	}
-	return *fmts.hasGoFmt
-}
-
 // check if the dart command is working
 // and caches the result
 func (fmts *Formatters) hasDart() bool {
@@ -77,43 +72,177 @@ func (fmts *Formatters) hasTypescript() bool {
 	return *fmts.hasTsFmt
 }
 
-// check if the pg_format command is working
-// and caches the result
-func (fmts *Formatters) hasPsql() bool {
-	fmts.lock.Lock()
-	defer fmts.lock.Unlock()
-
-	if fmts.hasPsqlFmt == nil {
-		err := exec.Command("pg_format", "-v").Run()
-		if err != nil {
-			log.Printf("No formatter for Psql (%s)", err)
-		}
-		fmts.hasPsqlFmt = new(bool)
-		*fmts.hasPsqlFmt = err == nil
-	}
-	return *fmts.hasPsqlFmt
-}
-
 // FormatFile format `filename`, if a formatter for `format` is found.
-// It returns an error if the command failed, not if no formatter is found.
+// It returns an error if the formatting failed (see [FormatError]),
+// not if no formatter is found.
 func (fr *Formatters) FormatFile(format Format, filename string) error {
 	switch format {
-	case Go:
-		if fr.hasGo() {
-			return exec.Command("goimports", "-w", filename).Run()
+	case Go, Psql:
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		formatted, err := formatInProcess(format, filename, content)
+		if err != nil {
+			return err
 		}
+		return os.WriteFile(filename, formatted, os.ModePerm)
 	case Dart:
 		if fr.hasDart() {
-			return exec.Command("dart", "format", filename).Run()
+			return runFormatter(filename, "dart", "format", filename)
 		}
 	case TypeScript:
 		if fr.hasTypescript() {
-			return exec.Command("npx", "prettier", "--write", filename).Run()
-		}
-	case Psql:
-		if fr.hasPsql() {
-			return exec.Command("pg_format", "-i", filename).Run()
+			return runFormatter(filename, "npx", "prettier", "--write", filename)
 		}
 	}
 	return nil
 }
+
+// formatInProcess formats Go (see [FormatGo]) and SQL (see [FormatSQL]) code
+func formatInProcess(format Format, filename string, content []byte) ([]byte, error) {
+	if format == Go {
+		return FormatGo(filename, content)
+	}
+	return FormatSQL(filename, content)
+}
+
+// FormatGo formats Go code with go/format, and adds the missing imports
+// (and removes the unused ones), as goimports does.
+// [filename] is used to resolve the imports, and may not exist.
+func FormatGo(filename string, content []byte) ([]byte, error) {
+	formatted, err := format.Source(content)
+	if err != nil {
+		line := 0
+		var list scanner.ErrorList
+		if errors.As(err, &list) && len(list) != 0 {
+			line, err = list[0].Pos.Line, errors.New(list[0].Msg)
+		}
+		return nil, newFormatError(filename, content, line, err)
+	}
+	formatted, err = imports.Process(filename, formatted, &imports.Options{Comments: true, TabIndent: true, TabWidth: 8})
+	if err != nil {
+		return nil, newFormatError(filename, content, 0, err)
+	}
+	return formatted, nil
+}
+
+// reFormatterLine matches the error locations printed by
+// prettier ("(12:5)") and dart format ("line 12, column 5")
+var reFormatterLine = regexp.MustCompile(`\((\d+):\d+\)|line (\d+), column`)
+
+// runFormatter runs the external formatter [command] on [filename],
+// returning a [FormatError] if it fails
+func runFormatter(filename string, command string, args ...string) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	out, err := exec.Command(command, args...).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	line := 0
+	if match := reFormatterLine.FindSubmatch(out); match != nil {
+		line, _ = strconv.Atoi(string(match[1]) + string(match[2])) // one of them is empty
+	}
+	if msg := bytes.TrimSpace(out); len(msg) != 0 {
+		err = fmt.Errorf("%s: %s", err, msg)
+	}
+	return newFormatError(filename, content, line, err)
+}
+
+// FormatError is returned when the generated code can't be formatted,
+// which usually means it is incorrect.
+type FormatError struct {
+	Filename string
+	Line     int    // 1-based line of the error, or 0 if unknown
+	Snippet  string // the lines around [Line], prefixed by their number
+	Err      error
+}
+
+// snippetContext is the number of lines printed around
+// the offending line of a [FormatError]
+const snippetContext = 2
+
+func newFormatError(filename string, content []byte, line int, err error) *FormatError {
+	out := &FormatError{Filename: filename, Line: line, Err: err}
+	lines := strings.Split(string(content), "\n")
+	if line <= 0 || line > len(lines) {
+		return out
+	}
+	var snippet strings.Builder
+	for i := max(line-snippetContext, 1); i <= min(line+snippetContext, len(lines)); i++ {
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&snippet, "%s %4d | %s\n", marker, i, lines[i-1])
+	}
+	out.Snippet = snippet.String()
+	return out
+}
+
+func (fe *FormatError) Error() string {
+	if fe.Line == 0 {
+		return fmt.Sprintf("formatting %s failed: %s", fe.Filename, fe.Err)
+	}
+	return fmt.Sprintf("formatting %s failed at line %d: %s\n%s", fe.Filename, fe.Line, fe.Err, fe.Snippet)
+}
+
+func (fe *FormatError) Unwrap() error { return fe.Err }
+
+// FormatFileWith formats [filename] in place with the custom [command],
+// whose arguments are separated by spaces, and to which [filename] is appended,
+// like "prettier --write".
+func FormatFileWith(command, filename string) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil
+	}
+	return runFormatter(filename, args[0], append(args[1:], filename)...)
+}
+
+// FormatContent returns [content] formatted as if it was the content of [filename],
+// which is not modified. The external formatters are run on a copy with the same name,
+// in a temporary directory.
+func (fr *Formatters) FormatContent(format Format, filename string, content []byte) ([]byte, error) {
+	switch format {
+	case NoFormat:
+		return content, nil
+	case Go, Psql:
+		return formatInProcess(format, filename, content)
+	}
+	return formatTemporary(filename, content, func(tmp string) error { return fr.FormatFile(format, tmp) })
+}
+
+// FormatContentWith is the same as [Formatters.FormatContent], but uses
+// a custom command, as [FormatFileWith].
+func FormatContentWith(command, filename string, content []byte) ([]byte, error) {
+	return formatTemporary(filename, content, func(tmp string) error { return FormatFileWith(command, tmp) })
+}
+
+// formatTemporary writes [content] to a file with the same name as [filename],
+// in a temporary directory (so that the output directory is left untouched),
+// calls [format] on it and returns the result
+func formatTemporary(filename string, content []byte, format func(tmp string) error) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "gomacro-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, filepath.Base(filename))
+	if err = os.WriteFile(tmp, content, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	if err = format(tmp); err != nil {
+		var fe *FormatError
+		if errors.As(err, &fe) {
+			fe.Filename = filename // hide the temporary file
+		}
+		return nil, err
+	}
+	return os.ReadFile(tmp)
+}
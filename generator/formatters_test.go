@@ -0,0 +1,77 @@
+package generator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/testutils"
+)
+
+func TestFormatGo(t *testing.T) {
+	const src = `package models
+
+import "os"
+
+func   quote(s string) string {
+return strconv.Quote(strings.TrimSpace(s))
+}
+`
+	out, err := FormatGo("models.go", []byte(src))
+	testutils.Assert(t, err == nil, err)
+	testutils.Assert(t, string(out) == `package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+func quote(s string) string {
+	return strconv.Quote(strings.TrimSpace(s))
+}
+`)
+
+	_, err = FormatGo("models.go", []byte("package models\n\nfunc f() {\n\treturn 1 +\n}\n"))
+	var fe *FormatError
+	testutils.Assert(t, errors.As(err, &fe))
+	testutils.Assert(t, fe.Filename == "models.go" && fe.Line == 5)
+	testutils.Assert(t, strings.Contains(fe.Snippet, ">    5 | }"))
+	testutils.Assert(t, strings.Contains(err.Error(), "formatting models.go failed at line 5"))
+}
+
+func TestFormatFileInProcess(t *testing.T) {
+	dir := t.TempDir()
+	goFile, sqlFile := filepath.Join(dir, "gen.go"), filepath.Join(dir, "gen.sql")
+	testutils.Assert(t, os.WriteFile(goFile, []byte("package gen\nvar  A = 1"), os.ModePerm) == nil)
+	testutils.Assert(t, os.WriteFile(sqlFile, []byte("\tCREATE TABLE t (\n\t\tA integer\n);"), os.ModePerm) == nil)
+
+	var fmts Formatters
+	testutils.Assert(t, fmts.FormatFile(Go, goFile) == nil)
+	testutils.Assert(t, fmts.FormatFile(Psql, sqlFile) == nil)
+	content, _ := os.ReadFile(goFile)
+	testutils.Assert(t, string(content) == "package gen\n\nvar A = 1\n")
+	content, _ = os.ReadFile(sqlFile)
+	testutils.Assert(t, string(content) == "CREATE TABLE t (\n    A integer\n);\n")
+
+	// FormatContent does not modify the file
+	formatted, err := fmts.FormatContent(Go, goFile, []byte("package gen\nvar  B = 1"))
+	testutils.Assert(t, err == nil, err)
+	testutils.Assert(t, string(formatted) == "package gen\n\nvar B = 1\n")
+	content, _ = os.ReadFile(goFile)
+	testutils.Assert(t, string(content) == "package gen\n\nvar A = 1\n")
+}
+
+func TestFormatContentWith(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "api.ts")
+
+	formatted, err := FormatContentWith("sed -i s/var/let/", file, []byte("var a = 1\n"))
+	testutils.Assert(t, err == nil, err)
+	testutils.Assert(t, string(formatted) == "let a = 1\n")
+
+	// the output directory is left untouched
+	entries, err := os.ReadDir(dir)
+	testutils.Assert(t, err == nil && len(entries) == 0, entries)
+}
@@ -111,7 +111,7 @@ type Cache map[*types.Named]bool
 // udpating it if not.
 // Non named types are ignored.
 func (c Cache) Check(typ analysis.Type) bool {
-	if named, isNamed := typ.Type().(*types.Named); isNamed {
+	if named, isNamed := types.Unalias(typ.Type()).(*types.Named); isNamed {
 		if c[named] {
 			return true
 		}
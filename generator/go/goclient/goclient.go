@@ -0,0 +1,577 @@
+// Package goclient generates a typed Go client
+// for the endpoints found by the httpapi package.
+// The types used by the endpoints are not redeclared : the generated
+// code refers to the original definitions.
+package goclient
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+	"unicode"
+
+	an "github.com/benoitkugler/gomacro/analysis"
+	"github.com/benoitkugler/gomacro/analysis/httpapi"
+	gen "github.com/benoitkugler/gomacro/generator"
+)
+
+// Generate returns the code for a Client struct, with one method
+// for each endpoint.
+// The code is written in [targetPackage], and imports the other packages
+// as required.
+func Generate(api []httpapi.Endpoint, targetPackage *types.Package) []gen.Declaration {
+	ctx := context{targetPackage: targetPackage, imports: make(map[string]string)}
+	out := []gen.Declaration{{ID: "__client", Content: clientCode}}
+	for _, endpoint := range api {
+		out = append(out, ctx.generateMethod(endpoint)...)
+	}
+
+	var imports []string
+	for path := range ctx.imports {
+		imports = append(imports, fmt.Sprintf("%q", path))
+	}
+	sort.Strings(imports)
+
+	out = append(out, gen.Declaration{
+		ID: "__header",
+		Content: fmt.Sprintf(`
+		package %s
+
+		import (
+			"bytes"
+			"context"
+			"encoding/json"
+			"fmt"
+			"io"
+			"mime/multipart"
+			"net/http"
+			"net/url"
+			"strings"
+			%s
+		)
+
+		// Code generated by gomacro/generator/go/goclient. DO NOT EDIT.
+
+		`, targetPackage.Name(), strings.Join(imports, "\n")),
+		Priority: true,
+	})
+
+	return out
+}
+
+// clientCode is shared by all the methods
+const clientCode = `
+// Client provides typed methods to call the HTTP API.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client // if nil, [http.DefaultClient] is used
+	Header     http.Header  // added to every request, for instance to provide authentication
+}
+
+// ClientFile is a file sent in a multipart form.
+type ClientFile struct {
+	Name    string
+	Content io.Reader
+}
+
+type clientBody struct {
+	content     io.Reader
+	contentType string
+}
+
+func clientJSON(v any) (clientBody, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return clientBody{}, err
+	}
+	return clientBody{bytes.NewReader(b), "application/json"}, nil
+}
+
+// clientForm builds a multipart form, with an optional file
+// and (key, value) pairs
+func clientForm(fileField string, file ClientFile, values ...string) (clientBody, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if fileField != "" {
+		fw, err := mw.CreateFormFile(fileField, file.Name)
+		if err != nil {
+			return clientBody{}, err
+		}
+		if _, err = io.Copy(fw, file.Content); err != nil {
+			return clientBody{}, err
+		}
+	}
+	for i := 0; i < len(values); i += 2 {
+		if err := mw.WriteField(values[i], values[i+1]); err != nil {
+			return clientBody{}, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return clientBody{}, err
+	}
+	return clientBody{&buf, mw.FormDataContentType()}, nil
+}
+
+// clientFormJSON returns the JSON encoding of v, as a string
+func clientFormJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// clientWildcard escapes a path parameter which may contain slashes
+func clientWildcard(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "%2F", "/")
+}
+
+// clientBool matches the encoding used by the TypeScript clients
+func clientBool(b bool) string {
+	if b {
+		return "ok"
+	}
+	return ""
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body clientBody) (*http.Request, error) {
+	fullURL := c.BaseURL + path
+	if len(query) != 0 {
+		fullURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, body.content)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range c.Header {
+		req.Header[k] = append(req.Header[k], vs...)
+	}
+	if body.contentType != "" {
+		req.Header.Set("Content-Type", body.contentType)
+	}
+	return req, nil
+}
+
+// do performs the request, returning an error for non 2XX status codes
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s %s: %s (%s)", req.Method, req.URL, resp.Status, bytes.TrimSpace(body))
+	}
+	return resp, nil
+}
+
+// send performs the request and decodes the JSON response into [out], if not nil
+func (c *Client) send(req *http.Request, out any) error {
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// sendBlob performs the request and returns the (binary) response
+func (c *Client) sendBlob(req *http.Request) ([]byte, error) {
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+`
+
+// streamCode is only added if one endpoint returns a stream
+const streamCode = `
+// clientStream performs the request and decodes the JSON values,
+// written one per line
+func clientStream[T any](c *Client, req *http.Request) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		resp, err := c.do(req)
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var item T
+			err := dec.Decode(&item)
+			if err == io.EOF {
+				return
+			}
+			if !yield(item, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// clientStreamError returns a sequence yielding only [err]
+func clientStreamError[T any](err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		yield(zero, err)
+	}
+}
+`
+
+type context struct {
+	targetPackage *types.Package
+	imports       map[string]string // package path -> name
+}
+
+func (ctx context) qualifier(pkg *types.Package) string {
+	if pkg == ctx.targetPackage {
+		return "" // same package; unqualified
+	}
+	ctx.imports[pkg.Path()] = pkg.Name()
+	return pkg.Name()
+}
+
+// typeName returns the Go code for [ty]
+func (ctx context) typeName(ty an.Type) string {
+	switch ty := ty.(type) {
+	case *an.Time:
+		ctx.imports["time"] = "time"
+		return "time.Time"
+	case *an.Pointer:
+		return "*" + ctx.typeName(ty.Elem)
+	case *an.Array:
+		if ty.Len >= 0 {
+			return fmt.Sprintf("[%d]%s", ty.Len, ctx.typeName(ty.Elem))
+		}
+		return "[]" + ctx.typeName(ty.Elem)
+	case *an.Map:
+		return fmt.Sprintf("map[%s]%s", ctx.typeName(ty.Key), ctx.typeName(ty.Elem))
+	default: // basic and named types
+		return types.TypeString(ty.Type(), ctx.qualifier)
+	}
+}
+
+// names used by the generated code (variables and imported packages),
+// which must not be shadowed by the parameters
+var reservedNames = map[string]bool{
+	"c": true, "ctx": true, "out": true, "query": true, "body": true,
+	"req": true, "err": true, "params": true, "file": true, "formJSON": true,
+	"url": true, "json": true, "http": true, "strconv": true, "time": true,
+	"io": true, "bytes": true, "strings": true, "fmt": true, "iter": true,
+	"multipart": true, "context": true,
+}
+
+// paramName returns a valid Go identifier for [name],
+// which may be any string
+func paramName(name string) string {
+	if name == "*" {
+		return "wildcard"
+	}
+	chunks := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for i := range chunks {
+		if i == 0 {
+			chunks[i] = gen.ToLowerFirst(chunks[i])
+		} else {
+			chunks[i] = strings.ToUpper(chunks[i][:1]) + chunks[i][1:]
+		}
+	}
+	out := strings.Join(chunks, "")
+	if out == "" || unicode.IsDigit(rune(out[0])) {
+		out = "p" + out
+	}
+	if token.IsKeyword(out) || reservedNames[out] {
+		out += "Param"
+	}
+	return out
+}
+
+// argNames maps the parameters of an endpoint to
+// distinct Go identifiers
+type argNames map[string]string
+
+// argKey identifies a parameter, since path, query and form
+// parameters may share the same name
+func argKey(kind, name string) string { return kind + ":" + name }
+
+// newArgNames resolves the types used by [a] and chooses
+// the argument names, so that they do not shadow the packages
+// referred to by these types, nor each other.
+func (ctx context) newArgNames(a httpapi.Endpoint) argNames {
+	ct := a.Contract
+	local := context{targetPackage: ctx.targetPackage, imports: make(map[string]string)}
+	for _, ty := range []an.Type{ct.InputBody, ct.InputForm.JSON.Type, ct.Return} {
+		if ty != nil {
+			local.typeName(ty)
+		}
+	}
+	for _, param := range append(ct.PathParams[:len(ct.PathParams):len(ct.PathParams)], ct.InputQueryParams...) {
+		local.typeName(param.Type)
+	}
+	packages := make(map[string]bool)
+	for _, name := range local.imports {
+		packages[name] = true
+	}
+
+	out := make(argNames)
+	used := make(map[string]bool)
+	add := func(kind, name string) {
+		base := paramName(name)
+		if packages[base] {
+			base += "Param"
+		}
+		varName := base
+		for i := 2; used[varName]; i++ {
+			varName = fmt.Sprintf("%s%d", base, i)
+		}
+		used[varName] = true
+		out[argKey(kind, name)] = varName
+	}
+	for _, param := range ct.PathParams {
+		add("path", param.Name)
+	}
+	for _, value := range ct.InputForm.ValueNames {
+		add("form", value)
+	}
+	if json := ct.InputForm.JSON; json.Name != "" {
+		add("json", json.Name)
+	}
+	for _, param := range ct.InputQueryParams {
+		add("query", param.Name)
+	}
+	return out
+}
+
+// methodName returns the exported name for the endpoint
+func methodName(a httpapi.Endpoint) string {
+	name := a.Contract.Name
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// pathCode returns the expression building the URL path,
+// with the path parameters escaped
+func (ctx context) pathCode(a httpapi.Endpoint, names argNames) string {
+	var chunks []string
+	for _, chunk := range a.PathChunks() {
+		if chunk.Param == nil {
+			chunks = append(chunks, fmt.Sprintf("%q", chunk.Static))
+		} else if chunk.IsWildcard { // keep the slashes
+			chunks = append(chunks, fmt.Sprintf("clientWildcard(%s)", paramValue(*chunk.Param, names[argKey("path", chunk.Param.Name)], ctx)))
+		} else {
+			chunks = append(chunks, fmt.Sprintf("url.PathEscape(%s)", paramValue(*chunk.Param, names[argKey("path", chunk.Param.Name)], ctx)))
+		}
+	}
+	if len(chunks) == 0 {
+		return `""`
+	}
+	return strings.Join(chunks, " + ")
+}
+
+// paramValue returns the code converting the (query or path) param,
+// stored in [varName], to a string
+func paramValue(param httpapi.TypedParam, varName string, ctx context) string {
+	var underlying *types.Basic
+	switch t := param.Type.(type) {
+	case *an.Basic:
+		underlying = t.B
+	case *an.Named:
+		underlying = t.Underlying.(*an.Basic).B
+	case *an.Enum:
+		underlying = t.Underlying()
+	default:
+		panic(an.NewError(token.Position{}, an.CodeUnsupportedType, "unsupported type %s for query param %s", param.Type.Type(), param.Name))
+	}
+
+	// only convert when required
+	convert := func(basic types.BasicKind, name string) string {
+		if underlying.Kind() == basic && types.Identical(param.Type.Type(), underlying) {
+			return varName
+		}
+		return fmt.Sprintf("%s(%s)", name, varName)
+	}
+	switch (&an.Basic{B: underlying}).Kind() {
+	case an.BKInt:
+		ctx.imports["strconv"] = "strconv"
+		return fmt.Sprintf("strconv.FormatInt(%s, 10)", convert(types.Int64, "int64"))
+	case an.BKFloat:
+		ctx.imports["strconv"] = "strconv"
+		return fmt.Sprintf("strconv.FormatFloat(%s, 'g', -1, 64)", convert(types.Float64, "float64"))
+	case an.BKBool:
+		return fmt.Sprintf("clientBool(%s)", convert(types.Bool, "bool"))
+	case an.BKString:
+		return convert(types.String, "string")
+	default:
+		panic(an.ExhaustiveBasicKindSwitch)
+	}
+}
+
+func (ctx context) pathParamsIn(a httpapi.Endpoint, names argNames) []string {
+	var out []string
+	for _, param := range a.Contract.PathParams {
+		out = append(out, names[argKey("path", param.Name)]+" "+ctx.typeName(param.Type))
+	}
+	return out
+}
+
+// return the arguments (without context) for the method
+func (ctx context) typeIn(a httpapi.Endpoint, names argNames) []string {
+	out := ctx.pathParamsIn(a, names)
+	if ty := a.Contract.InputBody; ty != nil {
+		out = append(out, "params "+ctx.typeName(ty))
+	} else if form := a.Contract.InputForm; !form.IsZero() {
+		if form.File != "" {
+			out = append(out, "file ClientFile")
+		}
+		for _, value := range form.ValueNames {
+			out = append(out, names[argKey("form", value)]+" string")
+		}
+		if json := form.JSON; json.Name != "" {
+			out = append(out, names[argKey("json", json.Name)]+" "+ctx.typeName(json.Type))
+		}
+	}
+	for _, param := range a.Contract.InputQueryParams {
+		out = append(out, names[argKey("query", param.Name)]+" "+ctx.typeName(param.Type))
+	}
+	return out
+}
+
+func (ctx context) queryCode(a httpapi.Endpoint, names argNames) string {
+	if len(a.Contract.InputQueryParams) == 0 {
+		return "var query url.Values"
+	}
+	code := "query := url.Values{}\n"
+	for _, param := range a.Contract.InputQueryParams {
+		code += fmt.Sprintf("query.Set(%q, %s)\n", param.Name, paramValue(param, names[argKey("query", param.Name)], ctx))
+	}
+	return code
+}
+
+// bodyCode returns the code defining the 'body' variable, returning
+// [errReturn] on failure
+func (ctx context) bodyCode(a httpapi.Endpoint, names argNames, errReturn string) string {
+	if a.Contract.InputBody != nil {
+		return fmt.Sprintf(`body, err := clientJSON(params)
+		if err != nil {
+			return %s
+		}`, errReturn)
+	}
+	form := a.Contract.InputForm
+	if form.IsZero() {
+		return "var body clientBody"
+	}
+
+	code := ""
+	var values []string
+	for _, value := range form.ValueNames {
+		values = append(values, fmt.Sprintf("%q, %s", value, names[argKey("form", value)]))
+	}
+	if json := form.JSON; json.Name != "" {
+		code += fmt.Sprintf(`formJSON, err := clientFormJSON(%s)
+		if err != nil {
+			return %s
+		}
+		`, names[argKey("json", json.Name)], errReturn)
+		values = append(values, fmt.Sprintf("%q, formJSON", json.Name))
+	}
+	fileArg := "ClientFile{}"
+	if form.File != "" {
+		fileArg = "file"
+	}
+	args := append([]string{fmt.Sprintf("%q", form.File), fileArg}, values...)
+	code += fmt.Sprintf(`body, err := clientForm(%s)
+	if err != nil {
+		return %s
+	}`, strings.Join(args, ", "), errReturn)
+	return code
+}
+
+func (ctx context) generateURL(a httpapi.Endpoint) gen.Declaration {
+	name := methodName(a) + "URL"
+	names := ctx.newArgNames(a)
+	args := ctx.pathParamsIn(a, names)
+	for _, param := range a.Contract.InputQueryParams {
+		args = append(args, names[argKey("query", param.Name)]+" "+ctx.typeName(param.Type))
+	}
+	const template = `
+	// %[1]s returns the URL for the endpoint %[2]s %[3]s
+	func (c *Client) %[1]s(%[4]s) string {
+		%[5]s
+		if len(query) != 0 {
+			return c.BaseURL + %[6]s + "?" + query.Encode()
+		}
+		return c.BaseURL + %[6]s
+	}
+	`
+	return gen.Declaration{
+		ID:      name,
+		Content: fmt.Sprintf(template, name, a.Method, a.Url, strings.Join(args, ", "), ctx.queryCode(a, names), ctx.pathCode(a, names)),
+	}
+}
+
+func (ctx context) generateMethod(a httpapi.Endpoint) []gen.Declaration {
+	if a.IsUrlOnly {
+		return []gen.Declaration{ctx.generateURL(a)}
+	}
+
+	name := methodName(a)
+	names := ctx.newArgNames(a)
+	args := strings.Join(append([]string{"ctx context.Context"}, ctx.typeIn(a, names)...), ", ")
+
+	var (
+		returnType, errReturn, outDecl, call string
+		ct                                   = a.Contract
+	)
+	switch {
+	case ct.IsReturnStream:
+		ctx.imports["iter"] = "iter"
+		item := ctx.typeName(ct.Return)
+		returnType = fmt.Sprintf("iter.Seq2[%s, error]", item)
+		errReturn = fmt.Sprintf("clientStreamError[%s](err)", item)
+		call = fmt.Sprintf("return clientStream[%s](c, req)", item)
+	case ct.IsReturnBlob:
+		returnType = "([]byte, error)"
+		errReturn = "nil, err"
+		call = "return c.sendBlob(req)"
+	case ct.Return == nil:
+		returnType = "error"
+		errReturn = "err"
+		call = "return c.send(req, nil)"
+	default:
+		returnType = fmt.Sprintf("(%s, error)", ctx.typeName(ct.Return))
+		errReturn = "out, err"
+		outDecl = "var out " + ctx.typeName(ct.Return) + "\n"
+		call = "err = c.send(req, &out)\nreturn out, err"
+	}
+
+	const template = `
+	// %[1]s performs the request %[2]s %[3]s
+	func (c *Client) %[1]s(%[4]s) %[5]s {
+		%[6]s%[7]s
+		%[8]s
+		req, err := c.newRequest(ctx, %[2]q, %[11]s, query, body)
+		if err != nil {
+			return %[9]s
+		}
+		%[10]s
+	}
+	`
+	code := fmt.Sprintf(template, name, a.Method, a.Url, args, returnType,
+		outDecl, ctx.queryCode(a, names), ctx.bodyCode(a, names, errReturn), errReturn, call, ctx.pathCode(a, names))
+
+	out := []gen.Declaration{{ID: name, Content: code}}
+	if ct.IsReturnStream {
+		out = append(out, gen.Declaration{ID: "__client_stream", Content: streamCode})
+	}
+	return out
+}
@@ -0,0 +1,81 @@
+package goclient
+
+import (
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"github.com/benoitkugler/gomacro/analysis/httpapi"
+	"github.com/benoitkugler/gomacro/generator"
+	. "github.com/benoitkugler/gomacro/testutils"
+)
+
+func TestParamName(t *testing.T) {
+	Assert(t, paramName("my-bool") == "myBool")
+	Assert(t, paramName("query_param1") == "queryParam1")
+	Assert(t, paramName("default") == "defaultParam")
+	Assert(t, paramName("file") == "fileParam")
+	Assert(t, paramName("url") == "urlParam")
+	Assert(t, paramName("time") == "timeParam")
+	Assert(t, paramName("form-JSON") == "formJSONParam")
+	Assert(t, paramName("2d") == "p2d")
+}
+
+func TestArgNames(t *testing.T) {
+	ctx := context{targetPackage: types.NewPackage("example.com/client", "client"), imports: make(map[string]string)}
+
+	// the body type is qualified by the models package
+	pkg := types.NewPackage("example.com/models", "models")
+	user := types.NewNamed(types.NewTypeName(0, pkg, "User", nil), types.NewStruct(nil, nil), nil)
+	names := ctx.newArgNames(httpapi.Endpoint{Contract: httpapi.Contract{
+		InputBody:        &analysis.Struct{Name: user},
+		InputQueryParams: []httpapi.TypedParam{{Name: "models", Type: analysis.String}},
+	}})
+	Assert(t, names[argKey("query", "models")] == "modelsParam", names)
+
+	// path and query params with the same name
+	names = ctx.newArgNames(httpapi.Endpoint{Contract: httpapi.Contract{
+		PathParams:       []httpapi.TypedParam{{Name: "id", Type: analysis.Int}},
+		InputQueryParams: []httpapi.TypedParam{{Name: "id", Type: analysis.Int}, {Name: "user-id", Type: analysis.String}, {Name: "userId", Type: analysis.String}},
+	}})
+	Assert(t, names[argKey("path", "id")] == "id" && names[argKey("query", "id")] == "id2", names)
+	Assert(t, names[argKey("query", "user-id")] == "userId" && names[argKey("query", "userId")] == "userId2", names)
+}
+
+func TestGenerate(t *testing.T) {
+	// the client is generated in the same package as the routes,
+	// so that unexported types may be used
+	fn := "../../../analysis/httpapi/test/routes.go"
+	pack, err := analysis.LoadSource(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apis := httpapi.ParseEcho(pack, abs, nil)
+	out := generator.WriteDeclarations(Generate(apis, pack.Types))
+
+	output := "../../../analysis/httpapi/test/client_gen.go"
+	err = os.WriteFile(output, []byte(out), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fmts generator.Formatters
+	if err := fmts.FormatFile(generator.Go, output); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnsupportedParam(t *testing.T) {
+	diags := analysis.NewDiagnostics(nil)
+	ok := diags.Try(0, func() {
+		paramValue(httpapi.TypedParam{Name: "ids", Type: &analysis.Array{Elem: analysis.Int, Len: -1}}, "ids", context{})
+	})
+	Assert(t, !ok && diags.ErrorCount() == 1, diags.List())
+}
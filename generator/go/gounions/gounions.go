@@ -4,6 +4,7 @@ package gounions
 
 import (
 	"fmt"
+	"go/token"
 	"go/types"
 	"strings"
 
@@ -13,7 +14,8 @@ import (
 
 // Generate walks through all the types in `Source`
 // and generate JSON routines for unions.
-func Generate(an *an.Analysis) []gen.Declaration {
+// The types which are not supported are reported to [diags].
+func Generate(ana *an.Analysis, diags *an.Diagnostics) []gen.Declaration {
 	var out []gen.Declaration
 
 	out = append(out, gen.Declaration{
@@ -23,13 +25,13 @@ func Generate(an *an.Analysis) []gen.Declaration {
 		import "encoding/json"
 		
 		// Code generated by gomacro/generator/gounions. DO NOT EDIT
-		`, an.Pkg.Name),
+		`, ana.Pkg.Name),
 		Priority: true,
 	})
 
-	ctx := context{cache: make(gen.Cache), srcPkg: an.Pkg.Types}
-	for _, typ := range an.Source {
-		out = append(out, ctx.generate(an.Types[typ])...)
+	ctx := context{cache: make(gen.Cache), srcPkg: ana.Pkg.Types}
+	for _, typ := range ana.Source {
+		diags.Try(an.DeclarationPos(typ), func() { out = append(out, ctx.generate(ana.Types[typ])...) })
 	}
 
 	return out
@@ -46,18 +48,18 @@ func (ctx context) generate(typ an.Type) []gen.Declaration {
 	}
 
 	switch typ := typ.(type) {
-	case *an.Basic, *an.Time, *an.Enum:
+	case *an.Basic, *an.Time, *an.Enum, *an.External, *an.TypeParam:
 		return nil
 	case *an.Pointer:
 		return ctx.generate(typ.Elem)
 	case *an.Array:
 		if _, isElemUnion := typ.Elem.(*an.Union); isElemUnion {
-			panic("anonymous arrays containing unions are not supported")
+			panic(an.NewError(token.Position{}, an.CodeUnsupportedType, "anonymous arrays containing unions are not supported (%s)", typ.Type()))
 		}
 		return nil
 	case *an.Map:
 		if _, isElemUnion := typ.Elem.(*an.Union); isElemUnion {
-			panic("anonymous maps containing unions are not supported")
+			panic(an.NewError(token.Position{}, an.CodeUnsupportedType, "anonymous maps containing unions are not supported (%s)", typ.Type()))
 		}
 		return nil
 	case *an.Named:
@@ -271,7 +273,8 @@ func (ctx context) codeForStruct(st *an.Struct) (out []gen.Declaration) {
 	}
 
 	// check for field requiring the wrapper
-	if !structRequireWrapper {
+	// (methods can't be defined on anonymous structs)
+	if !structRequireWrapper || st.Anonymous != nil {
 		return out
 	}
 
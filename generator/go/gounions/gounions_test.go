@@ -14,9 +14,9 @@ func TestUnions(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	an := analysis.NewAnalysisFromFile(pkg, source)
+	an := analysis.NewAnalysisFromFile(pkg, source, nil)
 
-	decls := Generate(an)
+	decls := Generate(an, nil)
 	out := generator.WriteDeclarations(decls)
 
 	fn := "test/gen.go"
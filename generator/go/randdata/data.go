@@ -2,6 +2,7 @@ package randdata
 
 import (
 	"fmt"
+	"go/token"
 	"go/types"
 	"strings"
 
@@ -11,18 +12,19 @@ import (
 
 // Generate generates the code for random data generation of
 // types defined in the analysis `Source`.
-func Generate(ana *an.Analysis) []gen.Declaration {
-	return generateWithTarget(ana, ana.Pkg.Types)
+// The types which are not supported are reported to [diags].
+func Generate(ana *an.Analysis, diags *an.Diagnostics) []gen.Declaration {
+	return generateWithTarget(ana, ana.Pkg.Types, diags)
 }
 
-func generateWithTarget(ana *an.Analysis, targetPackage *types.Package) []gen.Declaration {
+func generateWithTarget(ana *an.Analysis, targetPackage *types.Package, diags *an.Diagnostics) []gen.Declaration {
 	var (
 		out []gen.Declaration
 		ctx = context{cache: make(gen.Cache), targetPackage: targetPackage}
 	)
 
 	for _, typ := range ana.Source {
-		out = append(out, ctx.generate(ana.Types[typ])...)
+		diags.Try(an.DeclarationPos(typ), func() { out = append(out, ctx.generate(ana.Types[typ])...) })
 	}
 
 	imports := ctx.cache.Imports()
@@ -54,6 +56,9 @@ type context struct {
 
 // return the name or description in Go of the type `typ`
 func (ctx context) typeName(typ an.Type) string {
+	if _, isTime := typ.(*an.Time); isTime {
+		return "time.Time" // see codeForTime
+	}
 	return types.TypeString(typ.Type(), gen.NameRelativeTo(ctx.targetPackage))
 }
 
@@ -66,24 +71,17 @@ func functionIDBasicOrNamed(ctx context, ty types.Type) string {
 			return "int32"
 		}
 		return ty.Name()
-	case *types.Named:
+	case *types.Named, *types.Alias: // external types may be aliases
 		// build a string usable in function names
-		obj := ty.Obj()
+		obj := ty.(interface{ Obj() *types.TypeName }).Obj()
 		packageName := obj.Pkg().Name()
-		localName := obj.Name()
-		var name string
+		localName := an.MonomorphizedName(ty) // instantiated generics are monomorphized
 		if obj.Pkg() == ctx.targetPackage {
-			name = localName
-		} else {
-			name = packageName[:3] + "_" + localName
-		}
-		typeParams := ty.TypeArgs()
-		for i := range typeParams.Len() {
-			name += "_" + functionIDBasicOrNamed(ctx, typeParams.At(i))
+			return localName
 		}
-		return name
+		return packageName[:3] + "_" + localName
 	default:
-		panic("not supported")
+		panic(an.NewError(token.Position{}, an.CodeUnsupportedType, "unsupported type %s", ty))
 	}
 }
 
@@ -105,8 +103,10 @@ func (ctx context) functionID(ty an.Type) string {
 		return fmt.Sprintf("Slice%s", ctx.functionID(ty.Elem))
 	case *an.Map:
 		return fmt.Sprintf("Map%s%s", ctx.functionID(ty.Key), ctx.functionID(ty.Elem))
-	case *an.Named, *an.Enum, *an.Struct, *an.Union:
-		return functionIDBasicOrNamed(ctx, ty.Type().(*types.Named))
+	case *an.Struct: // anonymous structs use their synthesized name
+		return functionIDBasicOrNamed(ctx, ty.Name)
+	case *an.Named, *an.Enum, *an.Union, *an.External:
+		return functionIDBasicOrNamed(ctx, ty.Type())
 	default:
 		panic(an.ExhaustiveTypeSwitch)
 	}
@@ -122,9 +122,14 @@ func (ctx context) generate(ty an.Type) []gen.Declaration {
 		return []gen.Declaration{ctx.codeForBasic(ty)}
 	case *an.Time:
 		return []gen.Declaration{ctx.codeForTime(ty)}
+	case *an.External:
+		return []gen.Declaration{ctx.codeForExternal(ty)}
 	case *an.Pointer:
 		return ctx.codeForPointer(ty)
 	case *an.Named:
+		if len(ty.TypeParams) != 0 { // only instantiations have random values
+			return nil
+		}
 		return ctx.codeForNamed(ty)
 	case *an.Array:
 		return ctx.codeForArray(ty)
@@ -133,6 +138,9 @@ func (ctx context) generate(ty an.Type) []gen.Declaration {
 	case *an.Enum:
 		return []gen.Declaration{ctx.codeForEnum(ty)}
 	case *an.Struct:
+		if len(ty.TypeParams) != 0 { // only instantiations have random values
+			return nil
+		}
 		return ctx.codeForStruct(ty)
 	case *an.Union:
 		return ctx.codeForUnion(ty)
@@ -165,7 +173,7 @@ func (ctx context) codeForBasic(bs *an.Basic) gen.Declaration {
 	case types.String:
 		code = fnString()
 	default:
-		panic(fmt.Sprintf("basic type %v not supported", bs.B))
+		panic(an.NewError(token.Position{}, an.CodeUnsupportedType, "basic type %s not supported", bs.B))
 	}
 	return gen.Declaration{ID: ctx.functionID(bs), Content: code}
 }
@@ -216,6 +224,20 @@ func (ctx context) codeForTime(ty *an.Time) gen.Declaration {
 	return gen.Declaration{ID: id, Content: content}
 }
 
+func (ctx context) codeForExternal(ty *an.External) gen.Declaration {
+	id, name := ctx.functionID(ty), ctx.typeName(ty)
+	body := fmt.Sprintf("var out %s\n return out", name) // zero value
+	if fn := ty.Mapping.Randdata; fn != "" {
+		body = fmt.Sprintf("return %s()", fn)
+	}
+	content := fmt.Sprintf(`
+	func rand%s() %s {
+		%s
+	}
+	`, id, name, body)
+	return gen.Declaration{ID: id, Content: content}
+}
+
 func (ctx context) codeForPointer(ty *an.Pointer) []gen.Declaration {
 	out := ctx.generate(ty.Elem) // recurse
 	elemName := ctx.typeName(ty.Elem)
@@ -223,6 +245,9 @@ func (ctx context) codeForPointer(ty *an.Pointer) []gen.Declaration {
 	decl := gen.Declaration{
 		ID: id, Content: fmt.Sprintf(`
 		func rand%s() *%s {
+			if rand.Intn(4) == 0 { // nil pointers are also valid
+				return nil
+			}
 			data := rand%s()
 			return &data
 		}`, id, elemName, ctx.functionID(ty.Elem)),
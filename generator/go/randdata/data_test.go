@@ -8,6 +8,7 @@ import (
 
 	"github.com/benoitkugler/gomacro/analysis"
 	"github.com/benoitkugler/gomacro/generator"
+	"github.com/benoitkugler/gomacro/testutils"
 )
 
 func TestGenerate(t *testing.T) {
@@ -16,10 +17,10 @@ func TestGenerate(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	an := analysis.NewAnalysisFromFile(pkg, source)
+	an := analysis.NewAnalysisFromFile(pkg, source, nil)
 
 	testPath := strings.ReplaceAll(an.Pkg.PkgPath, "testutils/testsource", "generator/go/randdata")
-	decls := generateWithTarget(an, types.NewPackage(testPath, "test"))
+	decls := generateWithTarget(an, types.NewPackage(testPath, "test"), nil)
 	out := generator.WriteDeclarations(decls)
 
 	fn := "test/data.go"
@@ -33,3 +34,89 @@ func TestGenerate(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestPointers(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithPointers")
+	an := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	code := generator.WriteDeclarations(generateWithTarget(an, types.NewPackage("test", "test"), nil))
+	for _, expected := range []string{
+		"func randstringPtr() *string {",
+		"return nil",
+		"s.Label = randstringPtr()",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestExternals(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithExternals")
+	an := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	code := generator.WriteDeclarations(generateWithTarget(an, types.NewPackage("test", "test"), nil))
+	for _, expected := range []string{
+		`"net/netip"`,
+		"func randnet_Addr() netip.Addr {",
+		"var out netip.Addr",
+		"func randtim_Duration() time.Duration {",
+		"return randDuration()",
+		"s.Amount = randbig_IntPtr()",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestGenerics(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithGenerics")
+	an := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	code := generator.WriteDeclarations(generateWithTarget(an, types.NewPackage("test", "test"), nil))
+	for _, expected := range []string{
+		"func randtes_PageComp() testsource.Page[testsource.Comp] {",
+		"func randtes_PairStringEnumInt() testsource.Pair[string, testsource.EnumInt] {",
+		"func randtes_BatchString() testsource.Batch[string] {",
+		"s.Nested = randtes_PageBatchComp()",
+		"s.Items = randSlicetes_BatchComp()",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestAnonymousStructs(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithAnonymous")
+	an := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	code := generator.WriteDeclarations(generateWithTarget(an, types.NewPackage("test", "test"), nil))
+	for _, expected := range []string{
+		"func randtes_WithAnonymousPair() struct{A string; B string} {",
+		"s.Meta = randtes_WithAnonymousMeta()",
+		"s.Inner = randtes_WithAnonymousItemsInner()",
+		"func randtes_RowsElem() struct{X int} {",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
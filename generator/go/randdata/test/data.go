@@ -1,7 +1,10 @@
 package test
 
 import (
+	"encoding/json"
+	"math/big"
 	"math/rand"
+	"net/netip"
 	"time"
 
 	"github.com/benoitkugler/gomacro/testutils/testsource"
@@ -53,6 +56,24 @@ func randSliceint() []int {
 	return out
 }
 
+func randSlicenet_Addr() []netip.Addr {
+	l := 3 + rand.Intn(5)
+	out := make([]netip.Addr, l)
+	for i := range out {
+		out[i] = randnet_Addr()
+	}
+	return out
+}
+
+func randSlicestring() []string {
+	l := 3 + rand.Intn(5)
+	out := make([]string, l)
+	for i := range out {
+		out[i] = randstring()
+	}
+	return out
+}
+
 func randSlicesub_Enum() []subpackage.Enum {
 	l := 3 + rand.Intn(5)
 	out := make([]subpackage.Enum, l)
@@ -62,6 +83,33 @@ func randSlicesub_Enum() []subpackage.Enum {
 	return out
 }
 
+func randSlicetes_BatchComp() []testsource.Batch[testsource.Comp] {
+	l := 3 + rand.Intn(5)
+	out := make([]testsource.Batch[testsource.Comp], l)
+	for i := range out {
+		out[i] = randtes_BatchComp()
+	}
+	return out
+}
+
+func randSlicetes_Comp() []testsource.Comp {
+	l := 3 + rand.Intn(5)
+	out := make([]testsource.Comp, l)
+	for i := range out {
+		out[i] = randtes_Comp()
+	}
+	return out
+}
+
+func randSlicetes_EnumIntPtr() []*testsource.EnumInt {
+	l := 3 + rand.Intn(5)
+	out := make([]*testsource.EnumInt, l)
+	for i := range out {
+		out[i] = randtes_EnumIntPtr()
+	}
+	return out
+}
+
 func randSlicetes_ItfType() []testsource.ItfType {
 	l := 3 + rand.Intn(5)
 	out := make([]testsource.ItfType, l)
@@ -80,6 +128,45 @@ func randSlicetes_RecursiveType() []testsource.RecursiveType {
 	return out
 }
 
+func randSlicetes_RowsElem() []struct{ X int } {
+	l := 3 + rand.Intn(5)
+	out := make([]struct{ X int }, l)
+	for i := range out {
+		out[i] = randtes_RowsElem()
+	}
+	return out
+}
+
+func randSlicetes_WithAnonymousItems() []struct {
+	Id    int64
+	Label string
+	Inner struct{ Ok bool }
+} {
+	l := 3 + rand.Intn(5)
+	out := make([]struct {
+		Id    int64
+		Label string
+		Inner struct{ Ok bool }
+	}, l)
+	for i := range out {
+		out[i] = randtes_WithAnonymousItems()
+	}
+	return out
+}
+
+func randbig_Int() big.Int {
+	var out big.Int
+	return out
+}
+
+func randbig_IntPtr() *big.Int {
+	if rand.Intn(4) == 0 { // nil pointers are also valid
+		return nil
+	}
+	data := randbig_Int()
+	return &data
+}
+
 func randbool() bool {
 	i := rand.Int31n(2)
 	return i == 1
@@ -97,6 +184,24 @@ func randint64() int64 {
 	return int64(rand.Intn(1000000))
 }
 
+func randintPtr() *int {
+	if rand.Intn(4) == 0 { // nil pointers are also valid
+		return nil
+	}
+	data := randint()
+	return &data
+}
+
+func randjso_RawMessage() json.RawMessage {
+	var out json.RawMessage
+	return out
+}
+
+func randnet_Addr() netip.Addr {
+	var out netip.Addr
+	return out
+}
+
 var letterRunes2 = []rune("azertyuiopqsdfghjklmwxcvbn123456789é@!?&èïab ")
 
 func randstring() string {
@@ -108,6 +213,14 @@ func randstring() string {
 	return string(b)
 }
 
+func randstringPtr() *string {
+	if rand.Intn(4) == 0 { // nil pointers are also valid
+		return nil
+	}
+	data := randstring()
+	return &data
+}
+
 func randsub_Enum() subpackage.Enum {
 	choix := [...]subpackage.Enum{subpackage.A, subpackage.B, subpackage.C}
 	i := rand.Intn(len(choix))
@@ -133,6 +246,14 @@ func randtTime() time.Time {
 	return time.Unix(int64(rand.Int31()), 5)
 }
 
+func randtTimePtr() *time.Time {
+	if rand.Intn(4) == 0 { // nil pointers are also valid
+		return nil
+	}
+	data := randtTime()
+	return &data
+}
+
 func randtes_Basic1() testsource.Basic1 {
 	return testsource.Basic1(randint())
 }
@@ -149,6 +270,38 @@ func randtes_Basic4() testsource.Basic4 {
 	return testsource.Basic4(randstring())
 }
 
+func randtes_BatchComp() testsource.Batch[testsource.Comp] {
+	return testsource.Batch[testsource.Comp](randSlicetes_Comp())
+}
+
+func randtes_BatchCompPtr() *testsource.Batch[testsource.Comp] {
+	if rand.Intn(4) == 0 { // nil pointers are also valid
+		return nil
+	}
+	data := randtes_BatchComp()
+	return &data
+}
+
+func randtes_BatchString() testsource.Batch[string] {
+	return testsource.Batch[string](randSlicestring())
+}
+
+func randtes_Comp() testsource.Comp {
+	var s testsource.Comp
+	s.A = randuint8()
+	s.B = randuint8()
+
+	return s
+}
+
+func randtes_CompPtr() *testsource.Comp {
+	if rand.Intn(4) == 0 { // nil pointers are also valid
+		return nil
+	}
+	data := randtes_Comp()
+	return &data
+}
+
 func randtes_ComplexStruct() testsource.ComplexStruct {
 	var s testsource.ComplexStruct
 	s.DictWithTag = randMapintint()
@@ -164,8 +317,8 @@ func randtes_ComplexStruct() testsource.ComplexStruct {
 	s.F = randAr5_Ar5_bool()
 	s.Imported = randsub_StructWithComment()
 	s.EnumMap = randMaptes_EnumIntbool()
-	s.OptID1 = randtes_Generic_tes_IdCamp()
-	s.OptID2 = randtes_Generic_tes_IdFile()
+	s.OptID1 = randtes_GenericIdCamp()
+	s.OptID2 = randtes_GenericIdFile()
 
 	return s
 }
@@ -191,20 +344,28 @@ func randtes_EnumInt() testsource.EnumInt {
 	return choix[i]
 }
 
+func randtes_EnumIntPtr() *testsource.EnumInt {
+	if rand.Intn(4) == 0 { // nil pointers are also valid
+		return nil
+	}
+	data := randtes_EnumInt()
+	return &data
+}
+
 func randtes_EnumUInt() testsource.EnumUInt {
 	choix := [...]testsource.EnumUInt{testsource.A, testsource.B, testsource.C, testsource.D}
 	i := rand.Intn(len(choix))
 	return choix[i]
 }
 
-func randtes_Generic_tes_IdCamp() testsource.Generic[testsource.IdCamp] {
+func randtes_GenericIdCamp() testsource.Generic[testsource.IdCamp] {
 	var s testsource.Generic[testsource.IdCamp]
 	s.Id = randtes_IdCamp()
 
 	return s
 }
 
-func randtes_Generic_tes_IdFile() testsource.Generic[testsource.IdFile] {
+func randtes_GenericIdFile() testsource.Generic[testsource.IdFile] {
 	var s testsource.Generic[testsource.IdFile]
 	s.Id = randtes_IdFile()
 
@@ -244,6 +405,41 @@ func randtes_MyDate() testsource.MyDate {
 	return testsource.MyDate(randtDate())
 }
 
+func randtes_PageBatchComp() testsource.Page[testsource.Batch[testsource.Comp]] {
+	var s testsource.Page[testsource.Batch[testsource.Comp]]
+	s.Items = randSlicetes_BatchComp()
+	s.Next = randtes_BatchCompPtr()
+	s.Total = randint()
+
+	return s
+}
+
+func randtes_PageComp() testsource.Page[testsource.Comp] {
+	var s testsource.Page[testsource.Comp]
+	s.Items = randSlicetes_Comp()
+	s.Next = randtes_CompPtr()
+	s.Total = randint()
+
+	return s
+}
+
+func randtes_PageInt() testsource.Page[int] {
+	var s testsource.Page[int]
+	s.Items = randSliceint()
+	s.Next = randintPtr()
+	s.Total = randint()
+
+	return s
+}
+
+func randtes_PairStringEnumInt() testsource.Pair[string, testsource.EnumInt] {
+	var s testsource.Pair[string, testsource.EnumInt]
+	s.Key = randstring()
+	s.Value = randtes_EnumInt()
+
+	return s
+}
+
 func randtes_RecursiveType() testsource.RecursiveType {
 	var s testsource.RecursiveType
 	s.Children = randSlicetes_RecursiveType()
@@ -251,6 +447,17 @@ func randtes_RecursiveType() testsource.RecursiveType {
 	return s
 }
 
+func randtes_Rows() testsource.Rows {
+	return testsource.Rows(randSlicetes_RowsElem())
+}
+
+func randtes_RowsElem() struct{ X int } {
+	var s struct{ X int }
+	s.X = randint()
+
+	return s
+}
+
 func randtes_StructWithExternalRef() testsource.StructWithExternalRef {
 	var s testsource.StructWithExternalRef
 	s.Field1 = randsub_NamedSlice()
@@ -260,6 +467,114 @@ func randtes_StructWithExternalRef() testsource.StructWithExternalRef {
 	return s
 }
 
+func randtes_WithAnonymous() testsource.WithAnonymous {
+	var s testsource.WithAnonymous
+	s.Meta = randtes_WithAnonymousMeta()
+	s.Items = randSlicetes_WithAnonymousItems()
+	s.Pair = randtes_WithAnonymousPairPtr()
+	s.Rows = randtes_Rows()
+
+	return s
+}
+
+func randtes_WithAnonymousItems() struct {
+	Id    int64
+	Label string
+	Inner struct{ Ok bool }
+} {
+	var s struct {
+		Id    int64
+		Label string
+		Inner struct{ Ok bool }
+	}
+	s.Id = randint64()
+	s.Label = randstring()
+	s.Inner = randtes_WithAnonymousItemsInner()
+
+	return s
+}
+
+func randtes_WithAnonymousItemsInner() struct{ Ok bool } {
+	var s struct{ Ok bool }
+	s.Ok = randbool()
+
+	return s
+}
+
+func randtes_WithAnonymousMeta() struct {
+	Count int
+	Tags  []string "json:\"tags\""
+} {
+	var s struct {
+		Count int
+		Tags  []string "json:\"tags\""
+	}
+	s.Count = randint()
+	s.Tags = randSlicestring()
+
+	return s
+}
+
+func randtes_WithAnonymousPair() struct {
+	A string
+	B string
+} {
+	var s struct {
+		A string
+		B string
+	}
+	s.A = randstring()
+	s.B = randstring()
+
+	return s
+}
+
+func randtes_WithAnonymousPairPtr() *struct {
+	A string
+	B string
+} {
+	if rand.Intn(4) == 0 { // nil pointers are also valid
+		return nil
+	}
+	data := randtes_WithAnonymousPair()
+	return &data
+}
+
+func randtes_WithExternals() testsource.WithExternals {
+	var s testsource.WithExternals
+	s.Raw = randjso_RawMessage()
+	s.Addr = randnet_Addr()
+	s.Amount = randbig_IntPtr()
+	s.Addrs = randSlicenet_Addr()
+	s.Timeout = randtim_Duration()
+
+	return s
+}
+
+func randtes_WithGenerics() testsource.WithGenerics {
+	var s testsource.WithGenerics
+	s.Comps = randtes_PageComp()
+	s.Ints = randtes_PageInt()
+	s.Pair = randtes_PairStringEnumInt()
+	s.Names = randtes_BatchString()
+	s.Nested = randtes_PageBatchComp()
+
+	return s
+}
+
+func randtes_WithJSONOptions() testsource.WithJSONOptions {
+	var s testsource.WithJSONOptions
+	s.Name = randstring()
+	s.Count = randint()
+	s.Tags = randSlicestring()
+	s.Inner = randtes_Comp()
+	s.A = randuint8()
+	s.B = randuint8()
+	s.Comp = randtes_Comp()
+
+	return s
+}
+
 func randtes_WithOpaque() testsource.WithOpaque {
 	var s testsource.WithOpaque
 	s.F1 = randtes_StructWithExternalRef()
@@ -268,3 +583,23 @@ func randtes_WithOpaque() testsource.WithOpaque {
 
 	return s
 }
+
+func randtes_WithPointers() testsource.WithPointers {
+	var s testsource.WithPointers
+	s.Label = randstringPtr()
+	s.Count = randintPtr()
+	s.Deadline = randtTimePtr()
+	s.Inner = randtes_CompPtr()
+	s.Values = randSlicetes_EnumIntPtr()
+	s.Opt = randstringPtr()
+
+	return s
+}
+
+func randtim_Duration() time.Duration {
+	return randDuration()
+}
+
+func randuint8() uint8 {
+	return uint8(rand.Intn(1000000))
+}
@@ -0,0 +1,12 @@
+package test
+
+import (
+	"math/rand"
+	"time"
+)
+
+// randDuration is the custom generator configured for
+// the testsource.WithExternals.Timeout field
+func randDuration() time.Duration {
+	return time.Duration(rand.Int63n(int64(time.Hour)))
+}
@@ -5,6 +5,7 @@ package sqlcrud
 
 import (
 	"fmt"
+	"go/token"
 	"go/types"
 	"slices"
 	"strings"
@@ -42,14 +43,16 @@ var jsonValuer = gen.Declaration{
 // overriden in tests
 var pqImportPath = `"github.com/lib/pq"`
 
-func Generate(ana *an.Analysis, generateSets bool) []gen.Declaration {
-	tables := sql.SelectTables(ana)
+// Generate returns the CRUD functions for the tables contained in `ana.Source`.
+// The tables which are not supported are reported to [diags].
+func Generate(ana *an.Analysis, generateSets bool, diags *an.Diagnostics) []gen.Declaration {
+	tables := sql.SelectTables(ana, diags)
 	replacer := gen.NewTableNameReplacer(tables)
 	ctx := context{ana, replacer, make(gen.Cache), generateSets}
 
 	var decls []gen.Declaration
 	for _, ta := range tables {
-		decls = append(decls, ctx.generateTable(ta)...)
+		diags.Try(ta.Name.Obj().Pos(), func() { decls = append(decls, ctx.generateTable(ta)...) })
 	}
 
 	imports := ctx.cache.Imports()
@@ -117,9 +120,9 @@ func (ctx context) generateArrayConverter(key sql.ForeignKey) bool {
 // to the target package, along with its local name.
 // If not, it return false, assuming that the required methods are already implemented.
 func (ctx context) canImplementValuer(column sql.Column) (string, bool) {
-	named, ok := column.Field.Type.Type().(*types.Named)
+	named, ok := types.Unalias(columnType(column).Type()).(*types.Named)
 	if !ok {
-		panic(fmt.Sprintf("field %s (with type %T), is not named: sql.Valuer interface can't be implemented", column.Field.Field.Name(), column.SQLType))
+		panic(an.NewError(token.Position{}, an.CodeUnsupportedType, "field %s (with type %T) is not named: sql.Valuer interface can't be implemented", column.Field.Field.Name(), column.SQLType))
 	}
 	goTypeName := named.Obj().Name()
 	targetPath := ctx.ana.Pkg.PkgPath
@@ -201,6 +204,15 @@ func (ctx context) idArrayConverters(idTypeName string) gen.Declaration {
 	return out
 }
 
+// columnType returns the type of the column, without
+// pointer indirection : nil pointers are handled by the database/sql package
+func columnType(col sql.Column) an.Type {
+	if ptr, isPointer := col.Field.Type.(*an.Pointer); isPointer {
+		return ptr.Elem
+	}
+	return col.Field.Type
+}
+
 func isUnderlyingTime(ty an.Type) (*an.Time, bool) {
 	if named, isNamed := ty.(*an.Named); isNamed {
 		ty = named.Underlying
@@ -211,7 +223,7 @@ func isUnderlyingTime(ty an.Type) (*an.Time, bool) {
 
 // return the field name of sql.NullInt64 like types
 func isLocalNullInt64(col sql.Column) *types.Var {
-	named, ok := col.Field.Type.Type().(*types.Named)
+	named, ok := types.Unalias(columnType(col).Type()).(*types.Named)
 	if !ok {
 		return nil
 	}
@@ -243,7 +255,7 @@ func (ctx context) generateTable(ta sql.Table) (decls []gen.Declaration) {
 
 	// generate the value interface method
 	for _, col := range ta.Columns {
-		if ty, ok := isUnderlyingTime(col.Field.Type); ok {
+		if ty, ok := isUnderlyingTime(columnType(col)); ok {
 			goTypeName, isLocal := ctx.canImplementValuer(col)
 			if isLocal {
 				if ty.IsDate {
@@ -340,7 +352,23 @@ func (ctx context) generateJoinMethods(ta sql.Table, cols columnsCode) (out []ge
 		}
 
 		// lookup methods
-		if isNullable, nullableField := key.IsNullable(); isNullable {
+		if isNullable, nullableField := key.IsNullable(); isNullable && nullableField == "" { // pointer
+			// filter nil values
+			content += fmt.Sprintf(`
+			// %[1]ss returns the list of non nil %[1]s
+			// contained in this table.
+			// They are not garanteed to be distinct.
+			func (items %[2]ss) %[1]ss() []%[3]s {
+				var out []%[3]s
+				for _, target := range items {
+					if id := target.%[1]s; id != nil {
+						out = append(out, *id)
+					}
+				}
+				return out
+			}
+			`, fieldName, goTypeName, keyTypeName)
+		} else if isNullable {
 			// filter null values
 			content += fmt.Sprintf(`
 			// %[1]ss returns the list of non null %[1]s
@@ -615,7 +643,7 @@ func (ctx context) compositeConverters(goTypeName string, composite sql.Composit
 				s.%s = %s(val%s)
 			`, name, i, name, ctx.typeName(field.Field.Type()), name)
 		} else {
-			panic("unsupported field type in composite")
+			panic(an.NewError(token.Position{}, an.CodeUnsupportedType, "unsupported field type %s in composite %s", field.Field.Type(), goTypeName))
 		}
 	}
 
@@ -661,7 +689,7 @@ func (ctx context) arrayConverters(goTypeName string, arr sql.Array) gen.Declara
 		elemUnderlyingType = enum.Underlying()
 		elemKind = enum.Kind()
 	} else {
-		panic("unsupported array element")
+		panic(an.NewError(token.Position{}, an.CodeUnsupportedType, "unsupported array element %s", arr.A.Elem.Type()))
 	}
 
 	switch elemKind {
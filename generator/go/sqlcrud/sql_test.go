@@ -23,14 +23,14 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
-	ana = analysis.NewAnalysisFromFile(pkg, fn)
+	ana = analysis.NewAnalysisFromFile(pkg, fn, nil)
 
 	pqImportPath = `"github.com/benoitkugler/gomacro/analysis/sql/test/pq"`
 }
 
 func TestPrintID(t *testing.T) {
-	table1 := sql.NewTable(ana.Types[Lookup(ana.Pkg, "Table1")].(*analysis.Struct))
-	repas := sql.NewTable(ana.Types[Lookup(ana.Pkg, "Repas")].(*analysis.Struct))
+	table1 := sql.NewTable(ana.Types[Lookup(ana.Pkg, "Table1")].(*analysis.Struct), nil)
+	repas := sql.NewTable(ana.Types[Lookup(ana.Pkg, "Repas")].(*analysis.Struct), nil)
 
 	table1ID := table1.Columns[table1.Primary()].Field.Type.Type()
 	repasID := repas.Columns[repas.Primary()].Field.Type.Type()
@@ -41,7 +41,7 @@ func TestPrintID(t *testing.T) {
 }
 
 func TestGenerate(t *testing.T) {
-	decls := Generate(ana, true)
+	decls := Generate(ana, true, nil)
 	out := generator.WriteDeclarations(decls)
 
 	err := os.WriteFile(fileOut, []byte(out), os.ModePerm)
@@ -0,0 +1,36 @@
+// Package jsonschema generates JSON Schema (draft 2020-12)
+// definitions for Go types, as they are encoded by the encoding/json package
+// (and the union wrappers from generator/go/gounions).
+package jsonschema
+
+import (
+	"encoding/json"
+
+	an "github.com/benoitkugler/gomacro/analysis"
+)
+
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+type document struct {
+	Schema string             `json:"$schema"`
+	Defs   map[string]*Schema `json:"$defs"`
+}
+
+// Generate returns a JSON Schema document, with one entry
+// in $defs for each named type of the analysis source (and their dependencies).
+// Definitions may then be referenced with "<document URI>#/$defs/<Name>".
+// The types which are not supported are reported to [diags].
+func Generate(ana *an.Analysis, diags *an.Diagnostics) string {
+	sc := NewBuilder("#/$defs/", "jsonschema")
+	for _, typ := range ana.Source {
+		if ty := ana.Types[typ]; !an.IsGenericDeclaration(ty) { // only instantiations have a schema
+			diags.Try(an.DeclarationPos(typ), func() { sc.SchemaFor(ty) })
+		}
+	}
+
+	out, err := json.MarshalIndent(document{Schema: draft, Defs: sc.Defs}, "", "  ")
+	if err != nil { // should not happen since we control the document
+		panic(err)
+	}
+	return string(out) + "\n"
+}
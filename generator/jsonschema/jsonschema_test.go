@@ -0,0 +1,138 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"go/types"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	. "github.com/benoitkugler/gomacro/testutils"
+)
+
+func TestGenerate(t *testing.T) {
+	source := "../../testutils/testsource/defs.go"
+	pkg, err := analysis.LoadSource(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ana := analysis.NewAnalysisFromFile(pkg, source, nil)
+
+	code := Generate(ana, nil)
+
+	var doc document
+	err = json.Unmarshal([]byte(code), &doc)
+	Assert(t, err == nil)
+	defs := doc.Defs
+
+	// recursive types
+	Assert(t, defs["RecursiveType"].Properties["Children"].Items.Ref == "#/$defs/RecursiveType")
+	// unions
+	Assert(t, len(defs["ItfType"].OneOf) == 2)
+	Assert(t, defs["ItfType"].OneOf[0].Properties["Kind"].Const == "ConcretType1")
+	Assert(t, defs["ItfType"].OneOf[0].Properties["Data"].Ref == "#/$defs/ConcretType1")
+	// enums with labels
+	Assert(t, len(defs["EnumInt"].Enum) == 4)
+	Assert(t, defs["EnumInt"].OneOf[0].Title == "sdsd")
+	// time and date
+	Assert(t, defs["ComplexStruct"].Properties["Date"].Ref == "#/$defs/MyDate")
+	Assert(t, defs["MyDate"].Format == "date")
+	Assert(t, defs["ComplexStruct"].Properties["Time"].Format == "date-time")
+	// JSON tags and fixed arrays
+	Assert(t, defs["ComplexStruct"].Properties["with_tag"] != nil)
+	Assert(t, *defs["ComplexStruct"].Properties["F"].MinItems == 5)
+	// types from other packages are also registered
+	Assert(t, defs["NamedSlice"].Items.Ref == "#/$defs/Enum")
+
+	err = os.WriteFile("test/schemas.json", []byte(code), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpaque(t *testing.T) {
+	source := "../../testutils/testsource/defs.go"
+	pkg, err := analysis.LoadSource(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ana := analysis.NewAnalysisFromFile(pkg, source, nil)
+	ty := ana.Types[Lookup(pkg, "WithOpaque")]
+
+	sc := NewBuilder("#/$defs/", "typescript")
+	sc.SchemaFor(ty)
+	props := sc.Defs["WithOpaque"].Properties
+	Assert(t, props["F1"].Ref != "")
+	Assert(t, props["F2"].Ref == "" && props["F2"].Type == nil)
+}
+
+func TestJSONOptions(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := Lookup(pkg, "WithJSONOptions")
+	ana := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	sc := NewBuilder("#/$defs/", "typescript")
+	sc.SchemaFor(ana.Types[typ])
+	def := sc.Defs["WithJSONOptions"]
+	Assert(t, def.Properties["name"] != nil && def.Properties["Count"].Type == "string")
+	Assert(t, !slices.Contains(def.Required, "name") && !slices.Contains(def.Required, "tags"))
+	Assert(t, slices.Contains(def.Required, "Count") && slices.Contains(def.Required, "inner"))
+}
+
+func TestExternals(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := Lookup(pkg, "WithExternals")
+	ana := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	sc := NewBuilder("#/$defs/", "typescript")
+	sc.SchemaFor(ana.Types[typ])
+	def := sc.Defs["WithExternals"]
+	Assert(t, def.Properties["Raw"].Type == nil)
+	Assert(t, def.Properties["Addr"].Type == "string")
+	Assert(t, def.Properties["Addrs"].Type != nil && def.Properties["Timeout"].Type == "number")
+	Assert(t, len(sc.Defs) == 1) // no definitions for external types
+}
+
+func TestGenerics(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := Lookup(pkg, "WithGenerics")
+	ana := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	sc := NewBuilder("#/$defs/", "jsonschema")
+	sc.SchemaFor(ana.Types[typ])
+	props := sc.Defs["WithGenerics"].Properties
+	Assert(t, props["Comps"].Ref == "#/$defs/PageComp")
+	Assert(t, props["Nested"].Ref == "#/$defs/PageBatchComp")
+	Assert(t, sc.Defs["PageBatchComp"].Properties["Items"].Items.Ref == "#/$defs/BatchComp")
+	Assert(t, sc.Defs["PairStringEnumInt"].Properties["Value"].Ref == "#/$defs/EnumInt")
+	_, hasPage := sc.Defs["Page"]
+	Assert(t, !hasPage)
+}
+
+func TestAnonymousStructs(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := Lookup(pkg, "WithAnonymous")
+	ana := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	sc := NewBuilder("#/$defs/", "jsonschema")
+	sc.SchemaFor(ana.Types[typ])
+	props := sc.Defs["WithAnonymous"].Properties
+	Assert(t, props["meta"].Type == "object")
+	Assert(t, props["meta"].Properties["tags"].Type != "")
+	Assert(t, props["Items"].Items.Properties["Inner"].Properties["Ok"].Type == "boolean")
+	_, hasMeta := sc.Defs["WithAnonymousMeta"]
+	Assert(t, !hasMeta)
+}
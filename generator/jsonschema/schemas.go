@@ -0,0 +1,301 @@
+package jsonschema
+
+import (
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	an "github.com/benoitkugler/gomacro/analysis"
+)
+
+// This file defines how to convert analysis types
+// to JSON Schema objects.
+
+// Schema is a JSON Schema object, restricted to the keywords
+// required by the generator.
+type Schema struct {
+	Ref              string             `json:"$ref,omitempty"`
+	Type             any                `json:"type,omitempty"` // string or []string
+	Format           string             `json:"format,omitempty"`
+	ContentEncoding  string             `json:"contentEncoding,omitempty"`
+	Title            string             `json:"title,omitempty"`
+	Description      string             `json:"description,omitempty"`
+	Const            any                `json:"const,omitempty"`
+	Enum             []any              `json:"enum,omitempty"`
+	OneOf            []*Schema          `json:"oneOf,omitempty"`
+	Items            *Schema            `json:"items,omitempty"`
+	MinItems         *int               `json:"minItems,omitempty"`
+	MaxItems         *int               `json:"maxItems,omitempty"`
+	Properties       map[string]*Schema `json:"properties,omitempty"`
+	Required         []string           `json:"required,omitempty"`
+	PropertyNames    *Schema            `json:"propertyNames,omitempty"`
+	AdditionalProps  *Schema            `json:"additionalProperties,omitempty"`
+	Pattern          string             `json:"pattern,omitempty"`
+	ContentMediaType string             `json:"contentMediaType,omitempty"`
+}
+
+// nullable returns a schema accepting [s] or null
+func nullable(s *Schema) *Schema {
+	if typ, ok := s.Type.(string); ok && s.Ref == "" {
+		out := *s
+		out.Type = []string{typ, "null"}
+		return &out
+	}
+	return &Schema{OneOf: []*Schema{s, {Type: "null"}}}
+}
+
+// Builder accumulates the definitions of the named types,
+// so that they are shared between schemas.
+type Builder struct {
+	refPrefix string // such as #/$defs/
+	target    string // used to select opaque fields
+
+	// Defs stores the definitions referenced by the schemas
+	// returned by [SchemaFor]
+	Defs  map[string]*Schema
+	names map[types.Type]string // resolved definition names
+}
+
+// NewBuilder returns an empty builder. [refPrefix] is used to reference
+// definitions (such as "#/$defs/"), and [target] is checked against the struct
+// fields `gomacro-opaque` tag.
+func NewBuilder(refPrefix, target string) *Builder {
+	return &Builder{
+		refPrefix: refPrefix,
+		target:    target,
+		Defs:      make(map[string]*Schema),
+		names:     make(map[types.Type]string),
+	}
+}
+
+// defName returns a name for [named], which is unique
+// among the definitions.
+func (sc *Builder) defName(ty an.Type) string {
+	if name, has := sc.names[ty.Type()]; has {
+		return name
+	}
+
+	named := ty.Type().(*types.Named)
+	name := an.MonomorphizedName(named)     // instantiated generics are monomorphized
+	if _, isUsed := sc.Defs[name]; isUsed { // disambiguate with the package name
+		name = named.Obj().Pkg().Name() + "_" + name
+	}
+
+	sc.names[ty.Type()] = name
+	return name
+}
+
+// SchemaFor returns the schema for [ty], registering the required
+// definitions.
+// Named types (except time.Time) are always returned as references.
+func (sc *Builder) SchemaFor(ty an.Type) *Schema {
+	switch ty := ty.(type) {
+	case *an.Basic:
+		return schemaForBasic(ty)
+	case *an.Time:
+		return schemaForTime(ty)
+	case *an.Pointer:
+		return nullable(sc.SchemaFor(ty.Elem))
+	case *an.External:
+		return schemaForExternal(ty)
+	case *an.Array:
+		return sc.schemaForArray(ty)
+	case *an.Map:
+		return sc.schemaForMap(ty)
+	case *an.Struct:
+		if ty.Anonymous != nil { // inlined
+			return sc.schemaForStruct(ty)
+		}
+		return sc.ref(ty)
+	case *an.Named, *an.Enum, *an.Union:
+		return sc.ref(ty)
+	default:
+		panic(an.ExhaustiveTypeSwitch)
+	}
+}
+
+// ref registers the definition for [ty] (if needed)
+// and returns a reference to it
+func (sc *Builder) ref(ty an.Type) *Schema {
+	name := sc.defName(ty)
+	out := &Schema{Ref: sc.refPrefix + name}
+	if _, has := sc.Defs[name]; has {
+		return out
+	}
+
+	// register before recursing, to handle recursive types
+	def := new(Schema)
+	sc.Defs[name] = def
+
+	switch ty := ty.(type) {
+	case *an.Named:
+		*def = *sc.SchemaFor(ty.Underlying)
+	case *an.Enum:
+		*def = *schemaForEnum(ty)
+	case *an.Struct:
+		*def = *sc.schemaForStruct(ty)
+	case *an.Union:
+		*def = *sc.schemaForUnion(ty)
+	}
+	def.Title = name
+
+	return out
+}
+
+func schemaForBasic(ty *an.Basic) *Schema {
+	switch ty.Kind() {
+	case an.BKBool:
+		return &Schema{Type: "boolean"}
+	case an.BKInt:
+		format := "int64"
+		switch ty.B.Kind() {
+		case types.Int8, types.Int16, types.Int32, types.Uint8, types.Uint16:
+			format = "int32"
+		}
+		return &Schema{Type: "integer", Format: format}
+	case an.BKFloat:
+		if ty.B.Kind() == types.Float32 {
+			return &Schema{Type: "number", Format: "float"}
+		}
+		return &Schema{Type: "number", Format: "double"}
+	case an.BKString:
+		return &Schema{Type: "string"}
+	default:
+		panic(an.ExhaustiveBasicKindSwitch)
+	}
+}
+
+func schemaForTime(ty *an.Time) *Schema {
+	if ty.IsDate {
+		return &Schema{Type: "string", Format: "date"}
+	}
+	return &Schema{Type: "string", Format: "date-time"}
+}
+
+func schemaForExternal(ty *an.External) *Schema {
+	if ty.Mapping.JSON == an.JSONAny {
+		return &Schema{} // accept any value
+	}
+	return &Schema{Type: string(ty.Mapping.JSON)}
+}
+
+// isBytes returns true for []byte, which is encoded
+// as base64 string
+func isBytes(ty *an.Array) bool {
+	basic, isBasic := ty.Elem.(*an.Basic)
+	return isBasic && ty.Len == -1 && basic.B.Kind() == types.Byte
+}
+
+func (sc *Builder) schemaForArray(ty *an.Array) *Schema {
+	if isBytes(ty) {
+		return nullable(&Schema{Type: "string", ContentEncoding: "base64"})
+	}
+	out := &Schema{Type: "array", Items: sc.SchemaFor(ty.Elem)}
+	if ty.Len >= 0 {
+		out.MinItems, out.MaxItems = &ty.Len, &ty.Len
+		return out
+	}
+	// nil slices are marshalled as null
+	return nullable(out)
+}
+
+// return true for integer basic types and integer enums
+func isIntegerKey(ty an.Type) bool {
+	switch ty := ty.(type) {
+	case *an.Basic:
+		return ty.Kind() == an.BKInt
+	case *an.Enum:
+		return ty.IsInteger()
+	case *an.Named:
+		return isIntegerKey(ty.Underlying)
+	default:
+		return false
+	}
+}
+
+func (sc *Builder) schemaForMap(ty *an.Map) *Schema {
+	out := &Schema{Type: "object", AdditionalProps: sc.SchemaFor(ty.Elem)}
+	// JSON keys are always strings
+	if isIntegerKey(ty.Key) {
+		out.PropertyNames = &Schema{Pattern: "^-?[0-9]+$"}
+	}
+	// nil maps are marshalled as null
+	return nullable(out)
+}
+
+// constValue returns the Go value for [c], suitable
+// to be JSON encoded
+func constValue(c *types.Const) any {
+	val := c.Val()
+	switch val.Kind() {
+	case constant.String:
+		return constant.StringVal(val)
+	case constant.Int:
+		v, _ := constant.Int64Val(val)
+		return v
+	case constant.Float:
+		v, _ := constant.Float64Val(val)
+		return v
+	case constant.Bool:
+		return constant.BoolVal(val)
+	default:
+		panic(an.NewError(token.Position{}, an.CodeUnsupportedType, "unsupported constant %s (%s)", c.Name(), val))
+	}
+}
+
+// schemaForEnum returns the list of values, with their labels
+// exposed with 'oneOf' and 'title'
+func schemaForEnum(ty *an.Enum) *Schema {
+	out := schemaForBasic(&an.Basic{B: ty.Underlying()})
+	out.Format = ""
+	for _, member := range ty.Members {
+		if !member.Const.Exported() {
+			continue
+		}
+		value := constValue(member.Const)
+		out.Enum = append(out.Enum, value)
+		out.OneOf = append(out.OneOf, &Schema{Const: value, Title: member.Comment, Description: member.Const.Name()})
+	}
+	return out
+}
+
+func (sc *Builder) schemaForStruct(ty *an.Struct) *Schema {
+	out := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, field := range ty.Fields {
+		if !field.Exported() {
+			continue
+		}
+
+		name := field.JSONName()
+		switch {
+		case field.IsOpaqueFor(sc.target):
+			out.Properties[name] = &Schema{} // accept anything
+		case field.AsString(): // the value is encoded in a JSON string
+			out.Properties[name] = &Schema{Type: "string"}
+		default:
+			out.Properties[name] = sc.SchemaFor(field.Type)
+		}
+		if !field.OmitEmpty() {
+			out.Required = append(out.Required, name)
+		}
+	}
+	return out
+}
+
+// schemaForUnion uses the {Kind, Data} wrapper
+// used by gounions
+func (sc *Builder) schemaForUnion(ty *an.Union) *Schema {
+	out := &Schema{}
+	for _, member := range ty.Members {
+		kind := an.LocalName(member)
+		out.OneOf = append(out.OneOf, &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"Kind": {Const: kind},
+				"Data": sc.SchemaFor(member),
+			},
+			Required: []string{"Kind", "Data"},
+		})
+	}
+	return out
+}
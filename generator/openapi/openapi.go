@@ -0,0 +1,207 @@
+// Package openapi generates an OpenAPI 3.1 document
+// describing the endpoints found by the httpapi package.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"github.com/benoitkugler/gomacro/analysis/httpapi"
+	"github.com/benoitkugler/gomacro/generator/jsonschema"
+)
+
+// Info is the general information about the API.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]pathItem `json:"paths"`
+	Components components          `json:"components"`
+}
+
+type components struct {
+	Schemas map[string]*jsonschema.Schema `json:"schemas"`
+}
+
+// pathItem maps lower case HTTP methods to operations
+type pathItem map[string]*operation
+
+type operation struct {
+	OperationID string              `json:"operationId"`
+	Parameters  []parameter         `json:"parameters,omitempty"`
+	RequestBody *requestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]response `json:"responses"`
+}
+
+type parameter struct {
+	Name     string             `json:"name"`
+	In       string             `json:"in"` // query or path
+	Required bool               `json:"required"`
+	Schema   *jsonschema.Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema   *jsonschema.Schema  `json:"schema"`
+	Encoding map[string]encoding `json:"encoding,omitempty"`
+}
+
+type encoding struct {
+	ContentType string `json:"contentType"`
+}
+
+type header struct {
+	Schema *jsonschema.Schema `json:"schema"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Headers     map[string]header    `json:"headers,omitempty"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+// Generate returns an OpenAPI 3.1 document (in JSON format, which
+// is also valid YAML) describing the given endpoints.
+// The types used by the endpoints are added as components.
+func Generate(api []httpapi.Endpoint, info Info) string {
+	doc := document{
+		OpenAPI: "3.1.0",
+		Info:    info,
+		Paths:   make(map[string]pathItem),
+	}
+	sc := jsonschema.NewBuilder("#/components/schemas/", "openapi")
+
+	for _, endpoint := range api {
+		path, pathParams := pathTemplate(endpoint)
+		item := doc.Paths[path]
+		if item == nil {
+			item = make(pathItem)
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(endpoint.Method)] = newOperation(sc, endpoint, pathParams)
+	}
+
+	doc.Components.Schemas = sc.Defs
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil { // should not happen since we control the document
+		panic(err)
+	}
+	return string(out) + "\n"
+}
+
+// pathTemplate converts the path of [endpoint], using any of the
+// supported router syntaxes, to the OpenAPI syntax (/users/{id}/files/{wildcard}),
+// returning the path parameters in order
+func pathTemplate(endpoint httpapi.Endpoint) (string, []httpapi.TypedParam) {
+	var (
+		path   strings.Builder
+		params []httpapi.TypedParam
+	)
+	for _, chunk := range endpoint.PathChunks() {
+		if chunk.Param == nil {
+			path.WriteString(chunk.Static)
+			continue
+		}
+		param := *chunk.Param
+		if param.Name == "*" { // anonymous Echo wildcard
+			param.Name = "wildcard"
+		}
+		path.WriteString("{" + param.Name + "}")
+		params = append(params, param)
+	}
+	return path.String(), params
+}
+
+func newOperation(sc *jsonschema.Builder, endpoint httpapi.Endpoint, pathParams []httpapi.TypedParam) *operation {
+	ct := endpoint.Contract
+	out := &operation{OperationID: ct.Name}
+
+	for _, param := range pathParams {
+		schema := &jsonschema.Schema{Type: "string"}
+		if param.Type != nil {
+			schema = sc.SchemaFor(param.Type)
+		}
+		out.Parameters = append(out.Parameters, parameter{
+			Name: param.Name, In: "path", Required: true, Schema: schema,
+		})
+	}
+	for _, param := range ct.InputQueryParams {
+		out.Parameters = append(out.Parameters, parameter{
+			Name: param.Name, In: "query", Schema: sc.SchemaFor(param.Type),
+		})
+	}
+
+	if ct.InputBody != nil {
+		out.RequestBody = &requestBody{
+			Required: true,
+			Content:  map[string]mediaType{"application/json": {Schema: sc.SchemaFor(ct.InputBody)}},
+		}
+	} else if !ct.InputForm.IsZero() {
+		out.RequestBody = formBody(sc, ct.InputForm)
+	}
+
+	out.Responses = map[string]response{"200": successResponse(sc, ct)}
+	for _, code := range ct.ErrorResponses() {
+		out.Responses[strconv.Itoa(code)] = errorResponse(sc, code, ct.Responses[code])
+	}
+
+	return out
+}
+
+// formBody returns a multipart description of [form]
+func formBody(sc *jsonschema.Builder, form httpapi.Form) *requestBody {
+	schema := &jsonschema.Schema{Type: "object", Properties: make(map[string]*jsonschema.Schema)}
+	var enc map[string]encoding
+	if form.File != "" {
+		schema.Properties[form.File] = &jsonschema.Schema{Type: "string", Format: "binary"}
+		schema.Required = append(schema.Required, form.File)
+	}
+	for _, value := range form.ValueNames {
+		schema.Properties[value] = &jsonschema.Schema{Type: "string"}
+		schema.Required = append(schema.Required, value)
+	}
+	if param := form.JSON; param.Name != "" {
+		schema.Properties[param.Name] = sc.SchemaFor(param.Type)
+		schema.Required = append(schema.Required, param.Name)
+		enc = map[string]encoding{param.Name: {ContentType: "application/json"}}
+	}
+	return &requestBody{
+		Required: true,
+		Content:  map[string]mediaType{"multipart/form-data": {Schema: schema, Encoding: enc}},
+	}
+}
+
+func successResponse(sc *jsonschema.Builder, ct httpapi.Contract) response {
+	out := response{Description: http.StatusText(http.StatusOK)}
+	switch {
+	case ct.IsReturnBlob:
+		out.Headers = map[string]header{"Content-Disposition": {Schema: &jsonschema.Schema{Type: "string"}}}
+		out.Content = map[string]mediaType{"application/octet-stream": {Schema: &jsonschema.Schema{Type: "string", Format: "binary"}}}
+	case ct.IsReturnStream: // one JSON value per line
+		out.Content = map[string]mediaType{"application/x-ndjson": {Schema: sc.SchemaFor(ct.Return)}}
+	case ct.Return != nil:
+		out.Content = map[string]mediaType{"application/json": {Schema: sc.SchemaFor(ct.Return)}}
+	}
+	return out
+}
+
+// errorResponse describes the error response [code], with an optional payload
+func errorResponse(sc *jsonschema.Builder, code int, payload analysis.Type) response {
+	out := response{Description: http.StatusText(code)}
+	if payload != nil {
+		out.Content = map[string]mediaType{"application/json": {Schema: sc.SchemaFor(payload)}}
+	}
+	return out
+}
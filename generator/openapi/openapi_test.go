@@ -0,0 +1,95 @@
+package openapi
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"github.com/benoitkugler/gomacro/analysis/httpapi"
+	. "github.com/benoitkugler/gomacro/testutils"
+)
+
+func TestPathTemplate(t *testing.T) {
+	endpoint := func(url string, params ...httpapi.TypedParam) httpapi.Endpoint {
+		return httpapi.Endpoint{Url: url, Contract: httpapi.Contract{PathParams: params}}
+	}
+	id := httpapi.TypedParam{Name: "id", Type: analysis.Int}
+
+	path, params := pathTemplate(endpoint("/users/:id/files/*", id, httpapi.TypedParam{Name: "*", Type: analysis.String}))
+	Assert(t, path == "/users/{id}/files/{wildcard}")
+	Assert(t, len(params) == 2 && params[0] == id && params[1].Name == "wildcard")
+
+	path, params = pathTemplate(endpoint("/users"))
+	Assert(t, path == "/users" && len(params) == 0)
+
+	path, params = pathTemplate(endpoint("/users/{id}/files/{path...}", id, httpapi.TypedParam{Name: "path", Type: analysis.String}))
+	Assert(t, path == "/users/{id}/files/{path}")
+	Assert(t, len(params) == 2 && params[0] == id && params[1].Name == "path")
+
+	// Gin wildcard
+	path, params = pathTemplate(endpoint("/files/*path", httpapi.TypedParam{Name: "path", Type: analysis.String}))
+	Assert(t, path == "/files/{path}")
+	Assert(t, len(params) == 1 && params[0].Name == "path")
+
+	// Chi regular expression
+	path, params = pathTemplate(endpoint("/items/{id:[0-9]+}/details", id))
+	Assert(t, path == "/items/{id}/details")
+	Assert(t, len(params) == 1 && params[0] == id)
+}
+
+func TestTypedPathParams(t *testing.T) {
+	api := []httpapi.Endpoint{{Url: "/items/{id:[0-9]+}", Method: "GET", Contract: httpapi.Contract{
+		Name:       "getItem",
+		PathParams: []httpapi.TypedParam{{Name: "id", Type: analysis.Int}},
+	}}}
+	var doc document
+	err := json.Unmarshal([]byte(Generate(api, Info{})), &doc)
+	Assert(t, err == nil)
+	params := doc.Paths["/items/{id}"]["get"].Parameters
+	Assert(t, len(params) == 1 && params[0].Name == "id" && params[0].In == "path")
+	Assert(t, params[0].Schema.Type == "integer", params[0].Schema)
+}
+
+func TestGenerate(t *testing.T) {
+	fn := "../../analysis/httpapi/test/routes.go"
+	pack, err := analysis.LoadSource(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apis := httpapi.ParseEcho(pack, abs, nil)
+	code := Generate(apis, Info{Title: "Test API", Version: "1.0.0"})
+
+	var doc document
+	err = json.Unmarshal([]byte(code), &doc)
+	Assert(t, err == nil)
+	Assert(t, doc.Paths["/with_param/{param}"]["put"].Parameters[0].In == "path")
+	Assert(t, doc.Paths["/download"]["post"].Responses["200"].Content["application/octet-stream"].Schema != nil)
+	Assert(t, doc.Paths["/with_json_stream"]["get"].Responses["200"].Content["application/x-ndjson"].Schema != nil)
+	Assert(t, doc.Paths["/special_param_value/{default}/route"]["delete"].RequestBody.Content["multipart/form-data"].Schema != nil)
+
+	err = os.WriteFile("test/openapi.json", []byte(code), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	api := []httpapi.Endpoint{{Url: "/items", Method: "POST", Contract: httpapi.Contract{
+		Name:      "createItem",
+		Responses: map[int]analysis.Type{200: nil, 404: nil, 409: analysis.String},
+	}}}
+	var doc document
+	err := json.Unmarshal([]byte(Generate(api, Info{})), &doc)
+	Assert(t, err == nil)
+	responses := doc.Paths["/items"]["post"].Responses
+	Assert(t, len(responses) == 3)
+	Assert(t, responses["404"].Description == "Not Found" && responses["404"].Content == nil)
+	Assert(t, responses["409"].Content["application/json"].Schema != nil)
+}
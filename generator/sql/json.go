@@ -25,8 +25,12 @@ func codeFor(ty an.Type, cache gen.Cache) []gen.Declaration {
 	switch ty := ty.(type) {
 	case *an.Basic, *an.Time:
 		return []gen.Declaration{codeForBasicOrTime(ty)}
+	case *an.External:
+		return []gen.Declaration{codeForExternal(ty)}
 	case *an.Named:
 		return codeFor(ty.Underlying, cache)
+	case *an.Pointer:
+		return codeForPointer(ty, cache)
 	case *an.Enum:
 		return []gen.Declaration{codeForEnum(ty)}
 	case *an.Map:
@@ -60,11 +64,16 @@ func nameFromKind(kind an.BasicKind) string {
 func typeID(ty an.Type) string {
 	switch ty := ty.(type) {
 	case *an.Pointer:
-		panic("pointers not handled by the SQL generator")
+		return "nullable_" + typeID(ty.Elem)
 	case *an.Basic: // may be named or not
 		return nameFromKind(ty.Kind())
 	case *an.Time:
 		return "string" // saved as ISO string
+	case *an.External:
+		if ty.Mapping.JSON == an.JSONAny {
+			return "any"
+		}
+		return string(ty.Mapping.JSON)
 	case *an.Array:
 		as := "array_"
 		if ty.Len >= 0 {
@@ -75,7 +84,9 @@ func typeID(ty an.Type) string {
 		return "map_" + typeID(ty.Elem) // JSON map keys are always strings
 	case *an.Named: // shortcut to underlying
 		return typeID(ty.Underlying)
-	case *an.Struct, *an.Enum, *an.Union: // these types are always named
+	case *an.Struct: // anonymous structs use their synthesized name
+		return idFromNamed(ty.Name)
+	case *an.Enum, *an.Union: // these types are always named
 		return idFromNamed(ty.Type().(*types.Named))
 	default:
 		panic(an.ExhaustiveTypeSwitch + fmt.Sprintf(": %T", ty))
@@ -87,7 +98,7 @@ func idFromNamed(typ *types.Named) string {
 	if len(pkg) > 4 {
 		pkg = pkg[:4]
 	}
-	return pkg + "_" + typ.Obj().Name()
+	return pkg + "_" + an.MonomorphizedName(typ) // instantiated generics are monomorphized
 }
 
 // functionName returns the name of the validation function
@@ -114,7 +125,7 @@ const (
 	IMMUTABLE;`
 )
 
-// ty should be Basic or Time
+// ty should be Basic, Time or External
 func codeForBasicOrTime(ty an.Type) gen.Declaration {
 	name := typeID(ty)
 	s := gen.Declaration{
@@ -124,6 +135,25 @@ func codeForBasicOrTime(ty an.Type) gen.Declaration {
 	return s
 }
 
+const vAny = `
+	CREATE OR REPLACE FUNCTION %s (data jsonb)
+		RETURNS boolean
+		AS $$
+	BEGIN
+		RETURN TRUE;
+	END;
+	$$
+	LANGUAGE 'plpgsql'
+	IMMUTABLE;`
+
+// external types are validated according to their JSON kind
+func codeForExternal(ty *an.External) gen.Declaration {
+	if ty.Mapping.JSON == an.JSONAny {
+		return gen.Declaration{ID: functionName(ty), Content: fmt.Sprintf(vAny, functionName(ty))}
+	}
+	return codeForBasicOrTime(ty)
+}
+
 const vEnum = `
 CREATE OR REPLACE FUNCTION %s (data jsonb)
 	RETURNS boolean
@@ -187,6 +217,29 @@ func codeForArray(ty *an.Array, cache gen.Cache) []gen.Declaration {
 	return out
 }
 
+const vPointer = `
+	CREATE OR REPLACE FUNCTION %s (data jsonb)
+		RETURNS boolean
+		AS $$
+	BEGIN
+		IF jsonb_typeof(data) = 'null' THEN -- accept null value coming from nil pointers
+			RETURN TRUE;
+		END IF;
+		RETURN %s(data);
+	END;
+	$$
+	LANGUAGE 'plpgsql'
+	IMMUTABLE;`
+
+func codeForPointer(ty *an.Pointer, cache gen.Cache) []gen.Declaration {
+	out := codeFor(ty.Elem, cache) // recursion
+	fn, elemFuncName := functionName(ty), functionName(ty.Elem)
+	content := fmt.Sprintf(vPointer, fn, elemFuncName)
+
+	out = append(out, gen.Declaration{ID: fn, Content: content})
+	return out
+}
+
 const vMap = `
 	CREATE OR REPLACE FUNCTION %s (data jsonb)
 		RETURNS boolean
@@ -239,10 +292,18 @@ func codeForStruct(ty *an.Struct, cache gen.Cache) (out []gen.Declaration) {
 			continue
 		}
 
-		out = append(out, codeFor(f.Type, cache)...) // recursion
+		fieldType := f.Type
+		if f.AsString() { // the value is encoded in a JSON string
+			fieldType = an.String
+		}
+		out = append(out, codeFor(fieldType, cache)...) // recursion
 		fieldName := f.JSONName()
 		keys = append(keys, fmt.Sprintf("'%s'", fieldName))
-		checks = append(checks, fmt.Sprintf("AND %s(data->'%s')", functionName(f.Type), fieldName))
+		check := fmt.Sprintf("%s(data->'%s')", functionName(fieldType), fieldName)
+		if f.OmitEmpty() { // the key may be absent
+			check = fmt.Sprintf("(NOT data ? '%s' OR %s)", fieldName, check)
+		}
+		checks = append(checks, "AND "+check)
 	}
 	keyList := "KEY IN (" + strings.Join(keys, ", ") + ")"
 	if len(keys) == 0 {
@@ -20,34 +20,21 @@ const (
 
 // Generate returns the SQL statements required to create
 // the tables contained in `Source`, defined by Go structs.
-func Generate(ana *an.Analysis) []gen.Declaration {
+// The tables which are not supported are reported to [diags].
+func Generate(ana *an.Analysis, diags *an.Diagnostics) []gen.Declaration {
 	var decls []gen.Declaration
 
-	tables := sql.SelectTables(ana)
+	tables := sql.SelectTables(ana, diags)
 
 	nameReplacer := gen.NewTableNameReplacer(tables)
 
 	var constraints []string
 	for _, ta := range tables {
-
-		// table creation / JSON validations
-		decls = append(decls, generateTable(ta)...)
-
-		// explicit (user provided) constraints
-		for _, constraint := range ta.CustomConstraints {
-			constraints = append(constraints, generateCustomConstraint(ana, ta, nameReplacer, constraint))
-		}
-
-		// implicit constraints (like foreign keys)
-		for _, foreign := range ta.ForeignKeys() {
-			constraints = append(constraints, generateForeignConstraint(ta.TableName(), foreign))
-		}
-
-		for _, column := range ta.Columns {
-			if value, ok := column.Field.IsSQLGuard(); ok {
-				constraints = append(constraints, generateQuardConstraint(ana, ta, column, value)...)
-			}
-		}
+		diags.Try(ta.Name.Obj().Pos(), func() {
+			tableDecls, tableConstraints := generateTableAndConstraints(ana, ta, nameReplacer)
+			decls = append(decls, tableDecls...)
+			constraints = append(constraints, tableConstraints...)
+		})
 	}
 
 	decls = append(decls,
@@ -65,6 +52,28 @@ func Generate(ana *an.Analysis) []gen.Declaration {
 	return decls
 }
 
+func generateTableAndConstraints(ana *an.Analysis, ta sql.Table, nameReplacer gen.TableNameReplacer) (decls []gen.Declaration, constraints []string) {
+	// table creation / JSON validations
+	decls = generateTable(ta)
+
+	// explicit (user provided) constraints
+	for _, constraint := range ta.CustomConstraints {
+		constraints = append(constraints, generateCustomConstraint(ana, ta, nameReplacer, constraint))
+	}
+
+	// implicit constraints (like foreign keys)
+	for _, foreign := range ta.ForeignKeys() {
+		constraints = append(constraints, generateForeignConstraint(ta.TableName(), foreign))
+	}
+
+	for _, column := range ta.Columns {
+		if value, ok := column.Field.IsSQLGuard(); ok {
+			constraints = append(constraints, generateQuardConstraint(ana, ta, column, value)...)
+		}
+	}
+	return decls, constraints
+}
+
 func generateQuardConstraint(ana *an.Analysis, ta sql.Table, column sql.Column, value string) []string {
 	value = gen.ReplaceEnums(ana, value)
 	return []string{
@@ -129,10 +138,14 @@ func generateTable(ta sql.Table) []gen.Declaration {
 
 			colName := f.Field.Field.Name()
 			id := prefixDeclJSONConstraint + jsonFuncName + gen.SQLTableName(ta.TableName()) + colName
+			check := fmt.Sprintf("%s(%s)", jsonFuncName, colName)
+			if f.IsNullable() {
+				check = fmt.Sprintf("%s IS NULL OR %s", colName, check)
+			}
 			decls = append(decls, jsonDecls...)
 			decls = append(decls, gen.Declaration{
 				ID:       id,
-				Content:  fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s_gomacro CHECK (%s(%s));", gen.SQLTableName(ta.TableName()), colName, jsonFuncName, colName),
+				Content:  fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s_gomacro CHECK (%s);", gen.SQLTableName(ta.TableName()), colName, check),
 				Priority: false,
 			})
 		}
@@ -174,26 +187,27 @@ func createStmt(col sql.Column, isPrimary bool) (string, []gen.Declaration) {
 }
 
 func typeConstraint(field sql.Column) string {
+	notNull := "NOT NULL"
+	if field.IsNullable() {
+		notNull = ""
+	}
 	switch ty := field.SQLType.(type) {
 	case sql.Builtin:
-		if ty.IsNullable() {
-			return ""
-		}
-		return "NOT NULL"
+		return notNull
 	case sql.Enum:
 		if _, ok := field.Field.IsSQLGuard(); ok { // the guard already add a CHECK value = ref
-			return "NOT NULL"
+			return notNull
 		}
-		return fmt.Sprintf(" CHECK (%s IN %s) NOT NULL", field.Field.Field.Name(), enumTuple(ty.E))
+		return fmt.Sprintf(" CHECK (%s IN %s) %s", field.Field.Field.Name(), enumTuple(ty.E), notNull)
 	case sql.Array:
 		if L := ty.A.Len; L >= 0 {
-			return fmt.Sprintf(" CHECK (array_length(%s, 1) = %d) NOT NULL", field.Field.Field.Name(), L)
+			return fmt.Sprintf(" CHECK (array_length(%s, 1) = %d) %s", field.Field.Field.Name(), L, notNull)
 		}
 		return ""
 	case sql.Composite:
-		return "NOT NULL"
+		return notNull
 	case sql.JSON:
-		return "NOT NULL"
+		return notNull
 	default:
 		panic(sql.ExhaustiveSQLTypeSwitch)
 	}
@@ -1,11 +1,15 @@
 package sql
 
 import (
+	"go/types"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/benoitkugler/gomacro/analysis"
+	"github.com/benoitkugler/gomacro/analysis/sql"
 	"github.com/benoitkugler/gomacro/generator"
+	"github.com/benoitkugler/gomacro/testutils"
 )
 
 func TestCreate(t *testing.T) {
@@ -14,9 +18,20 @@ func TestCreate(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	an := analysis.NewAnalysisFromFile(pkg, source)
+	an := analysis.NewAnalysisFromFile(pkg, source, nil)
 
-	decls := Generate(an)
+	decls := Generate(an, nil)
+
+	// also check the JSON validations of the test source types
+	fixtures, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"WithJSONOptions", "WithExternals", "WithGenerics", "WithAnonymous"} {
+		typ := testutils.Lookup(fixtures, name)
+		ty := analysis.NewAnalysisFromTypes(fixtures, []types.Type{typ}, nil).Types[typ]
+		decls = append(decls, generateTable(sql.NewTable(ty.(*analysis.Struct), nil))...)
+	}
 
 	out := generator.WriteDeclarations(decls)
 	generated := "test/create.sql"
@@ -30,3 +45,119 @@ func TestCreate(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestJSONOptions(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithJSONOptions")
+	an := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	code := generator.WriteDeclarations(codeFor(an.Types[typ], make(generator.Cache)))
+	for _, expected := range []string{
+		"KEY IN ('name', 'Count', 'tags', 'inner', 'A', 'B', 'comp')",
+		"AND (NOT data ? 'name' OR gomacro_validate_json_string(data->'name'))",
+		"AND gomacro_validate_json_string(data->'Count')",
+		"AND (NOT data ? 'tags' OR gomacro_validate_json_array_string(data->'tags'))",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestPointers(t *testing.T) {
+	source := "../../analysis/sql/test/models.go"
+	pkg, err := analysis.LoadSource(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	an := analysis.NewAnalysisFromFile(pkg, source, nil)
+
+	code := generator.WriteDeclarations(Generate(an, nil))
+	for _, expected := range []string{
+		"IdRepas integer ,",
+		"Label text ,",
+		"Flow smallint  CHECK (Flow IN (0, 1, 2)) ,",
+		"ADD FOREIGN KEY(IdRepas) REFERENCES repass ON DELETE SET NULL;",
+		"CHECK (Params IS NULL OR gomacro_validate_json_test_Settings(Params));",
+		"AND gomacro_validate_json_nullable_number(data->'Limit')",
+		"IF jsonb_typeof(data) = 'null' THEN -- accept null value coming from nil pointers",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestExternals(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithExternals")
+	an := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	code := generator.WriteDeclarations(generateTable(sql.NewTable(an.Types[typ].(*analysis.Struct), nil)))
+	for _, expected := range []string{
+		"Raw jsonb NOT NULL,",
+		"Addr inet NOT NULL,",
+		"Amount numeric ,",
+		"Timeout interval NOT NULL",
+		"bool_and( gomacro_validate_json_string(value) )",
+		"is_valid boolean := jsonb_typeof(data) = 'string';",
+		"CHECK (gomacro_validate_json_array_string(Addrs))",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestGenerics(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithGenerics")
+	an := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	code := generator.WriteDeclarations(generateTable(sql.NewTable(an.Types[typ].(*analysis.Struct), nil)))
+	for _, expected := range []string{
+		"CREATE OR REPLACE FUNCTION gomacro_validate_json_test_PageComp (data jsonb)",
+		"CREATE OR REPLACE FUNCTION gomacro_validate_json_test_PageInt (data jsonb)",
+		"CREATE OR REPLACE FUNCTION gomacro_validate_json_test_PairStringEnumInt (data jsonb)",
+		"CREATE OR REPLACE FUNCTION gomacro_validate_json_test_PageBatchComp (data jsonb)",
+		"AND gomacro_validate_json_array_test_Comp(data->'Items')",
+		"CHECK (gomacro_validate_json_test_PageComp(Comps));",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestAnonymousStructs(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithAnonymous")
+	an := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil)
+
+	code := generator.WriteDeclarations(generateTable(sql.NewTable(an.Types[typ].(*analysis.Struct), nil)))
+	for _, expected := range []string{
+		"Meta jsonb NOT NULL,",
+		"CREATE OR REPLACE FUNCTION gomacro_validate_json_test_WithAnonymousMeta (data jsonb)",
+		"KEY IN ('Count', 'tags')",
+		"AND gomacro_validate_json_test_WithAnonymousItemsInner(data->'Inner');",
+		"CHECK (gomacro_validate_json_array_test_WithAnonymousItems(Items));",
+		"CHECK (Pair IS NULL OR gomacro_validate_json_test_WithAnonymousPair(Pair));",
+		"CHECK (gomacro_validate_json_array_test_RowsElem(Rows));",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
@@ -1,12 +1,12 @@
 -- Code genererated by gomacro/generator/sql. DO NOT EDIT.
+
+DROP TYPE IF EXISTS Comp;
+
+CREATE TYPE Comp AS (A smallint, B smallint);
+
 DROP TYPE IF EXISTS Composite;
 
-CREATE TYPE Composite AS (
-    A integer,
-    B smallint,
-    C integer,
-    D boolean
-);
+CREATE TYPE Composite AS (A integer, B smallint, C integer, D boolean);
 
 CREATE TABLE exercices (
     Id serial PRIMARY KEY,
@@ -56,7 +56,8 @@ CREATE TABLE question_tags (
     IdQuestion integer NOT NULL
 );
 
-CREATE TABLE repass ( Order text NOT NULL,
+CREATE TABLE repass (
+    Order text NOT NULL,
     Id serial PRIMARY KEY,
     V smallint CHECK (V IN (0, 1, 2)) NOT NULL
 );
@@ -79,165 +80,191 @@ CREATE TABLE table1s (
 
 CREATE TABLE with_optional_times (
     Id serial PRIMARY KEY,
-    Deadine timestamp(0
-) with time zone NOT NULL,
-    DeadineOpt timestamp(0
-) with time zone
+    Deadine timestamp (0) with time zone NOT NULL,
+    DeadineOpt timestamp (0) with time zone
 );
 
--- constraints
-ALTER TABLE table1s
-    ADD FOREIGN KEY (Ex1) REFERENCES repass;
-
-ALTER TABLE table1s
-    ADD FOREIGN KEY (Ex2) REFERENCES repass;
-
-ALTER TABLE table1s
-    ADD FOREIGN KEY (L) REFERENCES links;
-
-ALTER TABLE table1s
-    ADD FOREIGN KEY (Other) REFERENCES repass;
-
-ALTER TABLE table1s
-    ADD FOREIGN KEY (OptKey) REFERENCES questions;
-
-ALTER TABLE table1s
-    ALTER COLUMN guard SET DEFAULT 0
-    /* LocalEnum.A */
-;
-
-ALTER TABLE table1s
-    ADD CHECK (guard = 0
-    /* LocalEnum.A */);
+CREATE TABLE with_pointerss (
+    Id serial PRIMARY KEY,
+    IdRepas integer,
+    Label text,
+    Deadline timestamp (0) with time zone,
+    Flow smallint CHECK (Flow IN (0, 1, 2)),
+    Params jsonb
+);
 
-ALTER TABLE repass
-    ADD CHECK (V = 0
-    /* LocalEnum.A */
-        OR V = 1
-        /* LocalEnum.B */);
+CREATE TABLE with_anonymouss (
+    Meta jsonb NOT NULL,
+    Items jsonb NOT NULL,
+    Pair jsonb,
+    Rows jsonb NOT NULL
+);
 
-ALTER TABLE links
-    ADD FOREIGN KEY (Repas) REFERENCES repass;
+CREATE TABLE with_externalss (
+    Raw jsonb NOT NULL,
+    Addr inet NOT NULL,
+    Amount numeric,
+    Addrs jsonb NOT NULL,
+    Timeout interval NOT NULL
+);
 
-ALTER TABLE questions
-    ADD FOREIGN KEY (NeedExercice) REFERENCES exercices;
+CREATE TABLE with_genericss (
+    Comps jsonb NOT NULL,
+    Ints jsonb NOT NULL,
+    Pair jsonb NOT NULL,
+    Names text[],
+    Nested jsonb NOT NULL
+);
 
-ALTER TABLE question_tags
-    ADD UNIQUE (IdQuestion, Tag);
+CREATE TABLE with_json_optionss (
+    Name text NOT NULL,
+    Count integer NOT NULL,
+    Tags text[],
+    Inner Comp NOT NULL,
+    A smallint NOT NULL,
+    B smallint NOT NULL,
+    Comp Comp NOT NULL
+);
 
+-- constraints
+ALTER TABLE table1s ADD FOREIGN KEY(Ex1) REFERENCES repass;
+ALTER TABLE table1s ADD FOREIGN KEY(Ex2) REFERENCES repass;
+ALTER TABLE table1s ADD FOREIGN KEY(L) REFERENCES links;
+ALTER TABLE table1s ADD FOREIGN KEY(Other) REFERENCES repass;
+ALTER TABLE table1s ADD FOREIGN KEY(OptKey) REFERENCES questions;
+ALTER TABLE table1s ALTER COLUMN guard SET DEFAULT 0 /* LocalEnum.A */;
+ALTER TABLE table1s ADD CHECK(guard = 0 /* LocalEnum.A */);
+ALTER TABLE repass ADD CHECK (V = 0 /* LocalEnum.A */ OR V = 1 /* LocalEnum.B */);
+ALTER TABLE links ADD FOREIGN KEY(Repas) REFERENCES repass;
+ALTER TABLE questions ADD FOREIGN KEY(NeedExercice) REFERENCES exercices;
+ALTER TABLE question_tags ADD UNIQUE(IdQuestion, Tag);
 CREATE UNIQUE INDEX index_name ON question_tags (Tag);
-
-ALTER TABLE question_tags
-    ADD FOREIGN KEY (IdQuestion) REFERENCES questions ON DELETE CASCADE;
-
-ALTER TABLE exercice_questions
-    ADD PRIMARY KEY (IdExercice, INDEX);
-
-ALTER TABLE exercice_questions
-    ADD FOREIGN KEY (IdExercice) REFERENCES exercices ON DELETE CASCADE;
-
-ALTER TABLE exercice_questions
-    ADD FOREIGN KEY (IdQuestion) REFERENCES questions;
-
-ALTER TABLE progressions
-    ADD UNIQUE (Id, IdExercice);
-
-ALTER TABLE progression_questions
-    ADD UNIQUE (IdProgression, INDEX);
-
-ALTER TABLE progression_questions
-    ADD FOREIGN KEY (IdExercice, INDEX) REFERENCES exercice_questionss ON DELETE CASCADE;
-
-ALTER TABLE progression_questions
-    ADD FOREIGN KEY (IdProgression, IdExercice) REFERENCES progressionss (Id, IdExercice) ON DELETE CASCADE;
-
-ALTER TABLE progression_questions
-    ADD FOREIGN KEY (IdProgression) REFERENCES progressions ON DELETE CASCADE;
-
-ALTER TABLE progression_questions
-    ADD FOREIGN KEY (IdExercice) REFERENCES exercices ON DELETE CASCADE;
+ALTER TABLE question_tags ADD FOREIGN KEY(IdQuestion) REFERENCES questions ON DELETE CASCADE;
+ALTER TABLE exercice_questions ADD PRIMARY KEY (IdExercice, Index);
+ALTER TABLE exercice_questions ADD FOREIGN KEY(IdExercice) REFERENCES exercices ON DELETE CASCADE;
+ALTER TABLE exercice_questions ADD FOREIGN KEY(IdQuestion) REFERENCES questions;
+ALTER TABLE progressions ADD UNIQUE(Id, IdExercice);
+ALTER TABLE progression_questions ADD UNIQUE(IdProgression, Index);
+ALTER TABLE progression_questions ADD FOREIGN KEY (IdExercice, Index) REFERENCES exercice_questionss ON DELETE CASCADE;
+ALTER TABLE progression_questions ADD FOREIGN KEY (IdProgression, IdExercice) REFERENCES progressionss (Id, IdExercice) ON DELETE CASCADE;
+ALTER TABLE progression_questions ADD FOREIGN KEY(IdProgression) REFERENCES progressions ON DELETE CASCADE;
+ALTER TABLE progression_questions ADD FOREIGN KEY(IdExercice) REFERENCES exercices ON DELETE CASCADE;
+ALTER TABLE with_pointerss ADD FOREIGN KEY(IdRepas) REFERENCES repass ON DELETE SET NULL;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_array_5_array_5_boolean (data jsonb)
     RETURNS boolean
     AS $$
 BEGIN
-    IF jsonb_typeof(data) != 'array' THEN
-        RETURN FALSE;
-    END IF;
-    RETURN (
-        SELECT
-            bool_and(gomacro_validate_json_array_5_boolean (value))
-        FROM
-            jsonb_array_elements(data))
+    IF jsonb_typeof(data) != 'array' THEN RETURN FALSE; END IF;
+    RETURN (SELECT bool_and(gomacro_validate_json_array_5_boolean(value)) FROM jsonb_array_elements(data))
         AND jsonb_array_length(data) = 5;
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_array_5_boolean (data jsonb)
     RETURNS boolean
     AS $$
 BEGIN
-    IF jsonb_typeof(data) != 'array' THEN
-        RETURN FALSE;
-    END IF;
-    RETURN (
-        SELECT
-            bool_and(gomacro_validate_json_boolean (value))
-        FROM
-            jsonb_array_elements(data))
+    IF jsonb_typeof(data) != 'array' THEN RETURN FALSE; END IF;
+    RETURN (SELECT bool_and(gomacro_validate_json_boolean(value)) FROM jsonb_array_elements(data))
         AND jsonb_array_length(data) = 5;
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_array_array_test_Comp (data jsonb)
+    RETURNS boolean
+    AS $$
+BEGIN
+    IF jsonb_typeof(data) = 'null' THEN RETURN TRUE; END IF;
+    IF jsonb_typeof(data) != 'array' THEN RETURN FALSE; END IF;
+    IF jsonb_array_length(data) = 0 THEN RETURN TRUE; END IF;
+    RETURN (SELECT bool_and(gomacro_validate_json_array_test_Comp(value)) FROM jsonb_array_elements(data));
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_array_number (data jsonb)
     RETURNS boolean
     AS $$
 BEGIN
-    IF jsonb_typeof(data) = 'null' THEN
-        RETURN TRUE;
-    END IF;
-    IF jsonb_typeof(data) != 'array' THEN
-        RETURN FALSE;
-    END IF;
-    IF jsonb_array_length(data) = 0 THEN
-        RETURN TRUE;
-    END IF;
-    RETURN (
-        SELECT
-            bool_and(gomacro_validate_json_number (value))
-        FROM
-            jsonb_array_elements(data));
+    IF jsonb_typeof(data) = 'null' THEN RETURN TRUE; END IF;
+    IF jsonb_typeof(data) != 'array' THEN RETURN FALSE; END IF;
+    IF jsonb_array_length(data) = 0 THEN RETURN TRUE; END IF;
+    RETURN (SELECT bool_and(gomacro_validate_json_number(value)) FROM jsonb_array_elements(data));
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_array_string (data jsonb)
+    RETURNS boolean
+    AS $$
+BEGIN
+    IF jsonb_typeof(data) = 'null' THEN RETURN TRUE; END IF;
+    IF jsonb_typeof(data) != 'array' THEN RETURN FALSE; END IF;
+    IF jsonb_array_length(data) = 0 THEN RETURN TRUE; END IF;
+    RETURN (SELECT bool_and(gomacro_validate_json_string(value)) FROM jsonb_array_elements(data));
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_array_test_Comp (data jsonb)
+    RETURNS boolean
+    AS $$
+BEGIN
+    IF jsonb_typeof(data) = 'null' THEN RETURN TRUE; END IF;
+    IF jsonb_typeof(data) != 'array' THEN RETURN FALSE; END IF;
+    IF jsonb_array_length(data) = 0 THEN RETURN TRUE; END IF;
+    RETURN (SELECT bool_and(gomacro_validate_json_test_Comp(value)) FROM jsonb_array_elements(data));
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_array_test_ItfType (data jsonb)
     RETURNS boolean
     AS $$
 BEGIN
-    IF jsonb_typeof(data) = 'null' THEN
-        RETURN TRUE;
-    END IF;
-    IF jsonb_typeof(data) != 'array' THEN
-        RETURN FALSE;
-    END IF;
-    IF jsonb_array_length(data) = 0 THEN
-        RETURN TRUE;
-    END IF;
-    RETURN (
-        SELECT
-            bool_and(gomacro_validate_json_test_ItfType (value))
-        FROM
-            jsonb_array_elements(data));
+    IF jsonb_typeof(data) = 'null' THEN RETURN TRUE; END IF;
+    IF jsonb_typeof(data) != 'array' THEN RETURN FALSE; END IF;
+    IF jsonb_array_length(data) = 0 THEN RETURN TRUE; END IF;
+    RETURN (SELECT bool_and(gomacro_validate_json_test_ItfType(value)) FROM jsonb_array_elements(data));
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_array_test_RowsElem (data jsonb)
+    RETURNS boolean
+    AS $$
+BEGIN
+    IF jsonb_typeof(data) = 'null' THEN RETURN TRUE; END IF;
+    IF jsonb_typeof(data) != 'array' THEN RETURN FALSE; END IF;
+    IF jsonb_array_length(data) = 0 THEN RETURN TRUE; END IF;
+    RETURN (SELECT bool_and(gomacro_validate_json_test_RowsElem(value)) FROM jsonb_array_elements(data));
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_array_test_WithAnonymousItems (data jsonb)
+    RETURNS boolean
+    AS $$
+BEGIN
+    IF jsonb_typeof(data) = 'null' THEN RETURN TRUE; END IF;
+    IF jsonb_typeof(data) != 'array' THEN RETURN FALSE; END IF;
+    IF jsonb_array_length(data) = 0 THEN RETURN TRUE; END IF;
+    RETURN (SELECT bool_and(gomacro_validate_json_test_WithAnonymousItems(value)) FROM jsonb_array_elements(data));
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_boolean (data jsonb)
     RETURNS boolean
@@ -251,46 +278,75 @@ BEGIN
     RETURN is_valid;
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_map_boolean (data jsonb)
     RETURNS boolean
     AS $$
 BEGIN
-    IF jsonb_typeof(data) = 'null' THEN
-        -- accept null value coming from nil maps
+    IF jsonb_typeof(data) = 'null' THEN -- accept null value coming from nil maps
         RETURN TRUE;
     END IF;
     RETURN jsonb_typeof(data) = 'object'
-        AND (
-            SELECT
-                bool_and(gomacro_validate_json_boolean (value))
-            FROM
-                jsonb_each(data));
+        AND (SELECT bool_and(gomacro_validate_json_boolean(value)) FROM jsonb_each(data));
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_map_number (data jsonb)
     RETURNS boolean
     AS $$
 BEGIN
-    IF jsonb_typeof(data) = 'null' THEN
-        -- accept null value coming from nil maps
+    IF jsonb_typeof(data) = 'null' THEN -- accept null value coming from nil maps
         RETURN TRUE;
     END IF;
     RETURN jsonb_typeof(data) = 'object'
-        AND (
-            SELECT
-                bool_and(gomacro_validate_json_number (value))
-            FROM
-                jsonb_each(data));
+        AND (SELECT bool_and(gomacro_validate_json_number(value)) FROM jsonb_each(data));
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_nullable_array_test_Comp (data jsonb)
+    RETURNS boolean
+    AS $$
+BEGIN
+    IF jsonb_typeof(data) = 'null' THEN -- accept null value coming from nil pointers
+        RETURN TRUE;
+    END IF;
+    RETURN gomacro_validate_json_array_test_Comp(data);
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_nullable_number (data jsonb)
+    RETURNS boolean
+    AS $$
+BEGIN
+    IF jsonb_typeof(data) = 'null' THEN -- accept null value coming from nil pointers
+        RETURN TRUE;
+    END IF;
+    RETURN gomacro_validate_json_number(data);
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_nullable_test_Comp (data jsonb)
+    RETURNS boolean
+    AS $$
+BEGIN
+    IF jsonb_typeof(data) = 'null' THEN -- accept null value coming from nil pointers
+        RETURN TRUE;
+    END IF;
+    RETURN gomacro_validate_json_test_Comp(data);
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_number (data jsonb)
     RETURNS boolean
@@ -304,8 +360,8 @@ BEGIN
     RETURN is_valid;
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_string (data jsonb)
     RETURNS boolean
@@ -319,8 +375,8 @@ BEGIN
     RETURN is_valid;
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_subp_StructWithComment (data jsonb)
     RETURNS boolean
@@ -331,17 +387,35 @@ BEGIN
     IF jsonb_typeof(data) != 'object' THEN
         RETURN FALSE;
     END IF;
-    is_valid := (
-        SELECT
-            bool_and(KEY IN ('A'))
-        FROM
-            jsonb_each(data))
-        AND gomacro_validate_json_number (data -> 'A');
+    is_valid := (SELECT bool_and(
+            KEY IN ('A')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_number(data->'A');
+    RETURN is_valid;
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_test_Comp (data jsonb)
+    RETURNS boolean
+    AS $$
+DECLARE
+    is_valid boolean;
+BEGIN
+    IF jsonb_typeof(data) != 'object' THEN
+        RETURN FALSE;
+    END IF;
+    is_valid := (SELECT bool_and(
+            KEY IN ('A', 'B')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_number(data->'A')
+        AND gomacro_validate_json_number(data->'B');
     RETURN is_valid;
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_test_ComplexStruct (data jsonb)
     RETURNS boolean
@@ -352,30 +426,28 @@ BEGIN
     IF jsonb_typeof(data) != 'object' THEN
         RETURN FALSE;
     END IF;
-    is_valid := (
-        SELECT
-            bool_and(KEY IN ('with_tag', 'Time', 'B', 'Value', 'L', 'A', 'E', 'E2', 'Date', 'F', 'Imported', 'EnumMap', 'OptID1', 'OptID2'))
-        FROM
-            jsonb_each(data))
-        AND gomacro_validate_json_map_number (data -> 'with_tag')
-        AND gomacro_validate_json_string (data -> 'Time')
-        AND gomacro_validate_json_string (data -> 'B')
-        AND gomacro_validate_json_test_ItfType (data -> 'Value')
-        AND gomacro_validate_json_array_test_ItfType (data -> 'L')
-        AND gomacro_validate_json_number (data -> 'A')
-        AND gomacro_validate_json_test_EnumInt (data -> 'E')
-        AND gomacro_validate_json_test_EnumUInt (data -> 'E2')
-        AND gomacro_validate_json_string (data -> 'Date')
-        AND gomacro_validate_json_array_5_array_5_boolean (data -> 'F')
-        AND gomacro_validate_json_subp_StructWithComment (data -> 'Imported')
-        AND gomacro_validate_json_map_boolean (data -> 'EnumMap')
-        AND gomacro_validate_json_test_Generic (data -> 'OptID1')
-        AND gomacro_validate_json_test_Generic (data -> 'OptID2');
+    is_valid := (SELECT bool_and(
+            KEY IN ('with_tag', 'Time', 'B', 'Value', 'L', 'A', 'E', 'E2', 'Date', 'F', 'Imported', 'EnumMap', 'OptID1', 'OptID2')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_map_number(data->'with_tag')
+        AND gomacro_validate_json_string(data->'Time')
+        AND gomacro_validate_json_string(data->'B')
+        AND gomacro_validate_json_test_ItfType(data->'Value')
+        AND gomacro_validate_json_array_test_ItfType(data->'L')
+        AND gomacro_validate_json_number(data->'A')
+        AND gomacro_validate_json_test_EnumInt(data->'E')
+        AND gomacro_validate_json_test_EnumUInt(data->'E2')
+        AND gomacro_validate_json_string(data->'Date')
+        AND gomacro_validate_json_array_5_array_5_boolean(data->'F')
+        AND gomacro_validate_json_subp_StructWithComment(data->'Imported')
+        AND gomacro_validate_json_map_boolean(data->'EnumMap')
+        AND gomacro_validate_json_test_GenericIdCamp(data->'OptID1')
+        AND gomacro_validate_json_test_GenericIdFile(data->'OptID2');
     RETURN is_valid;
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_test_ConcretType1 (data jsonb)
     RETURNS boolean
@@ -386,18 +458,16 @@ BEGIN
     IF jsonb_typeof(data) != 'object' THEN
         RETURN FALSE;
     END IF;
-    is_valid := (
-        SELECT
-            bool_and(KEY IN ('List2', 'V'))
-        FROM
-            jsonb_each(data))
-        AND gomacro_validate_json_array_number (data -> 'List2')
-        AND gomacro_validate_json_number (data -> 'V');
+    is_valid := (SELECT bool_and(
+            KEY IN ('List2', 'V')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_array_number(data->'List2')
+        AND gomacro_validate_json_number(data->'V');
     RETURN is_valid;
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_test_ConcretType2 (data jsonb)
     RETURNS boolean
@@ -408,24 +478,21 @@ BEGIN
     IF jsonb_typeof(data) != 'object' THEN
         RETURN FALSE;
     END IF;
-    is_valid := (
-        SELECT
-            bool_and(KEY IN ('D'))
-        FROM
-            jsonb_each(data))
-        AND gomacro_validate_json_number (data -> 'D');
+    is_valid := (SELECT bool_and(
+            KEY IN ('D')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_number(data->'D');
     RETURN is_valid;
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_test_EnumInt (data jsonb)
     RETURNS boolean
     AS $$
 DECLARE
-    is_valid boolean := jsonb_typeof(data) = 'number'
-    AND data::int IN (0, 1, 2, 4);
+    is_valid boolean := jsonb_typeof(data) = 'number' AND data::int IN (0, 1, 2, 4);
 BEGIN
     IF NOT is_valid THEN
         RAISE WARNING '% is not a test_EnumInt', data;
@@ -433,15 +500,14 @@ BEGIN
     RETURN is_valid;
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
 CREATE OR REPLACE FUNCTION gomacro_validate_json_test_EnumUInt (data jsonb)
     RETURNS boolean
     AS $$
 DECLARE
-    is_valid boolean := jsonb_typeof(data) = 'number'
-    AND data::int IN (0, 1, 2, 3, 4);
+    is_valid boolean := jsonb_typeof(data) = 'number' AND data::int IN (0, 1, 2, 3, 4);
 BEGIN
     IF NOT is_valid THEN
         RAISE WARNING '% is not a test_EnumUInt', data;
@@ -449,10 +515,10 @@ BEGIN
     RETURN is_valid;
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
-CREATE OR REPLACE FUNCTION gomacro_validate_json_test_Generic (data jsonb)
+CREATE OR REPLACE FUNCTION gomacro_validate_json_test_GenericIdCamp (data jsonb)
     RETURNS boolean
     AS $$
 DECLARE
@@ -461,40 +527,265 @@ BEGIN
     IF jsonb_typeof(data) != 'object' THEN
         RETURN FALSE;
     END IF;
-    is_valid := (
-        SELECT
-            bool_and(KEY IN ('Id'))
-        FROM
-            jsonb_each(data))
-        AND gomacro_validate_json_number (data -> 'Id');
+    is_valid := (SELECT bool_and(
+            KEY IN ('Id')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_number(data->'Id');
     RETURN is_valid;
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
-CREATE OR REPLACE FUNCTION gomacro_validate_json_test_ItfType (data jsonb)
+CREATE OR REPLACE FUNCTION gomacro_validate_json_test_GenericIdFile (data jsonb)
     RETURNS boolean
     AS $$
+DECLARE
+    is_valid boolean;
 BEGIN
-    IF jsonb_typeof(data) != 'object' OR jsonb_typeof(data -> 'Kind') != 'string' OR jsonb_typeof(data -> 'Data') = 'null' THEN
+    IF jsonb_typeof(data) != 'object' THEN
         RETURN FALSE;
     END IF;
-    CASE WHEN data ->> 'Kind' = 'ConcretType1' THEN
-        RETURN gomacro_validate_json_test_ConcretType1 (data -> 'Data');
-    WHEN data ->> 'Kind' = 'ConcretType2' THEN
-        RETURN gomacro_validate_json_test_ConcretType2 (data -> 'Data');
-    ELSE
+    is_valid := (SELECT bool_and(
+            KEY IN ('Id')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_number(data->'Id');
+    RETURN is_valid;
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_test_ItfType (data jsonb)
+    RETURNS boolean
+    AS $$
+BEGIN
+    IF jsonb_typeof(data) != 'object' OR jsonb_typeof(data->'Kind') != 'string' OR jsonb_typeof(data->'Data') = 'null' THEN
         RETURN FALSE;
+    END IF;
+    CASE
+    WHEN data->>'Kind' = 'ConcretType1' THEN
+        RETURN gomacro_validate_json_test_ConcretType1(data->'Data');
+    WHEN data->>'Kind' = 'ConcretType2' THEN
+        RETURN gomacro_validate_json_test_ConcretType2(data->'Data');
+    ELSE RETURN FALSE;
     END CASE;
 END;
 $$
-LANGUAGE 'plpgsql'
-IMMUTABLE;
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_test_PageBatchComp (data jsonb)
+    RETURNS boolean
+    AS $$
+DECLARE
+    is_valid boolean;
+BEGIN
+    IF jsonb_typeof(data) != 'object' THEN
+        RETURN FALSE;
+    END IF;
+    is_valid := (SELECT bool_and(
+            KEY IN ('Items', 'Next', 'Total')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_array_array_test_Comp(data->'Items')
+        AND gomacro_validate_json_nullable_array_test_Comp(data->'Next')
+        AND gomacro_validate_json_number(data->'Total');
+    RETURN is_valid;
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_test_PageComp (data jsonb)
+    RETURNS boolean
+    AS $$
+DECLARE
+    is_valid boolean;
+BEGIN
+    IF jsonb_typeof(data) != 'object' THEN
+        RETURN FALSE;
+    END IF;
+    is_valid := (SELECT bool_and(
+            KEY IN ('Items', 'Next', 'Total')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_array_test_Comp(data->'Items')
+        AND gomacro_validate_json_nullable_test_Comp(data->'Next')
+        AND gomacro_validate_json_number(data->'Total');
+    RETURN is_valid;
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_test_PageInt (data jsonb)
+    RETURNS boolean
+    AS $$
+DECLARE
+    is_valid boolean;
+BEGIN
+    IF jsonb_typeof(data) != 'object' THEN
+        RETURN FALSE;
+    END IF;
+    is_valid := (SELECT bool_and(
+            KEY IN ('Items', 'Next', 'Total')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_array_number(data->'Items')
+        AND gomacro_validate_json_nullable_number(data->'Next')
+        AND gomacro_validate_json_number(data->'Total');
+    RETURN is_valid;
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_test_PairStringEnumInt (data jsonb)
+    RETURNS boolean
+    AS $$
+DECLARE
+    is_valid boolean;
+BEGIN
+    IF jsonb_typeof(data) != 'object' THEN
+        RETURN FALSE;
+    END IF;
+    is_valid := (SELECT bool_and(
+            KEY IN ('Key', 'Value')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_string(data->'Key')
+        AND gomacro_validate_json_test_EnumInt(data->'Value');
+    RETURN is_valid;
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_test_RowsElem (data jsonb)
+    RETURNS boolean
+    AS $$
+DECLARE
+    is_valid boolean;
+BEGIN
+    IF jsonb_typeof(data) != 'object' THEN
+        RETURN FALSE;
+    END IF;
+    is_valid := (SELECT bool_and(
+            KEY IN ('X')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_number(data->'X');
+    RETURN is_valid;
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_test_Settings (data jsonb)
+    RETURNS boolean
+    AS $$
+DECLARE
+    is_valid boolean;
+BEGIN
+    IF jsonb_typeof(data) != 'object' THEN
+        RETURN FALSE;
+    END IF;
+    is_valid := (SELECT bool_and(
+            KEY IN ('Limit', 'Label')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_nullable_number(data->'Limit')
+        AND gomacro_validate_json_string(data->'Label');
+    RETURN is_valid;
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
-ALTER TABLE exercices
-    ADD CONSTRAINT Parameters_gomacro CHECK (gomacro_validate_json_map_boolean (Parameters));
+CREATE OR REPLACE FUNCTION gomacro_validate_json_test_WithAnonymousItems (data jsonb)
+    RETURNS boolean
+    AS $$
+DECLARE
+    is_valid boolean;
+BEGIN
+    IF jsonb_typeof(data) != 'object' THEN
+        RETURN FALSE;
+    END IF;
+    is_valid := (SELECT bool_and(
+            KEY IN ('Id', 'Label', 'Inner')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_number(data->'Id')
+        AND gomacro_validate_json_string(data->'Label')
+        AND gomacro_validate_json_test_WithAnonymousItemsInner(data->'Inner');
+    RETURN is_valid;
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION gomacro_validate_json_test_WithAnonymousItemsInner (data jsonb)
+    RETURNS boolean
+    AS $$
+DECLARE
+    is_valid boolean;
+BEGIN
+    IF jsonb_typeof(data) != 'object' THEN
+        RETURN FALSE;
+    END IF;
+    is_valid := (SELECT bool_and(
+            KEY IN ('Ok')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_boolean(data->'Ok');
+    RETURN is_valid;
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
-ALTER TABLE questions
-    ADD CONSTRAINT Page_gomacro CHECK (gomacro_validate_json_test_ComplexStruct (Page));
+CREATE OR REPLACE FUNCTION gomacro_validate_json_test_WithAnonymousMeta (data jsonb)
+    RETURNS boolean
+    AS $$
+DECLARE
+    is_valid boolean;
+BEGIN
+    IF jsonb_typeof(data) != 'object' THEN
+        RETURN FALSE;
+    END IF;
+    is_valid := (SELECT bool_and(
+            KEY IN ('Count', 'tags')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_number(data->'Count')
+        AND gomacro_validate_json_array_string(data->'tags');
+    RETURN is_valid;
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
 
+CREATE OR REPLACE FUNCTION gomacro_validate_json_test_WithAnonymousPair (data jsonb)
+    RETURNS boolean
+    AS $$
+DECLARE
+    is_valid boolean;
+BEGIN
+    IF jsonb_typeof(data) != 'object' THEN
+        RETURN FALSE;
+    END IF;
+    is_valid := (SELECT bool_and(
+            KEY IN ('A', 'B')
+        ) FROM jsonb_each(data))
+        AND gomacro_validate_json_string(data->'A')
+        AND gomacro_validate_json_string(data->'B');
+    RETURN is_valid;
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+ALTER TABLE with_externalss ADD CONSTRAINT Addrs_gomacro CHECK (gomacro_validate_json_array_string(Addrs));
+ALTER TABLE with_anonymouss ADD CONSTRAINT Rows_gomacro CHECK (gomacro_validate_json_array_test_RowsElem(Rows));
+ALTER TABLE with_anonymouss ADD CONSTRAINT Items_gomacro CHECK (gomacro_validate_json_array_test_WithAnonymousItems(Items));
+ALTER TABLE exercices ADD CONSTRAINT Parameters_gomacro CHECK (gomacro_validate_json_map_boolean(Parameters));
+ALTER TABLE questions ADD CONSTRAINT Page_gomacro CHECK (gomacro_validate_json_test_ComplexStruct(Page));
+ALTER TABLE with_genericss ADD CONSTRAINT Nested_gomacro CHECK (gomacro_validate_json_test_PageBatchComp(Nested));
+ALTER TABLE with_genericss ADD CONSTRAINT Comps_gomacro CHECK (gomacro_validate_json_test_PageComp(Comps));
+ALTER TABLE with_genericss ADD CONSTRAINT Ints_gomacro CHECK (gomacro_validate_json_test_PageInt(Ints));
+ALTER TABLE with_genericss ADD CONSTRAINT Pair_gomacro CHECK (gomacro_validate_json_test_PairStringEnumInt(Pair));
+ALTER TABLE with_pointerss ADD CONSTRAINT Params_gomacro CHECK (Params IS NULL OR gomacro_validate_json_test_Settings(Params));
+ALTER TABLE with_anonymouss ADD CONSTRAINT Meta_gomacro CHECK (gomacro_validate_json_test_WithAnonymousMeta(Meta));
+ALTER TABLE with_anonymouss ADD CONSTRAINT Pair_gomacro CHECK (Pair IS NULL OR gomacro_validate_json_test_WithAnonymousPair(Pair));
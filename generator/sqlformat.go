@@ -0,0 +1,355 @@
+package generator
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// FormatSQL is a minimal SQL pretty-printer, targeting the statements emitted by
+// the generator/sql package : the line breaks are kept, while the spaces are
+// normalized and the lines are re-indented, following the parentheses, the
+// statements and the PL/pgSQL blocks of the function bodies.
+// It returns a [FormatError] if [content] is not well formed (unterminated strings
+// or comments, unbalanced parentheses or blocks).
+func FormatSQL(filename string, content []byte) ([]byte, error) {
+	tokens, err := tokenizeSQL(string(content))
+	if err != nil {
+		return nil, err.toFormatError(filename, content)
+	}
+	out, err := printSQL(tokens)
+	if err != nil {
+		return nil, err.toFormatError(filename, content)
+	}
+	return out, nil
+}
+
+const sqlIndent = "    "
+
+type sqlTokenKind uint8
+
+const (
+	sqlWord    sqlTokenKind = iota // keyword, identifier, number or operator
+	sqlPunct                       // one of ( ) , ;
+	sqlString                      // quoted string or identifier, or nested dollar quoted string
+	sqlComment                     // -- line comment or /* block comment */
+	sqlDollar                      // $tag$ delimiting a function body
+)
+
+type sqlToken struct {
+	kind     sqlTokenKind
+	text     string
+	line     int  // 1-based, of the start of the token
+	space    bool // the token is preceded by spaces
+	newlines int  // number of line breaks before the token
+}
+
+func (tok sqlToken) is(text string) bool { return tok.kind == sqlPunct && tok.text == text }
+
+type sqlSyntaxError struct {
+	line int
+	msg  string
+}
+
+func (se *sqlSyntaxError) toFormatError(filename string, content []byte) error {
+	return newFormatError(filename, content, se.line, errors.New(se.msg))
+}
+
+var reDollarTag = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// isSQLDelimiter returns true if a word can't continue with [s]
+func isSQLDelimiter(s string) bool {
+	return strings.IndexByte(" \t\r\n(),;'\"", s[0]) != -1 || strings.HasPrefix(s, "--") || strings.HasPrefix(s, "/*")
+}
+
+func tokenizeSQL(src string) ([]sqlToken, *sqlSyntaxError) {
+	var (
+		out      []sqlToken
+		line     = 1
+		space    bool
+		newlines int
+		bodyTag  string // delimiter of the current function body, if any
+		bodyLine int
+	)
+	for i := 0; i < len(src); {
+		start, kind := i, sqlWord
+		switch c := src[i]; {
+		case c == '\n':
+			line++
+			newlines++
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			space = true
+			i++
+			continue
+		case strings.HasPrefix(src[i:], "--"):
+			kind = sqlComment
+			if end := strings.IndexByte(src[i:], '\n'); end != -1 {
+				i += end
+			} else {
+				i = len(src)
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			kind = sqlComment
+			end := strings.Index(src[i+2:], "*/")
+			if end == -1 {
+				return nil, &sqlSyntaxError{line, "unterminated comment"}
+			}
+			i += 2 + end + 2
+		case c == '\'' || c == '"':
+			kind = sqlString
+			i++
+			for {
+				end := strings.IndexByte(src[i:], c)
+				if end == -1 {
+					return nil, &sqlSyntaxError{line, "unterminated quoted string"}
+				}
+				i += end + 1
+				if i < len(src) && src[i] == c { // escaped quote
+					i++
+					continue
+				}
+				break
+			}
+		case c == '(' || c == ')' || c == ',' || c == ';':
+			kind = sqlPunct
+			i++
+		default:
+			if tag := reDollarTag.FindString(src[i:]); tag != "" {
+				switch bodyTag {
+				case "": // start of a function body
+					kind, bodyTag, bodyLine = sqlDollar, tag, line
+					i += len(tag)
+				case tag: // end of the function body
+					kind, bodyTag = sqlDollar, ""
+					i += len(tag)
+				default: // nested dollar quoted string
+					kind = sqlString
+					end := strings.Index(src[i+len(tag):], tag)
+					if end == -1 {
+						return nil, &sqlSyntaxError{line, "unterminated dollar quoted string " + tag}
+					}
+					i += 2*len(tag) + end
+				}
+				break
+			}
+			for i < len(src) && !isSQLDelimiter(src[i:]) {
+				i++
+			}
+		}
+
+		text := src[start:i]
+		if kind == sqlComment {
+			text = strings.TrimRight(text, " \t\r")
+		}
+		out = append(out, sqlToken{kind: kind, text: text, line: line, space: space, newlines: newlines})
+		line += strings.Count(text, "\n")
+		space, newlines = false, 0
+	}
+	if bodyTag != "" {
+		return nil, &sqlSyntaxError{bodyLine, "unterminated function body " + bodyTag}
+	}
+	return out, nil
+}
+
+// sqlPrinter stores the state of the pretty-printer
+type sqlPrinter struct {
+	out strings.Builder
+
+	parens []int // line of the opened parentheses
+
+	statementOpen  bool // a top level statement is started
+	statementLines int  // number of lines of the current top level statement
+	lastMultiline  bool // the last top level statement spanned several lines
+
+	inBody          bool // in a function body
+	blocks          int  // depth of the PL/pgSQL blocks
+	inDeclare       bool // in a DECLARE section, closed by BEGIN
+	plStart         bool // the next word starts a PL/pgSQL statement
+	plContinues     bool // the current PL/pgSQL statement continues on the next line
+	previousWord    string
+	last            sqlToken
+	lastSignificant sqlToken // ignoring comments
+}
+
+// plOpeners are the keywords ending a line without
+// continuing the statement on the next line
+var plOpeners = map[string]bool{"BEGIN": true, "DECLARE": true, "THEN": true, "ELSE": true, "LOOP": true, "CASE": true}
+
+// plDedent are the keywords indented as their enclosing block
+var plDedent = map[string]bool{"END": true, "ELSE": true, "ELSIF": true, "WHEN": true, "EXCEPTION": true}
+
+func printSQL(tokens []sqlToken) ([]byte, *sqlSyntaxError) {
+	var pr sqlPrinter
+	for len(tokens) != 0 {
+		end := 1
+		for end < len(tokens) && tokens[end].newlines == 0 {
+			end++
+		}
+		if err := pr.printLine(tokens[:end]); err != nil {
+			return nil, err
+		}
+		tokens = tokens[end:]
+	}
+	if len(pr.parens) != 0 {
+		return nil, &sqlSyntaxError{pr.parens[len(pr.parens)-1], "unclosed parenthesis"}
+	}
+	if pr.out.Len() != 0 {
+		pr.out.WriteByte('\n')
+	}
+	return []byte(pr.out.String()), nil
+}
+
+func (pr *sqlPrinter) indentation(line []sqlToken) int {
+	leadingCloses := 0
+	for _, tok := range line {
+		if !tok.is(")") {
+			break
+		}
+		leadingCloses++
+	}
+	depth := max(len(pr.parens)-leadingCloses, 0)
+
+	first := line[0]
+	if first.kind == sqlDollar && pr.inBody { // end of the body
+		return 0
+	}
+
+	level := 0
+	continues := pr.statementOpen
+	if pr.inBody {
+		level = pr.blocks
+		if word := strings.ToUpper(first.text); first.kind == sqlWord && (plDedent[word] || word == "BEGIN" && pr.inDeclare) {
+			level--
+		}
+		continues = pr.plContinues
+	}
+
+	switch {
+	case leadingCloses != 0 || depth != 0:
+		level += depth
+	case continues:
+		level++
+	}
+	return max(level, 0)
+}
+
+func (pr *sqlPrinter) printLine(line []sqlToken) *sqlSyntaxError {
+	first := line[0]
+	// a line starting with a separator is joined to the previous one
+	if join := first.is(";") || first.is(","); join && pr.out.Len() != 0 && pr.last.kind != sqlComment {
+		line[0].space = false
+		return pr.printTokens(line, 0)
+	}
+
+	atTop := !pr.inBody && !pr.statementOpen && len(pr.parens) == 0
+	if pr.out.Len() != 0 {
+		pr.out.WriteByte('\n')
+		// keep one blank line between the top level statements, and
+		// always separate the statements written on several lines
+		if atTop && (first.newlines > 1 || pr.lastMultiline) {
+			pr.out.WriteByte('\n')
+		}
+	}
+	if atTop {
+		pr.lastMultiline = false
+	}
+	if pr.statementOpen {
+		pr.statementLines++
+	}
+
+	pr.out.WriteString(strings.Repeat(sqlIndent, pr.indentation(line)))
+	return pr.printTokens(line, 1)
+}
+
+// printTokens writes [line], starting the space normalization at [from]
+func (pr *sqlPrinter) printTokens(line []sqlToken, from int) *sqlSyntaxError {
+	hasBody := pr.inBody
+	for i, tok := range line {
+		if i >= from && tok.space && (i == 0 || !line[i-1].is("(")) && !tok.is(")") && !tok.is(",") && !tok.is(";") {
+			pr.out.WriteByte(' ')
+		}
+		pr.out.WriteString(tok.text)
+		if err := pr.update(tok); err != nil {
+			return err
+		}
+		hasBody = hasBody || pr.inBody
+	}
+
+	if hasBody && pr.inBody {
+		last := pr.lastSignificant
+		switch {
+		case last.kind == sqlComment: // comment only line
+		case last.kind == sqlDollar:
+			pr.plContinues = false
+		default:
+			pr.plContinues = !last.is(";") && !(last.kind == sqlWord && plOpeners[strings.ToUpper(last.text)])
+		}
+	}
+	return nil
+}
+
+// update the state after [tok]
+func (pr *sqlPrinter) update(tok sqlToken) *sqlSyntaxError {
+	pr.last = tok
+	if tok.kind == sqlComment {
+		return nil
+	}
+	pr.lastSignificant = tok
+
+	if !pr.inBody && !pr.statementOpen && !tok.is(";") {
+		pr.statementOpen, pr.statementLines = true, 1
+	}
+
+	switch {
+	case tok.is("("):
+		pr.parens = append(pr.parens, tok.line)
+	case tok.is(")"):
+		if len(pr.parens) == 0 {
+			return &sqlSyntaxError{tok.line, "unexpected closing parenthesis"}
+		}
+		pr.parens = pr.parens[:len(pr.parens)-1]
+	case tok.is(";"):
+		if pr.inBody {
+			pr.plStart = true
+		} else if len(pr.parens) == 0 {
+			pr.statementOpen = false
+			pr.lastMultiline = pr.statementLines > 1
+		}
+	case tok.kind == sqlDollar:
+		pr.inBody = !pr.inBody
+		pr.blocks, pr.inDeclare, pr.plStart, pr.plContinues = 0, false, true, false
+		if !pr.inBody && len(pr.parens) != 0 {
+			return &sqlSyntaxError{pr.parens[len(pr.parens)-1], "unclosed parenthesis in function body"}
+		}
+	case tok.kind == sqlWord && pr.inBody:
+		word := strings.ToUpper(tok.text)
+		if pr.plStart {
+			switch word {
+			case "END":
+				pr.blocks--
+				if pr.blocks < 0 {
+					return &sqlSyntaxError{tok.line, "unexpected END"}
+				}
+			case "IF", "CASE":
+				pr.blocks++
+			case "DECLARE":
+				pr.blocks++
+				pr.inDeclare = true
+			case "BEGIN":
+				if pr.inDeclare {
+					pr.inDeclare = false
+				} else {
+					pr.blocks++
+				}
+			}
+		}
+		if word == "LOOP" && pr.previousWord != "END" {
+			pr.blocks++
+		}
+		pr.plStart = plOpeners[word] && word != "CASE"
+		pr.previousWord = word
+	}
+	return nil
+}
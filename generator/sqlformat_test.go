@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/testutils"
+)
+
+func TestFormatSQL(t *testing.T) {
+	const src = `-- header
+
+	CREATE TABLE items (
+		Id serial PRIMARY KEY,
+	Label text  NOT NULL ,
+	Tags text[]
+		);
+-- constraints
+ALTER TABLE items ADD CHECK( Label <> 'it''s ; (' );
+
+	CREATE OR REPLACE FUNCTION validate (data jsonb)
+		RETURNS boolean
+		AS $$
+	DECLARE
+		is_valid boolean;
+	BEGIN
+
+		IF jsonb_typeof(data) = 'null' THEN -- accept null
+			RETURN TRUE;
+		END IF;
+		CASE
+			WHEN data->>'Kind' = 'A' THEN
+ RETURN TRUE;
+ELSE RETURN FALSE;
+		END CASE;
+		is_valid := (SELECT bool_and( KEY IN ('A') ) FROM jsonb_each(data))
+AND TRUE
+			;
+		RETURN is_valid;
+	END;
+	$$
+	LANGUAGE 'plpgsql'
+	IMMUTABLE;
+ALTER TABLE items ADD CONSTRAINT Tags_gomacro CHECK (validate(Tags));
+`
+	const expected = `-- header
+
+CREATE TABLE items (
+    Id serial PRIMARY KEY,
+    Label text NOT NULL,
+    Tags text[]
+);
+
+-- constraints
+ALTER TABLE items ADD CHECK(Label <> 'it''s ; (');
+
+CREATE OR REPLACE FUNCTION validate (data jsonb)
+    RETURNS boolean
+    AS $$
+DECLARE
+    is_valid boolean;
+BEGIN
+    IF jsonb_typeof(data) = 'null' THEN -- accept null
+        RETURN TRUE;
+    END IF;
+    CASE
+    WHEN data->>'Kind' = 'A' THEN
+        RETURN TRUE;
+    ELSE RETURN FALSE;
+    END CASE;
+    is_valid := (SELECT bool_and(KEY IN ('A')) FROM jsonb_each(data))
+        AND TRUE;
+    RETURN is_valid;
+END;
+$$
+    LANGUAGE 'plpgsql'
+    IMMUTABLE;
+
+ALTER TABLE items ADD CONSTRAINT Tags_gomacro CHECK (validate(Tags));
+`
+	out, err := FormatSQL("items.sql", []byte(src))
+	testutils.Assert(t, err == nil, err)
+	testutils.Assert(t, string(out) == expected)
+
+	// formatting is idempotent
+	out, err = FormatSQL("items.sql", out)
+	testutils.Assert(t, err == nil, err)
+	testutils.Assert(t, string(out) == expected)
+}
+
+func TestFormatSQLErrors(t *testing.T) {
+	for _, test := range []struct {
+		src  string
+		line int
+	}{
+		{"CREATE TABLE t (\n  A integer\n;\n", 1},
+		{"SELECT 1;\nSELECT 'abc;\n", 2},
+		{"SELECT 1;\nSELECT 2);\n", 2},
+		{"SELECT 1; /* comment\n", 1},
+		{"CREATE FUNCTION f() AS $$\nBEGIN\nRETURN 1;\n", 1},
+		{"CREATE FUNCTION f() AS $$\nBEGIN\nEND;\nEND;\n$$;\n", 4},
+	} {
+		_, err := FormatSQL("test.sql", []byte(test.src))
+		var fe *FormatError
+		testutils.Assert(t, errors.As(err, &fe))
+		testutils.Assert(t, fe.Line == test.line, test.src, fe.Line)
+		testutils.Assert(t, fe.Snippet != "")
+	}
+}
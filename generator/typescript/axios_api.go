@@ -4,8 +4,11 @@ package typescript
 
 import (
 	"fmt"
+	"go/token"
 	"net/http"
+	"strconv"
 	"strings"
+	"unicode"
 
 	an "github.com/benoitkugler/gomacro/analysis"
 	"github.com/benoitkugler/gomacro/analysis/httpapi"
@@ -13,18 +16,21 @@ import (
 )
 
 // return arg: String(params[arg])
+// The parameter types are checked by httpapi.
 func asObjectKey(param httpapi.TypedParam) string {
-	var underlying *an.Basic
+	var kind an.BasicKind
 	switch t := param.Type.(type) {
 	case *an.Basic:
-		underlying = t
+		kind = t.Kind()
 	case *an.Named:
-		underlying = t.Underlying.(*an.Basic)
+		kind = t.Underlying.(*an.Basic).Kind()
+	case *an.Enum:
+		kind = t.Kind()
 	default:
-		panic("unsupported type")
+		panic(an.NewError(token.Position{}, an.CodeUnsupportedType, "unsupported type %s for query param %s", param.Type.Type(), param.Name))
 	}
 
-	switch underlying.Kind() {
+	switch kind {
 	case an.BKFloat, an.BKInt:
 		return fmt.Sprintf("%q: String(params[%q])", param.Name, param.Name) // stringify
 	case an.BKBool:
@@ -36,10 +42,15 @@ func asObjectKey(param httpapi.TypedParam) string {
 	}
 }
 
-// returns true if the client API call has an argument
-// for the body
+// returns true if the Axios call has an argument
+// for the body (other methods use the 'data' config field)
 func expectBodyParam(a httpapi.Endpoint) bool {
-	return a.Method == http.MethodPost || a.Method == http.MethodPut
+	switch a.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return true
+	default:
+		return false
+	}
 }
 
 func hasBodyInput(a httpapi.Endpoint) bool {
@@ -58,29 +69,89 @@ func paramsType(params []httpapi.TypedParam) string {
 	return "{" + strings.Join(tmp, ", ") + "}"
 }
 
-func typeIn(a httpapi.Endpoint) string {
+// pathParamVar returns a valid TS identifier for the path parameter [name],
+// which does not conflict with the other arguments
+func pathParamVar(name string) string {
+	if name == "*" {
+		return "wildcard"
+	}
+	out := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name)
+	if jsReservedWords[out] {
+		return out + "_"
+	}
+	switch out {
+	case "params", "formParams", "file", "formValue", "fullUrl", "signal", "api", "variables", "queryClient":
+		return out + "_"
+	}
+	return out
+}
+
+var jsReservedWords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+	"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+	"if": true, "import": true, "in": true, "instanceof": true, "new": true, "null": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"yield": true, "let": true, "static": true, "implements": true, "interface": true,
+	"package": true, "private": true, "protected": true, "public": true, "await": true,
+}
+
+// pathParamsIn returns the arguments for the path parameters
+func pathParamsIn(a httpapi.Endpoint) []string {
+	out := make([]string, len(a.Contract.PathParams))
+	for i, param := range a.Contract.PathParams {
+		out[i] = pathParamVar(param.Name) + ": " + typeName(param.Type)
+	}
+	return out
+}
+
+// apiArg is one argument of an API method
+type apiArg struct {
+	name, type_ string
+}
+
+// apiArgs returns the arguments of the API method for [a]
+func apiArgs(a httpapi.Endpoint) []apiArg {
+	var out []apiArg
+	for _, param := range a.Contract.PathParams {
+		out = append(out, apiArg{pathParamVar(param.Name), typeName(param.Type)})
+	}
+
 	if hasBodyInput(a) { // JSON mode
-		return "params: " + typeName(a.Contract.InputBody)
+		return append(out, apiArg{"params", typeName(a.Contract.InputBody)})
 	}
 
-	var chunks []string
 	if withFormData(a) { // form data mode
 		if vals := a.Contract.InputForm.AsTypedValues(); len(vals) != 0 {
-			chunks = append(chunks, "formParams: "+paramsType(vals))
+			out = append(out, apiArg{"formParams", paramsType(vals)})
 		}
 		if fi := a.Contract.InputForm.File; fi != "" {
-			chunks = append(chunks, "file: File")
+			out = append(out, apiArg{"file", "File"})
 		}
 		if json := a.Contract.InputForm.JSON; json.Name != "" {
-			chunks = append(chunks, "formValue: "+typeName(json.Type))
+			out = append(out, apiArg{"formValue", typeName(json.Type)})
 		}
 	}
 
 	// params as query params
 	if len(a.Contract.InputQueryParams) != 0 {
-		chunks = append(chunks, "params: "+paramsType(a.Contract.InputQueryParams))
+		out = append(out, apiArg{"params", paramsType(a.Contract.InputQueryParams)})
 	}
 
+	return out
+}
+
+func typeIn(a httpapi.Endpoint) string {
+	var chunks []string
+	for _, arg := range apiArgs(a) {
+		chunks = append(chunks, arg.name+": "+arg.type_)
+	}
 	return strings.Join(chunks, ", ")
 }
 
@@ -97,8 +168,20 @@ func typeOut(a httpapi.Endpoint) string {
 	return typeName(a.Contract.Return)
 }
 
+// fullUrl returns the expression building the URL, with
+// the path parameters escaped
 func fullUrl(a httpapi.Endpoint) string {
-	return fmt.Sprintf("this.baseURL + %q", a.Url) // basic url
+	chunks := []string{"this.baseURL"}
+	for _, chunk := range a.PathChunks() {
+		if chunk.Param == nil {
+			chunks = append(chunks, fmt.Sprintf("%q", chunk.Static))
+		} else if chunk.IsWildcard { // keep the slashes, but escape each segment
+			chunks = append(chunks, fmt.Sprintf(`String(%s).split("/").map(encodeURIComponent).join("/")`, pathParamVar(chunk.Param.Name)))
+		} else {
+			chunks = append(chunks, fmt.Sprintf("encodeURIComponent(String(%s))", pathParamVar(chunk.Param.Name)))
+		}
+	}
+	return strings.Join(chunks, " + ")
 }
 
 func convertTypedQueryParams(c httpapi.Contract) string {
@@ -109,7 +192,30 @@ func convertTypedQueryParams(c httpapi.Contract) string {
 	return "{ " + strings.Join(chunks, ", ") + " }"
 }
 
+// formDataCode returns the creation of the 'formData' variable
+func formDataCode(a httpapi.Endpoint) string {
+	form := "const formData = new FormData()\n"
+	if fi := a.Contract.InputForm.File; fi != "" {
+		form += fmt.Sprintf("formData.append(%q, file, file.name)\n", fi)
+	}
+	for _, param := range a.Contract.InputForm.ValueNames {
+		form += fmt.Sprintf("formData.append(%q, formParams[%q])\n", param, param)
+	}
+	if param := a.Contract.InputForm.JSON; param.Name != "" {
+		form += fmt.Sprintf("formData.append(%q, JSON.stringify(formValue))\n", param.Name)
+	}
+	return form
+}
+
 func generateAxiosCall(a httpapi.Endpoint) string {
+	form, body := "", "" // the request payload, if any
+	if withFormData(a) { // add the creation of FormData
+		form = formDataCode(a)
+		body = "formData"
+	} else if hasBodyInput(a) {
+		body = "params"
+	}
+
 	callObjectItems := []string{
 		"headers: this.getHeaders()",
 	}
@@ -119,6 +225,10 @@ func generateAxiosCall(a httpapi.Endpoint) string {
 	if a.Contract.IsReturnBlob {
 		callObjectItems = append(callObjectItems, "responseType: 'arraybuffer'")
 	}
+	if body != "" && !expectBodyParam(a) {
+		// Axios.get, .delete, .head and .options only accept the payload in the config
+		callObjectItems = append(callObjectItems, "data: "+body)
+	}
 	callParams := fmt.Sprintf("{ %s }", strings.Join(callObjectItems, ", "))
 
 	returnAssignment := ""
@@ -128,25 +238,13 @@ func generateAxiosCall(a httpapi.Endpoint) string {
 
 	methodLower := strings.ToLower(a.Method)
 
-	if withFormData(a) { // add the creation of FormData
-		form := "const formData = new FormData()\n"
-		if fi := a.Contract.InputForm.File; fi != "" {
-			form += fmt.Sprintf("formData.append(%q, file, file.name)\n", fi)
-		}
-		for _, param := range a.Contract.InputForm.ValueNames {
-			form += fmt.Sprintf("formData.append(%q, formParams[%q])\n", param, param)
+	if expectBodyParam(a) {
+		if body == "" {
+			body = "null"
 		}
-		if param := a.Contract.InputForm.JSON; param.Name != "" {
-			form += fmt.Sprintf("formData.append(%q, JSON.stringify(formValue))\n", param.Name)
-		}
-		return fmt.Sprintf("%s %s await Axios.%s(fullUrl, formData, %s)", form, returnAssignment, methodLower, callParams)
-	} else if hasBodyInput(a) {
-		return fmt.Sprintf("%s await Axios.%s(fullUrl, params, %s)", returnAssignment, methodLower, callParams)
-	} else if !hasBodyInput(a) && expectBodyParam(a) {
-		return fmt.Sprintf("%s await Axios.%s(fullUrl, null, %s)", returnAssignment, methodLower, callParams)
-	} else {
-		return fmt.Sprintf("%s await Axios.%s(fullUrl, %s)", returnAssignment, methodLower, callParams)
+		return fmt.Sprintf("%s%sawait Axios.%s(fullUrl, %s, %s)", form, returnAssignment, methodLower, body, callParams)
 	}
+	return fmt.Sprintf("%s%sawait Axios.%s(fullUrl, %s)", form, returnAssignment, methodLower, callParams)
 }
 
 func generateMethodJSONStream(a httpapi.Endpoint) string {
@@ -200,7 +298,7 @@ func generateMethod(a httpapi.Endpoint) string {
 			%[4]s;
 			%[5]s
 		} catch (error) {
-			this.handleError(error);
+			this.handleError(%[6]s);
 		}
 	}
 	`
@@ -220,14 +318,101 @@ func generateMethod(a httpapi.Endpoint) string {
 	}
 	return fmt.Sprintf(template,
 		fnName, in, fullUrl(a),
-		generateAxiosCall(a), returnValue)
+		generateAxiosCall(a), returnValue, handleErrorArgs(a))
+}
+
+// errorTypeName returns the name of the type
+// describing the error responses of [a]
+func errorTypeName(a httpapi.Endpoint) string {
+	name := a.Contract.Name
+	return strings.ToUpper(name[:1]) + name[1:] + "Error"
 }
 
-func renderTypes(s []httpapi.Endpoint) string {
-	var (
-		allTypes  []an.Type
-		hasStream bool
-	)
+// generateErrorType returns the discriminated union of the
+// error responses of [a], or false if there is none
+func generateErrorType(a httpapi.Endpoint) (generator.Declaration, bool) {
+	codes := a.Contract.ErrorResponses()
+	if len(codes) == 0 {
+		return generator.Declaration{}, false
+	}
+	members := make([]string, len(codes))
+	for i, code := range codes {
+		data := "unknown" // untyped error
+		if ty := a.Contract.Responses[code]; ty != nil {
+			data = typeName(ty)
+		}
+		members[i] = fmt.Sprintf("| { endpoint: %q; status: %d; data: %s }", a.Contract.Name, code, data)
+	}
+	name := errorTypeName(a)
+	return generator.Declaration{
+		ID: name,
+		Content: fmt.Sprintf(`
+		/** %s describes the error responses of %s */
+		export type %s = %s;
+		`, name, a.Contract.Name, name, strings.Join(members, "\n")),
+	}, true
+}
+
+// handleErrorArgs returns the arguments of the handleError call,
+// adding the typed response if known
+func handleErrorArgs(a httpapi.Endpoint) string {
+	codes := a.Contract.ErrorResponses()
+	if len(codes) == 0 {
+		return "error"
+	}
+	statuses := make([]string, len(codes))
+	for i, code := range codes {
+		statuses[i] = strconv.Itoa(code)
+	}
+	return fmt.Sprintf("error, this.typedError<%s>(error, %q, [%s])", errorTypeName(a), a.Contract.Name, strings.Join(statuses, ", "))
+}
+
+// typedErrorEndpoints returns the endpoints whose error responses are typed.
+// Streams are only included if [withStreams] is true.
+func typedErrorEndpoints(api []httpapi.Endpoint, withStreams bool) []httpapi.Endpoint {
+	var out []httpapi.Endpoint
+	for _, endpoint := range api {
+		if endpoint.IsUrlOnly || (endpoint.Contract.IsReturnStream && !withStreams) {
+			continue
+		}
+		if len(endpoint.Contract.ErrorResponses()) != 0 {
+			out = append(out, endpoint)
+		}
+	}
+	return out
+}
+
+// errorTypes returns the error types of [api]
+func errorTypes(api []httpapi.Endpoint) []generator.Declaration {
+	var out []generator.Declaration
+	for _, endpoint := range api {
+		if decl, ok := generateErrorType(endpoint); ok {
+			out = append(out, decl)
+		}
+	}
+	return out
+}
+
+// generateAPIError returns the union of all the error types of [api]
+func generateAPIError(api []httpapi.Endpoint) string {
+	var names []string
+	seen := map[string]bool{}
+	for _, endpoint := range api {
+		if name := errorTypeName(endpoint); !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "export type APIError = never;"
+	}
+	return "export type APIError = " + strings.Join(names, " | ") + ";"
+}
+
+// renderTypes returns the types used by the endpoints,
+// with the additional declarations [extra]
+func renderTypes(s []httpapi.Endpoint, extra ...generator.Declaration) string {
+	var allTypes []an.Type
 	for _, api := range s { // write top-level decl
 		if ty := api.Contract.InputBody; ty != nil {
 			allTypes = append(allTypes, ty)
@@ -238,26 +423,42 @@ func renderTypes(s []httpapi.Endpoint) string {
 		for _, qp := range api.Contract.InputQueryParams {
 			allTypes = append(allTypes, qp.Type)
 		}
-		if api.Contract.IsReturnStream {
-			hasStream = true
+		for _, pp := range api.Contract.PathParams {
+			allTypes = append(allTypes, pp.Type)
+		}
+		for _, code := range api.Contract.ErrorResponses() {
+			if ty := api.Contract.Responses[code]; ty != nil {
+				allTypes = append(allTypes, ty)
+			}
 		}
 	}
-	decls := generateTypes(allTypes)
-	if hasStream {
-		decls = append(decls, generator.Declaration{
-			ID:       "__JSONStreamResponse_import",
-			Content:  `import type { JSONStreamResponse } from "@/utils";`,
-			Priority: true,
-		})
-	}
+	decls := append(generateTypes(allTypes, nil), extra...) // the types are checked by httpapi
 	return generator.WriteDeclarations(decls)
 }
 
+func hasStream(api []httpapi.Endpoint) bool {
+	for _, endpoint := range api {
+		if endpoint.Contract.IsReturnStream {
+			return true
+		}
+	}
+	return false
+}
+
 // GenerateAxios generate a TS class using Axios for calling the
 // given http API description.
 func GenerateAxios(api []httpapi.Endpoint) string {
 	// generate the code required for all the endpoints
-	typesCode := renderTypes(api)
+	withErrors := typedErrorEndpoints(api, false)
+	extra := errorTypes(withErrors)
+	if hasStream(api) {
+		extra = append(extra, generator.Declaration{
+			ID:       "__JSONStreamResponse_import",
+			Content:  `import type { JSONStreamResponse } from "@/utils";`,
+			Priority: true,
+		})
+	}
+	typesCode := renderTypes(api, extra...)
 
 	apiCalls := make([]string, len(api))
 	for i, endpoint := range api {
@@ -272,13 +473,21 @@ func GenerateAxios(api []httpapi.Endpoint) string {
 
 	%s
 
+	/** APIError is the union of the typed error responses, 
+		which may be narrowed using 'endpoint' and 'status'.
+	*/
+	%s
+
 	/** AbstractAPI provides auto-generated API calls and should be used 
 		as base class for an app controller.
 	*/
 	export abstract class AbstractAPI {
 		constructor(protected baseURL: string, public authToken: string) {}
 
-		abstract protected handleError(error: any): void
+		/** handleError is called with the typed response, when the status code is
+			one of the error responses of the endpoint.
+		*/
+		abstract protected handleError(error: any, response?: APIError): void
 
 		abstract protected startRequest(): void
 
@@ -286,6 +495,17 @@ func GenerateAxios(api []httpapi.Endpoint) string {
 			return { Authorization: "Bearer " + this.authToken }
 		}
 
+		/** typedError returns the typed response of a failed request to 'endpoint',
+			or undefined if its status code is not one of 'statuses'.
+		*/
+		protected typedError<E extends APIError>(error: any, endpoint: string, statuses: number[]): E | undefined {
+			const response = error?.response;
+			if (response === undefined || !statuses.includes(response.status)) {
+				return undefined;
+			}
+			return { endpoint, status: response.status, data: response.data } as unknown as E;
+		}
+
 		%s
-	}`, typesCode, strings.Join(apiCalls, "\n"))
+	}`, typesCode, generateAPIError(withErrors), strings.Join(apiCalls, "\n"))
 }
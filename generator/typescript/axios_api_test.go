@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/benoitkugler/gomacro/analysis"
@@ -36,6 +37,79 @@ func TestGenerate1(t *testing.T) {
 	fmt.Println(GenerateAxios(apis))
 }
 
+func TestPathParams(t *testing.T) {
+	api := httpapi.Endpoint{
+		Url:    "/items/:class/files/*",
+		Method: http.MethodGet,
+		Contract: httpapi.Contract{
+			Name: "M1",
+			PathParams: []httpapi.TypedParam{
+				{Name: "class", Type: analysis.Int},
+				{Name: "*", Type: analysis.String},
+			},
+		},
+	}
+	if in := typeIn(api); in != "class_: Int, wildcard: string" {
+		t.Fatal(in)
+	}
+	if url := fullUrl(api); url != `this.baseURL + "/items/" + encodeURIComponent(String(class_)) + "/files/" + String(wildcard).split("/").map(encodeURIComponent).join("/")` {
+		t.Fatal(url)
+	}
+}
+
+func TestMethodsBody(t *testing.T) {
+	body := &analysis.Array{Elem: analysis.Int, Len: -1}
+	for method, call := range map[string]string{
+		http.MethodPatch:   "await Axios.patch(fullUrl, params, { headers: this.getHeaders() })",
+		http.MethodDelete:  "await Axios.delete(fullUrl, { headers: this.getHeaders(), data: params })",
+		http.MethodOptions: "await Axios.options(fullUrl, { headers: this.getHeaders(), data: params })",
+	} {
+		api := httpapi.Endpoint{Url: "/items", Method: method, Contract: httpapi.Contract{Name: "M1", InputBody: body}}
+		if code := generateAxiosCall(api); code != call {
+			t.Fatal(code)
+		}
+	}
+
+	api := httpapi.Endpoint{Url: "/items", Method: http.MethodPatch, Contract: httpapi.Contract{Name: "M1"}}
+	if code := generateAxiosCall(api); code != "await Axios.patch(fullUrl, null, { headers: this.getHeaders() })" {
+		t.Fatal(code)
+	}
+	api = httpapi.Endpoint{Url: "/items", Method: http.MethodHead, Contract: httpapi.Contract{Name: "M1"}}
+	if code := generateAxiosCall(api); code != "await Axios.head(fullUrl, { headers: this.getHeaders() })" {
+		t.Fatal(code)
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	api := httpapi.Endpoint{
+		Url:    "/items",
+		Method: http.MethodPost,
+		Contract: httpapi.Contract{
+			Name:      "createItem",
+			InputBody: analysis.String,
+			Responses: map[int]analysis.Type{
+				200: nil,
+				404: nil,
+				409: &analysis.Array{Elem: analysis.String, Len: -1},
+			},
+		},
+	}
+	decl, ok := generateErrorType(api)
+	if !ok || decl.ID != "CreateItemError" {
+		t.Fatal(decl)
+	}
+	if !strings.Contains(decl.Content, `| { endpoint: "createItem"; status: 404; data: unknown }`) ||
+		!strings.Contains(decl.Content, `| { endpoint: "createItem"; status: 409; data: ( string[] | null) }`) {
+		t.Fatal(decl.Content)
+	}
+	if args := handleErrorArgs(api); args != `error, this.typedError<CreateItemError>(error, "createItem", [404, 409])` {
+		t.Fatal(args)
+	}
+	if code := generateAPIError([]httpapi.Endpoint{api, api}); code != "export type APIError = CreateItemError;" {
+		t.Fatal(code)
+	}
+}
+
 func TestGenerateMaps(t *testing.T) {
 	api := httpapi.Endpoint{
 		Url:    "/samlskm/",
@@ -55,7 +129,7 @@ func TestGenerateTypes(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	an := analysis.NewAnalysisFromFile(pkg, source)
+	an := analysis.NewAnalysisFromFile(pkg, source, nil)
 
 	var (
 		decls []generator.Declaration
@@ -77,3 +151,13 @@ func TestGenerateTypes(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestUnsupportedParam(t *testing.T) {
+	diags := analysis.NewDiagnostics(nil)
+	ok := diags.Try(0, func() {
+		asObjectKey(httpapi.TypedParam{Name: "ids", Type: &analysis.Array{Elem: analysis.Int, Len: -1}})
+	})
+	if ok || diags.ErrorCount() != 1 {
+		t.Fatal(diags.List())
+	}
+}
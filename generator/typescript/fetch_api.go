@@ -0,0 +1,219 @@
+package typescript
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	an "github.com/benoitkugler/gomacro/analysis"
+	"github.com/benoitkugler/gomacro/analysis/httpapi"
+)
+
+// fetchTypeIn adds the optional abort signal to the arguments
+func fetchTypeIn(a httpapi.Endpoint) string {
+	if in := typeIn(a); in != "" {
+		return in + ", signal?: AbortSignal"
+	}
+	return "signal?: AbortSignal"
+}
+
+// fetchUrl returns the URL, including the query parameters
+func fetchUrl(a httpapi.Endpoint) string {
+	if len(a.Contract.InputQueryParams) == 0 {
+		return fullUrl(a)
+	}
+	return fmt.Sprintf(`%s + "?" + new URLSearchParams(%s).toString()`, fullUrl(a), convertTypedQueryParams(a.Contract))
+}
+
+// fetchRequest returns the code building the payload (if any),
+// and the 'RequestInit' fields
+func fetchRequest(a httpapi.Endpoint) (prelude, init string) {
+	headers := []string{"...this.getHeaders()"}
+	items := []string{fmt.Sprintf("method: %q", a.Method)}
+	if withFormData(a) { // the browser sets the Content-Type with the boundary
+		prelude = formDataCode(a)
+		items = append(items, "body: formData")
+	} else if hasBodyInput(a) {
+		headers = append(headers, `"Content-Type": "application/json"`)
+		items = append(items, "body: JSON.stringify(params)")
+	}
+	if a.Contract.IsReturnStream {
+		headers = append(headers, `Accept: "application/json"`)
+	}
+	if len(headers) == 1 {
+		items = append(items, "headers: this.getHeaders()")
+	} else {
+		items = append(items, "headers: { "+strings.Join(headers, ", ")+" }")
+	}
+	items = append(items, "signal")
+	return prelude, "{ " + strings.Join(items, ", ") + " }"
+}
+
+// fetchReturn returns the code reading the response 'rep'
+func fetchReturn(a httpapi.Endpoint) string {
+	switch {
+	case a.Contract.IsReturnStream:
+		return fmt.Sprintf("return this.readJSONStream<%s>(rep);", typeOut(a))
+	case a.Contract.IsReturnBlob:
+		return `
+		const blob = await rep.blob();
+		const header = rep.headers.get("content-disposition") ?? "";
+		const startIndex = header.indexOf("filename=") + 9;
+		const filename = decodeURIComponent(header.substring(startIndex));
+		return { blob: blob, filename: filename };
+		`
+	case hasNoReturn(a):
+		return "return true;"
+	default:
+		return fmt.Sprintf("return (await rep.json()) as %s;", typeOut(a))
+	}
+}
+
+func generateFetchMethod(a httpapi.Endpoint) string {
+	if a.IsUrlOnly {
+		return generateURL(a)
+	}
+	const template = `
+	/** %[1]s %[2]s */
+	async %[1]s(%[3]s) {
+		const fullUrl = %[4]s;
+		this.startRequest();
+		try {
+			%[5]s
+			const rep = await this.send(fullUrl, %[6]s);
+			%[7]s
+		} catch (error) {
+			this.handleError(%[8]s);
+		}
+	}
+	`
+	comment := "performs the request and handles the error"
+	if a.Contract.IsReturnStream {
+		comment = "returns an iterator over the JSON values streamed by the server"
+	}
+	prelude, init := fetchRequest(a)
+	return fmt.Sprintf(template,
+		a.Contract.Name, comment, fetchTypeIn(a), fetchUrl(a),
+		prelude, init, fetchReturn(a), handleErrorArgs(a))
+}
+
+// canSendBody returns false for the methods whose requests
+// can't have a body with fetch
+func canSendBody(method string) bool {
+	return method != http.MethodGet && method != http.MethodHead
+}
+
+// GenerateFetch generate a TS class using the native fetch API for calling the
+// given http API description.
+// Since fetch rejects GET and HEAD requests with a body, the endpoints
+// binding such requests to a JSON input are reported to [diags] and ignored.
+func GenerateFetch(api []httpapi.Endpoint, diags *an.Diagnostics) string {
+	var supported []httpapi.Endpoint
+	for _, endpoint := range api {
+		if hasBodyInput(endpoint) && !canSendBody(endpoint.Method) {
+			diags.Errorf(endpoint.Pos(), an.CodeUnsupportedRoute, "route %s %s ignored: fetch can't send a JSON body with a %s request", endpoint.Method, endpoint.Url, endpoint.Method)
+			continue
+		}
+		supported = append(supported, endpoint)
+	}
+	api = supported
+
+	// generate the code required for all the endpoints
+	withErrors := typedErrorEndpoints(api, true)
+	typesCode := renderTypes(api, errorTypes(withErrors)...)
+
+	apiCalls := make([]string, len(api))
+	for i, endpoint := range api {
+		apiCalls[i] = generateFetchMethod(endpoint)
+	}
+
+	return fmt.Sprintf(`
+	// Code generated by gomacro/typescript/fetch_api.go. DO NOT EDIT
+
+	%s
+
+	/** HTTPError is thrown when the server responds with a non 2XX status code.
+		'data' is the JSON payload of the response, or its text.
+	*/
+	export class HTTPError extends Error {
+		constructor(public status: number, public data: unknown) {
+			super("request failed with status " + status);
+		}
+	}
+
+	/** APIError is the union of the typed error responses,
+		which may be narrowed using 'endpoint' and 'status'.
+	*/
+	%s
+
+	/** AbstractAPI provides auto-generated API calls and should be used
+		as base class for an app controller.
+	*/
+	export abstract class AbstractAPI {
+		constructor(protected baseURL: string, public authToken: string) {}
+
+		/** handleError is called with the typed response, when the status code is
+			one of the error responses of the endpoint.
+		*/
+		abstract protected handleError(error: any, response?: APIError): void
+
+		abstract protected startRequest(): void
+
+		getHeaders(): Record<string, string> {
+			return { Authorization: "Bearer " + this.authToken }
+		}
+
+		/** send performs the request, and throws an HTTPError
+			if the status code is not 2XX.
+		*/
+		protected async send(url: string, init: RequestInit): Promise<Response> {
+			const rep = await fetch(url, init);
+			if (!rep.ok) {
+				const text = await rep.text();
+				let data: unknown = text;
+				try {
+					data = JSON.parse(text);
+				} catch {
+					// keep the text
+				}
+				throw new HTTPError(rep.status, data);
+			}
+			return rep;
+		}
+
+		/** typedError returns the typed response of a failed request to 'endpoint',
+			or undefined if its status code is not one of 'statuses'.
+		*/
+		protected typedError<E extends APIError>(error: any, endpoint: string, statuses: number[]): E | undefined {
+			if (!(error instanceof HTTPError) || !statuses.includes(error.status)) {
+				return undefined;
+			}
+			return { endpoint, status: error.status, data: error.data } as unknown as E;
+		}
+
+		/** readJSONStream parses the body of 'rep', made of one JSON value per line. */
+		protected async *readJSONStream<T>(rep: Response): AsyncGenerator<T> {
+			const reader = rep.body!.pipeThrough(new TextDecoderStream()).getReader();
+			let buffer = "";
+			while (true) {
+				const { done, value } = await reader.read();
+				if (done) {
+					break;
+				}
+				buffer += value;
+				const lines = buffer.split("\n");
+				buffer = lines.pop()!;
+				for (const line of lines) {
+					if (line.trim() !== "") {
+						yield JSON.parse(line) as T;
+					}
+				}
+			}
+			if (buffer.trim() !== "") {
+				yield JSON.parse(buffer) as T;
+			}
+		}
+
+		%s
+	}`, typesCode, generateAPIError(withErrors), strings.Join(apiCalls, "\n"))
+}
@@ -0,0 +1,93 @@
+package typescript
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"github.com/benoitkugler/gomacro/analysis/httpapi"
+)
+
+func TestGenerateFetch(t *testing.T) {
+	apis := []httpapi.Endpoint{
+		{
+			Url: "/items/:id", Method: http.MethodPut, Contract: httpapi.Contract{
+				Name:       "updateItem",
+				PathParams: []httpapi.TypedParam{{Name: "id", Type: analysis.Int}},
+				InputBody:  &analysis.Array{Elem: analysis.Bool, Len: 5},
+				Return:     &analysis.Array{Elem: analysis.Int, Len: -1},
+				Responses:  map[int]analysis.Type{200: nil, 409: analysis.String},
+			},
+		},
+		{
+			Url: "/items", Method: http.MethodGet, Contract: httpapi.Contract{
+				Name: "listItems",
+				InputQueryParams: []httpapi.TypedParam{
+					{Name: "filter", Type: analysis.String},
+					{Name: "archived", Type: analysis.Bool},
+				},
+				Return:         analysis.Int,
+				IsReturnStream: true,
+			},
+		},
+		{
+			Url: "/upload", Method: http.MethodPost, Contract: httpapi.Contract{
+				Name:         "upload",
+				InputForm:    httpapi.Form{File: "file", ValueNames: []string{"label"}},
+				Return:       &analysis.Array{Elem: analysis.Int, Len: -1},
+				IsReturnBlob: true,
+			},
+		},
+	}
+	code := GenerateFetch(apis, nil)
+
+	for _, expected := range []string{
+		"async updateItem(id: Int, params: Ar5_boolean, signal?: AbortSignal)",
+		`{ method: "PUT", body: JSON.stringify(params), headers: { ...this.getHeaders(), "Content-Type": "application/json" }, signal }`,
+		`this.handleError(error, this.typedError<UpdateItemError>(error, "updateItem", [409]))`,
+		"export type APIError = UpdateItemError;",
+		`const fullUrl = this.baseURL + "/items" + "?" + new URLSearchParams({ "filter": params["filter"], "archived": params["archived"] ? 'ok' : '' }).toString();`,
+		`headers: { ...this.getHeaders(), Accept: "application/json" }`,
+		"return this.readJSONStream<Int>(rep);",
+		`formData.append("file", file, file.name)`,
+		`{ method: "POST", body: formData, headers: this.getHeaders(), signal }`,
+		"const blob = await rep.blob();",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+	if strings.Contains(code, "axios") || strings.Contains(code, "@/utils") {
+		t.Fatal("unexpected dependency")
+	}
+}
+
+func TestGenerateFetchGETBody(t *testing.T) {
+	apis := []httpapi.Endpoint{
+		{
+			Url: "/items", Method: http.MethodGet, Contract: httpapi.Contract{
+				Name:      "searchItems", // echo Bind on a GET route
+				InputBody: &analysis.Array{Elem: analysis.Bool, Len: 5},
+				Return:    analysis.Int,
+			},
+		},
+		{
+			Url: "/items", Method: http.MethodPost, Contract: httpapi.Contract{
+				Name:      "createItem",
+				InputBody: &analysis.Array{Elem: analysis.Bool, Len: 5},
+				Return:    analysis.Int,
+			},
+		},
+	}
+	diags := analysis.NewDiagnostics(nil)
+	code := GenerateFetch(apis, diags)
+
+	list := diags.List()
+	if len(list) != 1 || !strings.Contains(list[0].Message, "route GET /items ignored") {
+		t.Fatal(list)
+	}
+	if strings.Contains(code, "searchItems") || !strings.Contains(code, "async createItem(params: Ar5_boolean") {
+		t.Fatal(code)
+	}
+}
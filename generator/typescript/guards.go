@@ -0,0 +1,378 @@
+package typescript
+
+import (
+	"fmt"
+	"strings"
+
+	an "github.com/benoitkugler/gomacro/analysis"
+	gen "github.com/benoitkugler/gomacro/generator"
+)
+
+// This file generates runtime type guards (isX), and the functions
+// converting times and dates from and to JS Date objects (parseX and serializeX).
+
+var (
+	parsedDecl = gen.Declaration{
+		ID: "__parsed_def",
+		Content: `
+	/** Parsed<T> is T where the times and dates are converted to Date objects. */
+	export type Parsed<T> = T extends Time | Date_
+		? Date
+		: T extends string | number | boolean | null | undefined
+		? T
+		: { [K in keyof T]: Parsed<T[K]> };
+	`,
+	}
+
+	isTimeStringDecl = gen.Declaration{
+		ID: "__guard_isTimeString",
+		Content: `function isTimeString(v: unknown) {
+		return typeof v === "string" && !isNaN(Date.parse(v));
+	}`,
+	}
+
+	isArrayOfDecl = gen.Declaration{
+		ID: "__guard_isArrayOf",
+		Content: `function isArrayOf(v: unknown, isElem: (e: unknown) => boolean, length?: number) {
+		return Array.isArray(v) && (length === undefined || v.length === length) && v.every(isElem);
+	}`,
+	}
+
+	isRecordOfDecl = gen.Declaration{
+		ID: "__guard_isRecordOf",
+		Content: `function isRecordOf(v: unknown, isElem: (e: unknown) => boolean) {
+		return typeof v === "object" && v !== null && !Array.isArray(v) && Object.values(v).every(isElem);
+	}`,
+	}
+
+	mapArrayDecl = gen.Declaration{
+		ID: "__guard_mapArray",
+		Content: `function mapArray<T, U>(v: T[] | null, f: (e: T) => U): U[] | null {
+		return v === null ? null : v.map(f);
+	}`,
+	}
+
+	mapRecordDecl = gen.Declaration{
+		ID: "__guard_mapRecord",
+		Content: `function mapRecord<T, U>(v: Record<string, T> | null, f: (e: T) => U): Record<string, U> | null {
+		return v === null ? null : Object.fromEntries(Object.entries(v).map(([k, e]) => [k, f(e)]));
+	}`,
+	}
+)
+
+// guardContext generates the guards and conversion functions
+type guardContext struct {
+	decls *[]gen.Declaration
+	cache gen.Cache
+	// hasTime caches the result of [containsTime]
+	hasTime map[an.Type]bool
+}
+
+func newGuardContext() guardContext {
+	return guardContext{decls: new([]gen.Declaration), cache: make(gen.Cache), hasTime: make(map[an.Type]bool)}
+}
+
+func (gc guardContext) add(decl gen.Declaration) { *gc.decls = append(*gc.decls, decl) }
+
+// generateGuards returns the guards and conversion functions for [types],
+// which are assumed to be already declared.
+func generateGuards(types []an.Type, diags *an.Diagnostics) []gen.Declaration {
+	gc := newGuardContext()
+	for _, ty := range types {
+		diags.Try(an.DeclarationPos(ty.Type()), func() { gc.generate(ty) })
+	}
+	return *gc.decls
+}
+
+// containsTime returns true if [ty] has times or dates, which
+// are converted by parseX and serializeX
+func (gc guardContext) containsTime(ty an.Type) bool {
+	if out, has := gc.hasTime[ty]; has {
+		return out
+	}
+	gc.hasTime[ty] = false // handle recursive types
+	var out bool
+	switch ty := ty.(type) {
+	case *an.Time:
+		out = true
+	case *an.Pointer:
+		out = gc.containsTime(ty.Elem)
+	case *an.Array:
+		out = gc.containsTime(ty.Elem)
+	case *an.Map:
+		out = gc.containsTime(ty.Elem)
+	case *an.Named:
+		out = gc.containsTime(ty.Underlying)
+	case *an.Struct:
+		for _, field := range ty.Fields {
+			if field.Exported() && !field.IsOpaqueFor("typescript") && gc.containsTime(field.Type) {
+				out = true
+			}
+		}
+	case *an.Union:
+		for _, member := range ty.Members {
+			if gc.containsTime(member) {
+				out = true
+			}
+		}
+	}
+	gc.hasTime[ty] = out
+	return out
+}
+
+// hasFunctions returns true for the types with a name, for which functions are generated.
+// Generic declarations have no functions : they are generated for each instantiation.
+func hasFunctions(ty an.Type) bool {
+	switch ty := ty.(type) {
+	case *an.Named:
+		return len(ty.TypeParams) == 0 && typeName(ty) != typeName(ty.Underlying)
+	case *an.Struct:
+		return len(ty.TypeParams) == 0
+	case *an.Enum, *an.Union:
+		return true
+	default:
+		return false
+	}
+}
+
+// guardExpr returns a boolean expression checking that [v] has type [ty].
+func (gc guardContext) guardExpr(ty an.Type, v string) string {
+	if hasFunctions(ty) {
+		return fmt.Sprintf("is%s(%s)", identName(ty), v)
+	}
+	switch ty := ty.(type) {
+	case *an.Pointer:
+		return fmt.Sprintf("(%s === null || %s)", v, gc.guardExpr(ty.Elem, v))
+	case *an.Basic:
+		switch ty.Kind() {
+		case an.BKString:
+			return fmt.Sprintf("typeof %s === \"string\"", v)
+		case an.BKInt:
+			return fmt.Sprintf("Number.isInteger(%s)", v)
+		case an.BKFloat:
+			return fmt.Sprintf("typeof %s === \"number\"", v)
+		case an.BKBool:
+			return fmt.Sprintf("typeof %s === \"boolean\"", v)
+		default:
+			panic(an.ExhaustiveBasicKindSwitch)
+		}
+	case *an.Time:
+		gc.add(isTimeStringDecl)
+		return fmt.Sprintf("isTimeString(%s)", v)
+	case *an.Array:
+		gc.add(isArrayOfDecl)
+		if ty.Len >= 0 {
+			return fmt.Sprintf("isArrayOf(%s, (e) => %s, %d)", v, gc.guardExpr(ty.Elem, "e"), ty.Len)
+		}
+		// nullable since empty slice may be JSONized as null
+		return fmt.Sprintf("(%s === null || isArrayOf(%s, (e) => %s))", v, v, gc.guardExpr(ty.Elem, "e"))
+	case *an.Map:
+		gc.add(isRecordOfDecl)
+		return fmt.Sprintf("(%s === null || isRecordOf(%s, (e) => %s))", v, v, gc.guardExpr(ty.Elem, "e"))
+	case *an.Named: // no alias
+		return gc.guardExpr(ty.Underlying, v)
+	case *an.External:
+		if ty.Mapping.JSON == an.JSONAny {
+			return "true"
+		}
+		return fmt.Sprintf("typeof %s === %q", v, ty.Mapping.JSON)
+	case *an.TypeParam: // only found in generic declarations, which have no guards
+		return "true"
+	default:
+		panic(an.ExhaustiveTypeSwitch)
+	}
+}
+
+// convertExpr returns an expression converting [v] (of type [ty]),
+// to JS Date objects if [parse] is true, or back to strings otherwise.
+func (gc guardContext) convertExpr(ty an.Type, v string, parse bool) string {
+	if !gc.containsTime(ty) {
+		return v
+	}
+	if hasFunctions(ty) {
+		if parse {
+			return fmt.Sprintf("parse%s(%s)", identName(ty), v)
+		}
+		return fmt.Sprintf("serialize%s(%s)", identName(ty), v)
+	}
+	switch ty := ty.(type) {
+	case *an.Time:
+		switch {
+		case parse:
+			return fmt.Sprintf("new Date(%s)", v)
+		case ty.IsDate:
+			return fmt.Sprintf("(%s.toISOString().substring(0, 10) as Date_)", v)
+		default:
+			return fmt.Sprintf("(%s.toISOString() as Time)", v)
+		}
+	case *an.Pointer:
+		return fmt.Sprintf("(%s === null ? null : %s)", v, gc.convertExpr(ty.Elem, v, parse))
+	case *an.Array:
+		if ty.Len >= 0 {
+			return fmt.Sprintf("%s.map((e) => %s)", v, gc.convertExpr(ty.Elem, "e", parse))
+		}
+		gc.add(mapArrayDecl)
+		return fmt.Sprintf("mapArray(%s, (e) => %s)", v, gc.convertExpr(ty.Elem, "e", parse))
+	case *an.Map:
+		gc.add(mapRecordDecl)
+		return fmt.Sprintf("mapRecord(%s, (e) => %s)", v, gc.convertExpr(ty.Elem, "e", parse))
+	case *an.Named: // no alias
+		return gc.convertExpr(ty.Underlying, v, parse)
+	default:
+		panic(an.ExhaustiveTypeSwitch)
+	}
+}
+
+func (gc guardContext) generate(ty an.Type) {
+	if gc.cache.Check(ty) {
+		return
+	}
+
+	switch ty := ty.(type) {
+	case *an.Basic, *an.Time, *an.External, *an.TypeParam:
+		// nothing to do
+	case *an.Pointer:
+		gc.generate(ty.Elem)
+	case *an.Array:
+		gc.generate(ty.Elem)
+	case *an.Map:
+		gc.generate(ty.Key)
+		gc.generate(ty.Elem)
+	case *an.Named:
+		gc.generate(ty.Underlying)
+		if hasFunctions(ty) {
+			gc.addFunctions(ty, "return "+gc.guardExpr(ty.Underlying, "v")+";",
+				fmt.Sprintf("return %s as Parsed<%s>;", gc.convertExpr(ty.Underlying, "json", true), typeName(ty)),
+				fmt.Sprintf("return %s as %s;", gc.convertExpr(ty.Underlying, "v", false), typeName(ty)),
+			)
+		}
+	case *an.Enum:
+		gc.addFunctions(ty, fmt.Sprintf("return (Object.values(%s) as unknown[]).includes(v);", typeName(ty)), "", "")
+	case *an.Struct:
+		if hasFunctions(ty) {
+			gc.generateStruct(ty)
+		}
+	case *an.Union:
+		gc.generateUnion(ty)
+	default:
+		panic(an.ExhaustiveTypeSwitch)
+	}
+}
+
+// addFunctions adds the declarations for the guard of [ty],
+// and the conversion functions if [ty] contains times.
+func (gc guardContext) addFunctions(ty an.Type, guardBody, parseBody, serializeBody string) {
+	name, typ := identName(ty), typeName(ty)
+	code := fmt.Sprintf(`
+	/** is%[1]s returns true if 'v' is a valid %[3]s. */
+	export function is%[1]s(v: unknown): v is %[3]s {
+		%[2]s
+	}
+	`, name, guardBody, typ)
+	if gc.containsTime(ty) {
+		gc.add(parsedDecl)
+		gc.add(timeDecl)
+		gc.add(dateDecl)
+		code += fmt.Sprintf(`
+		/** parse%[1]s converts the times and dates of 'json' to Date objects. */
+		export function parse%[1]s(json: %[4]s): Parsed<%[4]s> {
+			%[2]s
+		}
+
+		/** serialize%[1]s is the inverse of parse%[1]s. */
+		export function serialize%[1]s(v: Parsed<%[4]s>): %[4]s {
+			%[3]s
+		}
+		`, name, parseBody, serializeBody, typ)
+	}
+	gc.add(gen.Declaration{ID: "guard:" + declID(ty), Content: code})
+}
+
+func (gc guardContext) generateStruct(t *an.Struct) {
+	var checks, parsed, serialized []string
+	for _, field := range t.Fields {
+		if !field.Exported() || field.IsOpaqueFor("typescript") {
+			continue
+		}
+		gc.generate(field.Type) // recurse
+
+		name := field.JSONName()
+		value := fmt.Sprintf("o[%q]", name)
+		check := gc.guardExpr(field.Type, value)
+		if field.AsString() {
+			check = fmt.Sprintf("typeof %s === \"string\"", value)
+		}
+		if field.OmitEmpty() {
+			check = fmt.Sprintf("(%s === undefined || %s)", value, check)
+		}
+		checks = append(checks, check)
+
+		if gc.containsTime(field.Type) {
+			// JSON names are not always valid identifiers
+			jsonValue, goValue := fmt.Sprintf("json[%q]", name), fmt.Sprintf("v[%q]", name)
+			parse, serialize := gc.convertExpr(field.Type, jsonValue, true), gc.convertExpr(field.Type, goValue, false)
+			if field.OmitEmpty() {
+				parse = fmt.Sprintf("%s === undefined ? undefined : %s", jsonValue, parse)
+				serialize = fmt.Sprintf("%s === undefined ? undefined : %s", goValue, serialize)
+			}
+			parsed = append(parsed, fmt.Sprintf("%s: %s,", propertyName(name), parse))
+			serialized = append(serialized, fmt.Sprintf("%s: %s,", propertyName(name), serialize))
+		}
+	}
+
+	guard := `if (typeof v !== "object" || v === null) return false;`
+	if len(checks) != 0 {
+		guard += fmt.Sprintf(`
+		const o = v as Record<string, unknown>;
+		return %s;`, strings.Join(checks, " &&\n"))
+	} else {
+		guard += "\nreturn true;"
+	}
+
+	name := typeName(t)
+	gc.addFunctions(t, guard,
+		fmt.Sprintf(`return {
+			...json,
+			%s
+		} as Parsed<%s>;`, strings.Join(parsed, "\n"), name),
+		fmt.Sprintf(`return {
+			...v,
+			%s
+		} as %s;`, strings.Join(serialized, "\n"), name),
+	)
+}
+
+func (gc guardContext) generateUnion(u *an.Union) {
+	var checks, parsed, serialized []string
+	for _, m := range u.Members {
+		gc.generate(m) // recurse
+
+		kind := an.LocalName(m)
+		checks = append(checks, fmt.Sprintf("case %q:\nreturn %s;", kind, gc.guardExpr(m, "o.Data")))
+		if gc.containsTime(m) {
+			parsed = append(parsed, fmt.Sprintf("case %q:\nreturn { Kind: json.Kind, Data: %s } as Parsed<%s>;", kind, gc.convertExpr(m, "json.Data", true), typeName(u)))
+			serialized = append(serialized, fmt.Sprintf("case %q:\nreturn { Kind: v.Kind, Data: %s } as %s;", kind, gc.convertExpr(m, "v.Data", false), typeName(u)))
+		}
+	}
+
+	name := typeName(u)
+	gc.addFunctions(u,
+		fmt.Sprintf(`if (typeof v !== "object" || v === null) return false;
+		const o = v as Record<string, unknown>;
+		switch (o.Kind) {
+			%s
+			default:
+			return false;
+		}`, strings.Join(checks, "\n")),
+		fmt.Sprintf(`switch (json.Kind) {
+			%s
+			default:
+			return json as Parsed<%s>;
+		}`, strings.Join(parsed, "\n"), name),
+		fmt.Sprintf(`switch (v.Kind) {
+			%s
+			default:
+			return v as %s;
+		}`, strings.Join(serialized, "\n"), name),
+	)
+}
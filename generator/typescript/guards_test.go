@@ -0,0 +1,42 @@
+package typescript
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"github.com/benoitkugler/gomacro/generator"
+)
+
+func TestGenerateGuards(t *testing.T) {
+	source := "test/guards/guards.go"
+	pkg, err := analysis.LoadSource(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ana := analysis.NewAnalysisFromFile(pkg, source, nil)
+
+	code := generator.WriteDeclarations(Generate(ana, nil))
+	for _, expected := range []string{
+		"export type Parsed<T> = T extends Time | Date_",
+		"export function isEvent(v: unknown): v is Event {",
+		`(o["Dates"] === null || isArrayOf(o["Dates"], (e) => isEventDate(e)))`,
+		`return { Kind: json.Kind, Data: parseEvent(json.Data) } as Parsed<Item>;`,
+		`case "Note":
+return isNote(o.Data);`,
+		`Start: new Date(json["Start"]),`,
+		`Dates: mapArray(v["Dates"], (e) => serializeEventDate(e)),`,
+		`"end-date": new Date(json["end-date"]),`,
+		`"end-date": (v["end-date"].toISOString() as Time),`,
+		`ByTitle: mapRecord(json["ByTitle"], (e) => parseEvent(e)),`,
+		"return (v.toISOString().substring(0, 10) as Date_) as EventDate;",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+	// no conversion is required for Note
+	if strings.Contains(code, "parseNote") {
+		t.Fatal("unexpected parseNote")
+	}
+}
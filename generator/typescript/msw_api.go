@@ -0,0 +1,234 @@
+package typescript
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	an "github.com/benoitkugler/gomacro/analysis"
+	"github.com/benoitkugler/gomacro/analysis/httpapi"
+	gen "github.com/benoitkugler/gomacro/generator"
+)
+
+// This file generates MSW (Mock Service Worker) handlers,
+// returning fake values built from the analysed types.
+
+// fakeMaxDepth limits the size of the values of recursive types
+const fakeMaxDepth = 2
+
+// fakeContext generates the functions returning fake values
+type fakeContext struct {
+	decls *[]gen.Declaration
+	cache gen.Cache
+}
+
+func newFakeContext() fakeContext {
+	return fakeContext{decls: new([]gen.Declaration), cache: make(gen.Cache)}
+}
+
+// fakeCall returns the call to the fake function [name] : inside
+// a fake function, the 'depth' argument is incremented
+func fakeCall(name string, inFunc bool) string {
+	if inFunc {
+		return name + "(depth + 1)"
+	}
+	return name + "(1)"
+}
+
+// fakeGuard returns [value] if the depth is not too large, or [empty] otherwise
+func fakeGuard(value, empty string, inFunc bool) string {
+	if !inFunc { // top level values are never recursive
+		return value
+	}
+	return fmt.Sprintf("(depth < %d ? %s : %s)", fakeMaxDepth, value, empty)
+}
+
+// expr returns an expression evaluating to a fake value of type [ty].
+// [inFunc] is true when the expression is used in a fake function,
+// where the 'depth' variable is defined.
+func (fc fakeContext) expr(ty an.Type, inFunc bool) string {
+	switch ty := ty.(type) {
+	case *an.Basic:
+		switch ty.Kind() {
+		case an.BKString:
+			return `"fake"`
+		case an.BKInt:
+			return "(1 as Int)"
+		case an.BKFloat:
+			return "1.5"
+		case an.BKBool:
+			return "true"
+		default:
+			panic(an.ExhaustiveBasicKindSwitch)
+		}
+	case *an.Time:
+		if ty.IsDate {
+			return "(new Date().toISOString().substring(0, 10) as Date_)"
+		}
+		return "(new Date().toISOString() as Time)"
+	case *an.Pointer:
+		return fc.expr(ty.Elem, inFunc)
+	case *an.Array:
+		elem := fc.expr(ty.Elem, inFunc)
+		if ty.Len >= 0 {
+			elems := make([]string, ty.Len)
+			for i := range elems {
+				elems[i] = elem
+			}
+			return fmt.Sprintf("([%s] as %s)", strings.Join(elems, ", "), typeName(ty))
+		}
+		return fakeGuard(fmt.Sprintf("[%s, %s]", elem, elem), "[]", inFunc)
+	case *an.Map:
+		return fakeGuard(fmt.Sprintf("{ [%s]: %s }", fc.expr(ty.Key, inFunc), fc.expr(ty.Elem, inFunc)), "{}", inFunc)
+	case *an.Enum: // use the first exported member
+		for _, member := range ty.Members {
+			if member.Const.Exported() {
+				return an.LocalName(ty) + "." + member.Const.Name()
+			}
+		}
+		panic(an.NewError(token.Position{}, an.CodeUnsupportedType, "enum %s has no exported member to use as fake value", ty.Type()))
+	case *an.Named:
+		if typeName(ty) == typeName(ty.Underlying) { // no alias
+			return fc.expr(ty.Underlying, inFunc)
+		}
+		return fakeCall(fc.function(ty), inFunc)
+	case *an.Struct, *an.Union:
+		return fakeCall(fc.function(ty), inFunc)
+	case *an.External:
+		var value string
+		switch ty.Mapping.JSON {
+		case an.JSONString:
+			value = `"fake"`
+		case an.JSONNumber:
+			value = "1"
+		case an.JSONBoolean:
+			value = "true"
+		default:
+			value = "null"
+		}
+		return fmt.Sprintf("(%s as %s)", value, typeName(ty))
+	case *an.TypeParam: // only found in generic declarations
+		return "null"
+	default:
+		panic(an.ExhaustiveTypeSwitch)
+	}
+}
+
+// function adds (if needed) the declaration of the function returning
+// a fake value for [ty], and returns its name
+func (fc fakeContext) function(ty an.Type) string {
+	name := "fake" + identName(ty)
+	if fc.cache.Check(ty) {
+		return name
+	}
+
+	var value string
+	switch ty := ty.(type) {
+	case *an.Named:
+		value = fmt.Sprintf("%s as %s", fc.expr(ty.Underlying, true), typeName(ty))
+	case *an.Struct:
+		var fields []string
+		for _, field := range ty.Fields {
+			if !field.Exported() {
+				continue
+			}
+			switch {
+			case field.IsOpaqueFor("typescript"):
+				fields = append(fields, fmt.Sprintf("%s: null,", propertyName(field.JSONName())))
+			case field.AsString():
+				fields = append(fields, fmt.Sprintf("%s: JSON.stringify(%s),", propertyName(field.JSONName()), fc.expr(field.Type, true)))
+			default:
+				fields = append(fields, fmt.Sprintf("%s: %s,", propertyName(field.JSONName()), fc.expr(field.Type, true)))
+			}
+		}
+		value = "{\n" + strings.Join(fields, "\n") + "\n}"
+	case *an.Union: // use the first member
+		member := ty.Members[0]
+		value = fmt.Sprintf("{ Kind: %q, Data: %s }", an.LocalName(member), fc.expr(member, true))
+	}
+
+	depth := "depth"
+	if !strings.Contains(value, "depth") { // avoid unused parameter
+		depth = "_depth"
+	}
+	*fc.decls = append(*fc.decls, gen.Declaration{
+		ID: "fake:" + declID(ty),
+		Content: fmt.Sprintf(`
+		function %s(%s: number): %s {
+			return %s;
+		}
+		`, name, depth, typeName(ty), value),
+	})
+	return name
+}
+
+// mswPath returns the path of [a], using the MSW syntax
+// for parameters
+func mswPath(a httpapi.Endpoint) string {
+	var out strings.Builder
+	for _, chunk := range a.PathChunks() {
+		switch {
+		case chunk.Param == nil:
+			out.WriteString(chunk.Static)
+		case chunk.IsWildcard:
+			out.WriteString("*")
+		default:
+			out.WriteString(":" + chunk.Param.Name)
+		}
+	}
+	return out.String()
+}
+
+// mswResolver returns the function building the fake response
+func (fc fakeContext) mswResolver(a httpapi.Endpoint) string {
+	ct := a.Contract
+	switch {
+	case ct.IsReturnBlob:
+		return `() => new HttpResponse(new Blob(["fake content"]), {
+			headers: { "Content-Type": "application/octet-stream", "Content-Disposition": "attachment; filename=fake.txt" },
+		})`
+	case ct.IsReturnStream: // one JSON value per line
+		value := fc.expr(ct.Return, false)
+		return fmt.Sprintf(`() => new HttpResponse([%s, %s].map((value) => JSON.stringify(value) + "\n").join(""), {
+			headers: { "Content-Type": "application/x-ndjson" },
+		})`, value, value)
+	case ct.Return == nil:
+		return "() => new HttpResponse(null, { status: 200 })"
+	default:
+		return fmt.Sprintf("() => HttpResponse.json(%s)", fc.expr(ct.Return, false))
+	}
+}
+
+// GenerateMSW generates MSW request handlers, returning fake values
+// for each endpoint of [api].
+// The endpoints whose fake values can't be built are reported to [diags] and ignored.
+func GenerateMSW(api []httpapi.Endpoint, diags *an.Diagnostics) string {
+	fc := newFakeContext()
+	var handlers []string
+	for _, endpoint := range api {
+		diags.Try(endpoint.Pos(), func() {
+			handlers = append(handlers, fmt.Sprintf("http.%s(baseURL + %q, %s),",
+				strings.ToLower(endpoint.Method), mswPath(endpoint), fc.mswResolver(endpoint)))
+		})
+	}
+
+	imports := gen.Declaration{
+		ID:       "__msw_import",
+		Content:  `import { http, HttpResponse } from "msw";`,
+		Priority: true,
+	}
+	typesCode := renderTypes(api, append(*fc.decls, imports)...)
+
+	return fmt.Sprintf(`
+	// Code generated by gomacro/typescript/msw_api.go. DO NOT EDIT
+
+	%s
+
+	/** handlers returns the MSW request handlers, serving fake values for each endpoint. */
+	export function handlers(baseURL = "") {
+		return [
+			%s
+		];
+	}
+	`, typesCode, strings.Join(handlers, "\n"))
+}
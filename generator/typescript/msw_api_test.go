@@ -0,0 +1,103 @@
+package typescript
+
+import (
+	"go/constant"
+	"go/token"
+	"go/types"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"github.com/benoitkugler/gomacro/analysis/httpapi"
+	"github.com/benoitkugler/gomacro/testutils"
+)
+
+func TestGenerateMSW(t *testing.T) {
+	source := "../../testutils/testsource/defs.go"
+	pkg, err := analysis.LoadSource(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ana := analysis.NewAnalysisFromFile(pkg, source, nil)
+
+	// one endpoint for each type
+	var apis []httpapi.Endpoint
+	for _, ty := range ana.Source {
+		apis = append(apis, httpapi.Endpoint{
+			Url: "/" + analysis.LocalName(ana.Types[ty]), Method: http.MethodGet,
+			Contract: httpapi.Contract{Name: "get", Return: ana.Types[ty]},
+		})
+	}
+	code := GenerateMSW(apis, nil)
+
+	for _, expected := range []string{
+		`import { http, HttpResponse } from "msw";`,
+		"function fakeRecursiveType(depth: number): RecursiveType {",
+		"Children: (depth < 2 ? [fakeRecursiveType(depth + 1), fakeRecursiveType(depth + 1)] : []),",
+		`return { Kind: "ConcretType1", Data: fakeConcretType1(depth + 1) };`,
+		"E: EnumInt.Ai,",
+		`http.get(baseURL + "/RecursiveType", () => HttpResponse.json(fakeRecursiveType(1))),`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestGenerateMSWRoutes(t *testing.T) {
+	fn := "../../analysis/httpapi/test/routes.go"
+	pack, err := analysis.LoadSource(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := GenerateMSW(httpapi.ParseEcho(pack, abs, nil), nil)
+	for _, expected := range []string{
+		`http.put(baseURL + "/with_param/:param"`,
+		`"Content-Disposition": "attachment; filename=fake.txt"`,
+		`"Content-Type": "application/x-ndjson"`,
+		"new HttpResponse(null, { status: 200 })",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestFakeEnum(t *testing.T) {
+	source := "../../testutils/testsource/defs.go"
+	pkg, err := analysis.LoadSource(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ana := analysis.NewAnalysisFromFile(pkg, source, nil)
+	enum := *ana.Types[testutils.Lookup(pkg, "EnumUInt")].(*analysis.Enum)
+	hidden := analysis.EnumMember{Const: types.NewConst(token.NoPos, pkg.Types, "hidden", enum.Type(), constant.MakeInt64(9))}
+
+	// unexported members are skipped
+	enum.Members = append([]analysis.EnumMember{hidden}, enum.Members...)
+	if expr := newFakeContext().expr(&enum, false); expr != "EnumUInt.A" {
+		t.Fatal(expr)
+	}
+
+	// no exported member
+	enum.Members = []analysis.EnumMember{hidden}
+	apis := []httpapi.Endpoint{
+		{Url: "/enum", Method: http.MethodGet, Contract: httpapi.Contract{Name: "getEnum", Return: &enum}},
+		{Url: "/int", Method: http.MethodGet, Contract: httpapi.Contract{Name: "getInt", Return: analysis.Int}},
+	}
+	diags := analysis.NewDiagnostics(nil)
+	code := GenerateMSW(apis, diags)
+	if diags.ErrorCount() != 1 || !strings.Contains(diags.List()[0].Message, "has no exported member") {
+		t.Fatal(diags.List())
+	}
+	if strings.Contains(code, `"/enum"`) || !strings.Contains(code, `http.get(baseURL + "/int"`) {
+		t.Fatal(code)
+	}
+}
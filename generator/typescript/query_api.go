@@ -0,0 +1,205 @@
+package typescript
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/benoitkugler/gomacro/analysis/httpapi"
+)
+
+// DefaultQueryPackage is the package providing the TanStack Query hooks,
+// used when none is specified. Use "@tanstack/vue-query" for Vue.
+const DefaultQueryPackage = "@tanstack/react-query"
+
+// isQuery returns true for the endpoints wrapped in a query hook
+func isQuery(a httpapi.Endpoint) bool { return a.Method == http.MethodGet }
+
+// isMutation returns true for the endpoints wrapped in a mutation hook
+func isMutation(a httpapi.Endpoint) bool {
+	switch a.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// hookName returns the capitalized name of the endpoint
+func hookName(a httpapi.Endpoint) string {
+	name := a.Contract.Name
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// queryResult returns the type of the promise returned
+// by the API method (undefined is returned on error)
+func queryResult(a httpapi.Endpoint) string {
+	out := typeOut(a)
+	if a.Contract.IsReturnBlob {
+		out = "{ blob: Blob; filename: string }"
+	} else if hasNoReturn(a) {
+		out = "boolean"
+	}
+	return fmt.Sprintf("Promise<%s | undefined>", out)
+}
+
+// argNames returns the names of the arguments of the API method
+func argNames(a httpapi.Endpoint) []string {
+	var out []string
+	for _, arg := range apiArgs(a) {
+		out = append(out, arg.name)
+	}
+	return out
+}
+
+// queryKey returns the cache key for the GET endpoint [a], made of
+// its URL followed by its arguments
+func queryKey(a httpapi.Endpoint) string {
+	items := append([]string{fmt.Sprintf("%q", a.Url)}, argNames(a)...)
+	return fmt.Sprintf("(%s) => [%s] as const", typeIn(a), strings.Join(items, ", "))
+}
+
+func generateQueryHook(a httpapi.Endpoint) string {
+	args := argNames(a)
+	params := "api: QueryAPI"
+	if in := typeIn(a); in != "" {
+		params += ", " + in
+	}
+	const template = `
+	/** use%[1]sQuery wraps %[2]s in a query */
+	export function use%[1]sQuery(%[3]s) {
+		return useQuery({
+			queryKey: queryKeys.%[2]s(%[4]s),
+			queryFn: ({ signal }) => api.%[2]s(%[5]s),
+		});
+	}
+	`
+	return fmt.Sprintf(template, hookName(a), a.Contract.Name, params,
+		strings.Join(args, ", "), strings.Join(append(args, "signal"), ", "))
+}
+
+func generateMutationHook(a httpapi.Endpoint) string {
+	var (
+		variables, call []string
+		vars            = "()"
+	)
+	for _, arg := range apiArgs(a) {
+		variables = append(variables, arg.name+": "+arg.type_)
+		call = append(call, "variables."+arg.name)
+	}
+	if len(variables) != 0 {
+		vars = fmt.Sprintf("(variables: { %s })", strings.Join(variables, "; "))
+	}
+
+	if len(a.Invalidates) == 0 {
+		const template = `
+		/** use%[1]sMutation wraps %[2]s in a mutation */
+		export function use%[1]sMutation(api: QueryAPI) {
+			return useMutation({
+				mutationFn: %[3]s => api.%[2]s(%[4]s),
+			});
+		}
+		`
+		return fmt.Sprintf(template, hookName(a), a.Contract.Name, vars, strings.Join(call, ", "))
+	}
+
+	invalidations := make([]string, len(a.Invalidates))
+	for i, url := range a.Invalidates {
+		invalidations[i] = fmt.Sprintf("queryClient.invalidateQueries({ queryKey: [%q] })", url)
+	}
+	const template = `
+	/** use%[1]sMutation wraps %[2]s in a mutation, invalidating the queries for %[5]s */
+	export function use%[1]sMutation(api: QueryAPI) {
+		const queryClient = useQueryClient();
+		return useMutation({
+			mutationFn: %[3]s => api.%[2]s(%[4]s),
+			onSuccess: () => Promise.all([%[6]s]),
+		});
+	}
+	`
+	return fmt.Sprintf(template, hookName(a), a.Contract.Name, vars, strings.Join(call, ", "),
+		strings.Join(a.Invalidates, ", "), strings.Join(invalidations, ", "))
+}
+
+// GenerateQuery generates TanStack Query hooks wrapping the API calls
+// generated by [GenerateAxios] or [GenerateFetch] :
+//   - GET endpoints are wrapped in useXXXQuery hooks, whose cache keys start with the endpoint URL
+//   - POST, PUT, PATCH and DELETE endpoints are wrapped in useXXXMutation hooks, invalidating
+//     the queries listed in [httpapi.Endpoint.Invalidates]
+//
+// The hooks are imported from [queryPackage], which defaults to [DefaultQueryPackage].
+func GenerateQuery(api []httpapi.Endpoint, queryPackage string) string {
+	if queryPackage == "" {
+		queryPackage = DefaultQueryPackage
+	}
+
+	var (
+		endpoints        []httpapi.Endpoint
+		methods, keys    []string
+		hooks            []string
+		hasQuery, hasMut bool
+		hasQueryClient   bool
+	)
+	for _, endpoint := range api {
+		if endpoint.IsUrlOnly || endpoint.Contract.IsReturnStream {
+			continue
+		}
+		switch {
+		case isQuery(endpoint):
+			hasQuery = true
+			keys = append(keys, fmt.Sprintf("%s: %s,", endpoint.Contract.Name, queryKey(endpoint)))
+			hooks = append(hooks, generateQueryHook(endpoint))
+		case isMutation(endpoint):
+			hasMut = true
+			hasQueryClient = hasQueryClient || len(endpoint.Invalidates) != 0
+			hooks = append(hooks, generateMutationHook(endpoint))
+		default: // HEAD, OPTIONS
+			continue
+		}
+		endpoints = append(endpoints, endpoint)
+		params := "signal?: AbortSignal"
+		if in := typeIn(endpoint); in != "" {
+			params = in + ", " + params
+		}
+		methods = append(methods, fmt.Sprintf("%s(%s): %s;", endpoint.Contract.Name, params, queryResult(endpoint)))
+	}
+
+	var imports []string
+	if hasMut {
+		imports = append(imports, "useMutation")
+	}
+	if hasQuery {
+		imports = append(imports, "useQuery")
+	}
+	if hasQueryClient {
+		imports = append(imports, "useQueryClient")
+	}
+	importCode := ""
+	if len(imports) != 0 {
+		importCode = fmt.Sprintf("import { %s } from %q;", strings.Join(imports, ", "), queryPackage)
+	}
+
+	return fmt.Sprintf(`
+	// Code generated by gomacro/typescript/query_api.go. DO NOT EDIT
+
+	%s
+
+	%s
+
+	/** QueryAPI is implemented by the AbstractAPI classes
+		generated by the typescript/api and typescript/fetch modes.
+	*/
+	export interface QueryAPI {
+		%s
+	}
+
+	/** queryKeys returns the cache keys used by the query hooks,
+		starting with the endpoint URL.
+	*/
+	export const queryKeys = {
+		%s
+	};
+
+	%s
+	`, importCode, renderTypes(endpoints), strings.Join(methods, "\n"), strings.Join(keys, "\n"), strings.Join(hooks, "\n"))
+}
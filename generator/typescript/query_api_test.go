@@ -0,0 +1,68 @@
+package typescript
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"github.com/benoitkugler/gomacro/analysis/httpapi"
+)
+
+func TestGenerateQuery(t *testing.T) {
+	item := &analysis.Array{Elem: analysis.Int, Len: -1}
+	apis := []httpapi.Endpoint{
+		{
+			Url: "/items", Method: http.MethodGet, Contract: httpapi.Contract{
+				Name:             "listItems",
+				InputQueryParams: []httpapi.TypedParam{{Name: "filter", Type: analysis.String}},
+				Return:           item,
+			},
+		},
+		{
+			Url: "/items/:id", Method: http.MethodGet, Contract: httpapi.Contract{
+				Name:       "getItem",
+				PathParams: []httpapi.TypedParam{{Name: "id", Type: analysis.Int}},
+				Return:     analysis.String,
+			},
+		},
+		{
+			Url: "/items/:id", Method: http.MethodPut, Contract: httpapi.Contract{
+				Name:       "updateItem",
+				PathParams: []httpapi.TypedParam{{Name: "id", Type: analysis.Int}},
+				InputBody:  item,
+			},
+			Invalidates: []string{"/items", "/items/:id"},
+		},
+		{
+			Url: "/items", Method: http.MethodDelete, Contract: httpapi.Contract{Name: "clearItems"},
+		},
+		{
+			Url: "/items", Method: http.MethodHead, Contract: httpapi.Contract{Name: "checkItems"},
+		},
+	}
+	code := GenerateQuery(apis, "")
+
+	for _, expected := range []string{
+		`import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query";`,
+		"listItems(params: {\"filter\": string}, signal?: AbortSignal): Promise<( Int[] | null) | undefined>;",
+		"updateItem(id: Int, params: ( Int[] | null), signal?: AbortSignal): Promise<boolean | undefined>;",
+		`getItem: (id: Int) => ["/items/:id", id] as const,`,
+		"export function useGetItemQuery(api: QueryAPI, id: Int)",
+		"queryFn: ({ signal }) => api.getItem(id, signal),",
+		"mutationFn: (variables: { id: Int; params: ( Int[] | null) }) => api.updateItem(variables.id, variables.params),",
+		`onSuccess: () => Promise.all([queryClient.invalidateQueries({ queryKey: ["/items"] }), queryClient.invalidateQueries({ queryKey: ["/items/:id"] })]),`,
+		"mutationFn: () => api.clearItems(),",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+	if strings.Contains(code, "checkItems") {
+		t.Fatal("HEAD endpoints should be ignored")
+	}
+
+	if code := GenerateQuery(apis[3:], "@tanstack/vue-query"); !strings.Contains(code, `import { useMutation } from "@tanstack/vue-query";`) {
+		t.Fatal(code)
+	}
+}
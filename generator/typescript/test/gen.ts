@@ -15,13 +15,20 @@ export type Int = number & { __opaque__: "Int" };
 // ISO date-time string
 export type Time = string & { __opaque__: "Time" };
 
-export type Basic1 = number & { __opaque__: "Basic1" };
+export type Basic1 = Int & { __opaque_int__: "Basic1" };
 // github.com/benoitkugler/gomacro/testutils/testsource.Basic2
 export type Basic2 = boolean;
 // github.com/benoitkugler/gomacro/testutils/testsource.Basic3
 export type Basic3 = number;
 // github.com/benoitkugler/gomacro/testutils/testsource.Basic4
 export type Basic4 = string;
+// github.com/benoitkugler/gomacro/testutils/testsource.Batch[T any]
+export type Batch<T> = T[] | null;
+// github.com/benoitkugler/gomacro/testutils/testsource.Comp
+export interface Comp {
+  A: Int;
+  B: Int;
+}
 // github.com/benoitkugler/gomacro/testutils/testsource.ComplexStruct
 export interface ComplexStruct {
   with_tag: Record<Int, Int> | null;
@@ -36,8 +43,8 @@ export interface ComplexStruct {
   F: Ar5_Ar5_boolean;
   Imported: StructWithComment;
   EnumMap: Record<EnumInt, boolean> | null;
-  OptID1: Generic_IdCamp;
-  OptID2: Generic_IdFile;
+  OptID1: Generic<IdCamp>;
+  OptID2: Generic<IdFile>;
 }
 // github.com/benoitkugler/gomacro/testutils/testsource.ConcretType1
 export interface ConcretType1 {
@@ -82,16 +89,12 @@ export const EnumUIntLabels: Record<EnumUInt, string> = {
   [EnumUInt.e]: "not added",
 };
 
-// github.com/benoitkugler/gomacro/testutils/testsource.Generic[github.com/benoitkugler/gomacro/testutils/testsource.IdCamp]
-export interface Generic_IdCamp {
-  Id: IdCamp;
-}
-// github.com/benoitkugler/gomacro/testutils/testsource.Generic[github.com/benoitkugler/gomacro/testutils/testsource.IdFile]
-export interface Generic_IdFile {
-  Id: IdFile;
+// github.com/benoitkugler/gomacro/testutils/testsource.Generic[T ~int64]
+export interface Generic<T> {
+  Id: T;
 }
-export type IdCamp = number & { __opaque__: "IdCamp" };
-export type IdFile = number & { __opaque__: "IdFile" };
+export type IdCamp = Int & { __opaque_int__: "IdCamp" };
+export type IdFile = Int & { __opaque_int__: "IdFile" };
 // github.com/benoitkugler/gomacro/testutils/testsource.ItfList
 export type ItfList = ItfType[] | null;
 
@@ -116,22 +119,77 @@ export type ItfType2 = { Kind: "ConcretType1"; Data: ConcretType1 };
 
 // github.com/benoitkugler/gomacro/testutils/testsource.MyDate
 export type MyDate = Date_;
+// github.com/benoitkugler/gomacro/testutils/testsource.Page[T any]
+export interface Page<T> {
+  Items: T[] | null;
+  Next: T | null;
+  Total: Int;
+}
+// github.com/benoitkugler/gomacro/testutils/testsource.Pair[K comparable, V any]
+export interface Pair<K, V> {
+  Key: K;
+  Value: V;
+}
 // github.com/benoitkugler/gomacro/testutils/testsource.RecursiveType
 export interface RecursiveType {
   Children: RecursiveType[] | null;
 }
+// github.com/benoitkugler/gomacro/testutils/testsource.Rows
+export type Rows = { X: Int }[] | null;
 // github.com/benoitkugler/gomacro/testutils/testsource.StructWithExternalRef
 export interface StructWithExternalRef {
   Field1: NamedSlice;
   Field2: NamedSlice;
   Field3: Int;
 }
+// github.com/benoitkugler/gomacro/testutils/testsource.WithAnonymous
+export interface WithAnonymous {
+  meta: { Count: Int; tags: string[] | null };
+  Items: { Id: Int; Label: string; Inner: { Ok: boolean } }[] | null;
+  Pair: { A: string; B: string } | null;
+  Rows: Rows;
+}
+// github.com/benoitkugler/gomacro/testutils/testsource.WithExternals
+export interface WithExternals {
+  Raw: unknown;
+  Addr: string;
+  Amount: number | null;
+  Addrs: string[] | null;
+  Timeout: Duration;
+}
+// github.com/benoitkugler/gomacro/testutils/testsource.WithGenerics
+export interface WithGenerics {
+  Comps: Page<Comp>;
+  Ints: Page<Int>;
+  Pair: Pair<string, EnumInt>;
+  Names: Batch<string>;
+  Nested: Page<Batch<Comp>>;
+}
+// github.com/benoitkugler/gomacro/testutils/testsource.WithJSONOptions
+export interface WithJSONOptions {
+  name?: string;
+  Count: string;
+  tags?: string[] | null;
+  inner: Comp;
+  A: Int;
+  B: Int;
+  comp: Comp;
+}
 // github.com/benoitkugler/gomacro/testutils/testsource.WithOpaque
 export interface WithOpaque {
   F1: StructWithExternalRef;
   F2: unknown;
   F3: unknown;
 }
+// github.com/benoitkugler/gomacro/testutils/testsource.WithPointers
+export interface WithPointers {
+  Label: string | null;
+  Count: Int | null;
+  Deadline: Time | null;
+  Inner: Comp | null;
+  Values: (EnumInt | null)[] | null;
+  opt?: string | null;
+}
 // github.com/benoitkugler/gomacro/testutils/testsource/subpackage.Enum
 export const Enum = {
   A: 0,
@@ -0,0 +1,28 @@
+package guards
+
+import "time"
+
+type Event struct {
+	Title string
+	Start time.Time
+	Dates []EventDate
+	End   time.Time `json:"end-date,omitempty"`
+}
+
+type EventDate time.Time
+
+type Note struct {
+	Text string
+}
+
+type Item interface {
+	isItem()
+}
+
+func (Event) isItem() {}
+func (Note) isItem()  {}
+
+type Agenda struct {
+	Items   []Item
+	ByTitle map[string]Event
+}
@@ -29,7 +29,7 @@ export class Endpoints {
     return this.baseURL + "/const_url_from_inner_package/endpoint" + ``;
   }
 
-  Anonymous5861747() {
+  Anonymous103() {
     return (
       this.baseURL +
       "/const_url_from_inner_package/endpoint/entoher/const_local_url" +
@@ -49,22 +49,28 @@ export class Endpoints {
     return this.baseURL + "/string_litteral" + ``;
   }
 
-  handler6() {
-    return this.baseURL + "/with_param/:param" + ``;
+  handler6(param: Int) {
+    return (
+      this.baseURL + "/with_param/" + encodeURIComponent(String(param)) + ``
+    );
   }
 
-  handler7(my_bool: boolean, my_int: Int) {
+  handler7(class_: string, my_bool: boolean, my_int: Int) {
     return (
       this.baseURL +
-      "/special_param_value/:class/route" +
+      "/special_param_value/" +
+      encodeURIComponent(String(class_)) +
+      "/route" +
       `?my-bool=${my_bool ? "YES" : ""}&my-int=${my_int}`
     );
   }
 
-  handler8(query_param1: string, query_param2: string) {
+  handler8(default_: string, query_param1: string, query_param2: string) {
     return (
       this.baseURL +
-      "/special_param_value/:default/route" +
+      "/special_param_value/" +
+      encodeURIComponent(String(default_)) +
+      "/route" +
       `?query_param1=${query_param1}&query_param2=${query_param2}`
     );
   }
@@ -73,7 +79,7 @@ export class Endpoints {
     return this.baseURL + "/extern function" + ``;
   }
 
-  Anonymous5862146() {
+  Anonymous113() {
     return this.baseURL + "/func litteral" + ``;
   }
 
@@ -85,7 +91,7 @@ export class Endpoints {
     return this.baseURL + "/with_generic" + `?param-name=${param_name}`;
   }
 
-  Anonymous5862333(token: string) {
+  Anonymous119(token: string) {
     return this.baseURL + "/with middleware" + `?token=${token}`;
   }
 }
@@ -2,7 +2,8 @@ package typescript
 
 import (
 	"fmt"
-	"go/types"
+	"go/token"
+	"strconv"
 	"strings"
 
 	an "github.com/benoitkugler/gomacro/analysis"
@@ -11,39 +12,46 @@ import (
 
 // This file defines how to print TS types.
 
-// Generate generates the code for the types in `ana.Source`.
-func Generate(ana *an.Analysis) []gen.Declaration {
+// Generate generates the code for the types in `ana.Source`,
+// along with their type guards and conversion functions (see guards.go).
+// The types which are not supported are reported to [diags].
+func Generate(ana *an.Analysis, diags *an.Diagnostics) []gen.Declaration {
 	var allTypes []an.Type
 	for _, ty := range ana.Source {
 		allTypes = append(allTypes, ana.Types[ty])
 	}
 
-	return append(generateTypes(allTypes), gen.Declaration{
+	decls := append(generateTypes(allTypes, diags), generateGuards(allTypes, diags)...)
+	return append(decls, gen.Declaration{
 		ID:       "__header",
 		Priority: true,
 		Content:  "// Code generated by gomacro/generator/typescript. DO NOT EDIT.",
 	})
 }
 
-func generateTypes(types []an.Type) []gen.Declaration {
+func generateTypes(types []an.Type, diags *an.Diagnostics) []gen.Declaration {
 	var (
 		decls []gen.Declaration
 		cache = make(gen.Cache)
 	)
 	for _, ty := range types {
-		decls = append(decls, generate(ty, cache)...)
+		diags.Try(an.DeclarationPos(ty.Type()), func() { decls = append(decls, generate(ty, cache)...) })
 	}
 	return decls
 }
 
 func declID(ty an.Type) string {
+	if st, isStruct := ty.(*an.Struct); isStruct { // distinguish anonymous structs
+		return st.Name.String()
+	}
 	return ty.Type().String()
 }
 
 func typeName(ty an.Type) string {
 	switch ty := ty.(type) {
 	case *an.Pointer:
-		panic("pointers not handled by Typescript generator")
+		// nil pointers are JSONized as null
+		return fmt.Sprintf("(%s | null)", typeName(ty.Elem))
 	case *an.Basic:
 		switch ty.Kind() {
 		case an.BKString:
@@ -70,25 +78,29 @@ func typeName(ty an.Type) string {
 			switch elem := ty.Elem.(type) {
 			case *an.Array:
 				if elem.Len == -1 {
-					panic("Fixed array of slices not supported")
+					panic(an.NewError(token.Position{}, an.CodeUnsupportedType, "fixed array of slices %s not supported", ty.Type()))
 				}
 			case *an.Map:
-				panic("Fixed array of maps not supported")
+				panic(an.NewError(token.Position{}, an.CodeUnsupportedType, "fixed array of maps %s not supported", ty.Type()))
 			}
 
 			return fmt.Sprintf("Ar%d_%s", ty.Len, typeName(ty.Elem))
 		}
 		// nullable since empty slice may be JSONized as null
 		return fmt.Sprintf("( %s[] | null)", typeName(ty.Elem))
-	case *an.Named, *an.Enum, *an.Union:
-		return an.LocalName(ty)
+	case *an.External:
+		return ty.Mapping.TypeScriptType()
+	case *an.Named:
+		return genericName(ty, ty.TypeArgs)
 	case *an.Struct:
-		name := an.LocalName(ty)
-		typeParams := ty.Name.TypeArgs()
-		for i := range typeParams.Len() {
-			name += "_" + typeParams.At(i).(*types.Named).Obj().Name()
+		if ty.Anonymous != nil {
+			return inlineStruct(ty)
 		}
-		return name
+		return genericName(ty, ty.TypeArgs)
+	case *an.Enum, *an.Union:
+		return an.LocalName(ty)
+	case *an.TypeParam:
+		return ty.Name()
 	case nil:
 		return "never"
 	default:
@@ -96,6 +108,58 @@ func typeName(ty an.Type) string {
 	}
 }
 
+// genericName returns the name of [ty], followed by its type arguments, if any,
+// like Page<User>
+func genericName(ty an.Type, typeArgs []an.Type) string {
+	name := an.LocalName(ty)
+	if len(typeArgs) == 0 {
+		return name
+	}
+	args := make([]string, len(typeArgs))
+	for i, arg := range typeArgs {
+		args[i] = typeName(arg)
+	}
+	return fmt.Sprintf("%s<%s>", name, strings.Join(args, ", "))
+}
+
+// declName returns the name used in the declaration of [ty],
+// followed by its type parameters, if any, like Page<T>
+func declName(ty an.Type, typeParams []*an.TypeParam) string {
+	name := an.LocalName(ty)
+	if len(typeParams) == 0 {
+		return name
+	}
+	params := make([]string, len(typeParams))
+	for i, param := range typeParams {
+		params[i] = param.Name()
+	}
+	return fmt.Sprintf("%s<%s>", name, strings.Join(params, ", "))
+}
+
+// identName returns a valid identifier for [ty], used as suffix in
+// function names, like isX or XSchema.
+// Instantiated generic types use their monomorphized name, like PageUser.
+func identName(ty an.Type) string {
+	switch ty := ty.(type) {
+	case *an.Named:
+		return an.MonomorphizedName(ty.Type())
+	case *an.Struct: // anonymous structs use their synthesized name
+		return an.MonomorphizedName(ty.Name)
+	default:
+		return typeName(ty)
+	}
+}
+
+// generateInstance returns the declarations required by
+// an instantiated generic type : its generic declaration and its type arguments.
+func generateInstance(origin an.Type, typeArgs []an.Type, cache gen.Cache) []gen.Declaration {
+	out := generate(origin, cache)
+	for _, arg := range typeArgs {
+		out = append(out, generate(arg, cache)...)
+	}
+	return out
+}
+
 func generate(ty an.Type, cache gen.Cache) []gen.Declaration {
 	if cache.Check(ty) {
 		return nil
@@ -109,7 +173,9 @@ func generate(ty an.Type, cache gen.Cache) []gen.Declaration {
 		// nothing to do
 		return nil
 	case *an.Pointer:
-		panic("pointers not handled by Typescript generator")
+		return generate(ty.Elem, cache)
+	case *an.External, *an.TypeParam: // nothing to declare
+		return nil
 	case *an.Time:
 		return []gen.Declaration{codeForTime(ty)}
 	case *an.Array:
@@ -117,10 +183,16 @@ func generate(ty an.Type, cache gen.Cache) []gen.Declaration {
 	case *an.Map:
 		return codeForMap(ty, cache)
 	case *an.Named:
+		if len(ty.TypeArgs) != 0 {
+			return generateInstance(ty.Origin, ty.TypeArgs, cache)
+		}
 		return codeForNamed(ty, cache)
 	case *an.Enum:
 		return []gen.Declaration{codeForEnum(ty)}
 	case *an.Struct:
+		if len(ty.TypeArgs) != 0 {
+			return generateInstance(ty.Origin, ty.TypeArgs, cache)
+		}
 		return codeForStruct(ty, cache)
 	case *an.Union:
 		return codeForUnion(ty, cache)
@@ -167,7 +239,7 @@ func codeForNamed(named *an.Named, cache gen.Cache) []gen.Declaration {
 	if basic, ok := named.Underlying.(*an.Basic); ok && basic.Kind() == an.BKInt {
 		return []gen.Declaration{{
 			ID:      declID(named),
-			Content: fmt.Sprintf("export type %s = Int & { __opaque_int__: '%s' };", name, name),
+			Content: fmt.Sprintf("export type %s = Int & { __opaque_int__: '%s' };", declName(named, named.TypeParams), name),
 		}}
 	}
 
@@ -179,7 +251,7 @@ func codeForNamed(named *an.Named, cache gen.Cache) []gen.Declaration {
 	}
 
 	code := fmt.Sprintf(`// %s
-	export type %s = %s`, gen.Origin(named), name, target)
+	export type %s = %s`, gen.Origin(named), declName(named, named.TypeParams), target)
 
 	deps = append(deps, gen.Declaration{ID: declID(named), Content: code})
 	return deps
@@ -229,6 +301,36 @@ func codeForEnum(enum *an.Enum) gen.Declaration {
 	}
 }
 
+// propertyName returns [name], quoted if it is not a valid identifier
+func propertyName(name string) string {
+	if token.IsIdentifier(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// fieldName returns the property name of [field],
+// which is optional if the field may be omitted.
+func fieldName(field an.StructField) string {
+	if field.OmitEmpty() {
+		return propertyName(field.JSONName()) + "?"
+	}
+	return propertyName(field.JSONName())
+}
+
+// fieldTypeName returns the TS type of [field], taking into account
+// opaque fields and the 'string' json option.
+func fieldTypeName(field an.StructField) string {
+	switch {
+	case field.IsOpaqueFor("typescript"):
+		return "unknown"
+	case field.AsString():
+		return "string"
+	default:
+		return typeName(field.Type)
+	}
+}
+
 func codeForStruct(t *an.Struct, cache gen.Cache) (decls []gen.Declaration) {
 	var fields []string
 	for _, field := range t.Fields {
@@ -236,16 +338,17 @@ func codeForStruct(t *an.Struct, cache gen.Cache) (decls []gen.Declaration) {
 			continue
 		}
 
-		if field.IsOpaqueFor("typescript") {
-			// use unknown
-			fields = append(fields, fmt.Sprintf("\t%s: unknown,", field.JSONName()))
-		} else {
+		if !field.IsOpaqueFor("typescript") {
 			decls = append(decls, generate(field.Type, cache)...) // recurse
-			fields = append(fields, fmt.Sprintf("\t%s: %s,", field.JSONName(), typeName(field.Type)))
 		}
+		fields = append(fields, fmt.Sprintf("\t%s: %s,", fieldName(field), fieldTypeName(field)))
 	}
 
-	name := typeName(t)
+	if t.Anonymous != nil { // inlined, see [inlineStruct]
+		return decls
+	}
+
+	name := declName(t, t.TypeParams)
 
 	out := "// " + gen.Origin(t) + "\n"
 	if isEmpty := len(t.Fields) == 0; isEmpty {
@@ -261,6 +364,20 @@ func codeForStruct(t *an.Struct, cache gen.Cache) (decls []gen.Declaration) {
 	return decls
 }
 
+// inlineStruct returns the object type for the anonymous struct [t]
+func inlineStruct(t *an.Struct) string {
+	var fields []string
+	for _, field := range t.Fields {
+		if field.Exported() {
+			fields = append(fields, fmt.Sprintf("%s: %s", fieldName(field), fieldTypeName(field)))
+		}
+	}
+	if len(fields) == 0 {
+		return "Record<string, never>"
+	}
+	return "{ " + strings.Join(fields, "; ") + " }"
+}
+
 func codeForUnion(u *an.Union, cache gen.Cache) (out []gen.Declaration) {
 	var (
 		membersDecl []string
@@ -0,0 +1,204 @@
+package typescript
+
+import (
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"github.com/benoitkugler/gomacro/generator"
+	"github.com/benoitkugler/gomacro/testutils"
+)
+
+func TestJSONOptions(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithJSONOptions")
+	ty := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil).Types[typ]
+
+	code := generator.WriteDeclarations(append(generateTypes([]analysis.Type{ty}, nil), generateGuards([]analysis.Type{ty}, nil)...))
+	for _, expected := range []string{
+		"name?: string,",
+		"Count: string,",
+		"tags?: ( string[] | null),",
+		"inner: Comp,",
+		"A: Int,",
+		"comp: Comp,",
+		`(o["name"] === undefined || typeof o["name"] === "string")`,
+		`typeof o["Count"] === "string"`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+
+	code = generator.WriteDeclarations(generateZodSchemas([]analysis.Type{ty}, nil))
+	for _, expected := range []string{
+		"name: z.string().optional(),",
+		"Count: z.string(),",
+		"tags: z.array(z.string()).nullable().optional(),",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestPointers(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithPointers")
+	ty := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil).Types[typ]
+
+	code := generator.WriteDeclarations(append(generateTypes([]analysis.Type{ty}, nil), generateGuards([]analysis.Type{ty}, nil)...))
+	for _, expected := range []string{
+		"Label: (string | null),",
+		"Deadline: (Time | null),",
+		"Inner: (Comp | null),",
+		"Values: ( (EnumInt | null)[] | null),",
+		"opt?: (string | null),",
+		`(o["Label"] === null || typeof o["Label"] === "string")`,
+		`(o["Inner"] === null || isComp(o["Inner"]))`,
+		`Deadline: (json["Deadline"] === null ? null : new Date(json["Deadline"]))`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+
+	code = generator.WriteDeclarations(generateZodSchemas([]analysis.Type{ty}, nil))
+	for _, expected := range []string{
+		"Label: z.string().nullable(),",
+		"Deadline: TimeSchema.nullable(),",
+		"Values: z.array(z.lazy(() => EnumIntSchema).nullable()).nullable(),",
+		"opt: z.string().nullable().optional(),",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestExternals(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithExternals")
+	ty := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil).Types[typ]
+
+	code := generator.WriteDeclarations(append(generateTypes([]analysis.Type{ty}, nil), generateGuards([]analysis.Type{ty}, nil)...))
+	for _, expected := range []string{
+		"Raw: unknown,",
+		"Addr: string,",
+		"Amount: (number | null),",
+		"Addrs: ( string[] | null),",
+		"Timeout: Duration,",
+		`typeof o["Addr"] === "string"`,
+		`(o["Amount"] === null || typeof o["Amount"] === "number")`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+
+	code = generator.WriteDeclarations(generateZodSchemas([]analysis.Type{ty}, nil))
+	for _, expected := range []string{
+		"Raw: z.unknown(),",
+		"Addr: z.string(),",
+		"Timeout: (z.number() as unknown as z.ZodType<Duration>),",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestGenerics(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithGenerics")
+	ty := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil).Types[typ]
+
+	code := generator.WriteDeclarations(append(generateTypes([]analysis.Type{ty}, nil), generateGuards([]analysis.Type{ty}, nil)...))
+	for _, expected := range []string{
+		"export interface Page<T> {",
+		"Items: ( T[] | null),",
+		"Next: (T | null),",
+		"export interface Pair<K, V> {",
+		"export type Batch<T> = ( T[] | null)",
+		"Comps: Page<Comp>,",
+		"Pair: Pair<string, EnumInt>,",
+		"Nested: Page<Batch<Comp>>,",
+		"export function isPageComp(v: unknown): v is Page<Comp> {",
+		"export function isBatchString(v: unknown): v is Batch<string> {",
+		`isPageBatchComp(o["Nested"])`,
+		`isArrayOf(o["Items"], (e) => isBatchComp(e))`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+	// generic declarations have no guards
+	if strings.Contains(code, "function isPage(") {
+		t.Fatal("unexpected guard for Page")
+	}
+	// each generic type is only declared once
+	if strings.Count(code, "export interface Page<T>") != 1 {
+		t.Fatal("duplicated declaration of Page")
+	}
+
+	code = generator.WriteDeclarations(generateZodSchemas([]analysis.Type{ty}, nil))
+	for _, expected := range []string{
+		"export const PageCompSchema: z.ZodType<Page<Comp>> = z.object({",
+		"Nested: z.lazy(() => PageBatchCompSchema),",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
+
+func TestAnonymousStructs(t *testing.T) {
+	pkg, err := analysis.LoadSource("../../testutils/testsource/defs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := testutils.Lookup(pkg, "WithAnonymous")
+	ty := analysis.NewAnalysisFromTypes(pkg, []types.Type{typ}, nil).Types[typ]
+
+	code := generator.WriteDeclarations(append(generateTypes([]analysis.Type{ty}, nil), generateGuards([]analysis.Type{ty}, nil)...))
+	for _, expected := range []string{
+		"meta: { Count: Int; tags: ( string[] | null) },",
+		"Items: ( { Id: Int; Label: string; Inner: { Ok: boolean } }[] | null),",
+		"Pair: ({ A: string; B: string } | null),",
+		"export type Rows = ( { X: Int }[] | null)",
+		"export function isWithAnonymousMeta(v: unknown): v is { Count: Int; tags: ( string[] | null) } {",
+		"isWithAnonymousItemsInner(o[\"Inner\"])",
+		"isArrayOf(v, (e) => isRowsElem(e))",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+	// anonymous structs are inlined
+	if strings.Contains(code, "interface WithAnonymousMeta") {
+		t.Fatal("unexpected declaration for an anonymous struct")
+	}
+
+	code = generator.WriteDeclarations(generateZodSchemas([]analysis.Type{ty}, nil))
+	for _, expected := range []string{
+		"export const WithAnonymousPairSchema: z.ZodType<{ A: string; B: string }> = z.object({",
+		"Pair: z.lazy(() => WithAnonymousPairSchema).nullable(),",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
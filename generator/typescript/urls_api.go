@@ -55,6 +55,9 @@ func generateQuery(params []httpapi.TypedParam) (vars, query string) {
 
 func generateURL(a httpapi.Endpoint) string {
 	params, query := generateQuery(a.Contract.InputQueryParams)
+	if pathParams := pathParamsIn(a); len(pathParams) != 0 {
+		params = strings.Join(pathParams, ", ") + ", " + params
+	}
 	const template = `
 	/** Returns an URL with method %[5]s */
  	%[1]s(%[2]s) {
@@ -0,0 +1,284 @@
+package typescript
+
+import (
+	"fmt"
+	"strings"
+
+	an "github.com/benoitkugler/gomacro/analysis"
+	gen "github.com/benoitkugler/gomacro/generator"
+)
+
+// This file defines Zod schemas, validating
+// the TS types at runtime.
+
+// GenerateZod generates the code for the types in `ana.Source`,
+// along with a Zod schema for each of them.
+// The types which are not supported are reported to [diags].
+func GenerateZod(ana *an.Analysis, diags *an.Diagnostics) []gen.Declaration {
+	var allTypes []an.Type
+	for _, ty := range ana.Source {
+		allTypes = append(allTypes, ana.Types[ty])
+	}
+
+	return append(generateZodSchemas(allTypes, diags),
+		gen.Declaration{
+			ID:       "__header",
+			Priority: true,
+			Content:  "// Code generated by gomacro/generator/typescript. DO NOT EDIT.",
+		},
+		gen.Declaration{
+			ID:       "__zod_import",
+			Priority: true,
+			Content:  `import { z } from "zod";`,
+		},
+	)
+}
+
+// generateZodSchemas returns the TS types and their schemas.
+func generateZodSchemas(types []an.Type, diags *an.Diagnostics) []gen.Declaration {
+	decls := generateTypes(types, diags)
+	cache := make(gen.Cache)
+	for _, ty := range types {
+		diags.Try(an.DeclarationPos(ty.Type()), func() { decls = append(decls, generateZod(ty, cache)...) })
+	}
+	return decls
+}
+
+// zodSchemaName returns the name of the schema
+// validating the named type [ty]
+func zodSchemaName(ty an.Type) string { return identName(ty) + "Schema" }
+
+func zodDeclID(ty an.Type) string { return "zod:" + declID(ty) }
+
+// zodDecl returns the declaration of the schema [code] for [ty]
+func zodDecl(ty an.Type, code string) gen.Declaration {
+	return gen.Declaration{
+		ID:      zodDeclID(ty),
+		Content: fmt.Sprintf("export const %s: z.ZodType<%s> = %s;", zodSchemaName(ty), typeName(ty), code),
+	}
+}
+
+var (
+	intSchemaDecl = gen.Declaration{
+		ID:      "__int_schema",
+		Content: `export const IntSchema = z.custom<Int>((value) => Number.isInteger(value), "expected an integer");`,
+	}
+
+	timeSchemaDecl = gen.Declaration{
+		ID: "__time_schema",
+		Content: `export const TimeSchema = z.custom<Time>(
+			(value) => typeof value === "string" && !isNaN(Date.parse(value)),
+			"expected an ISO date-time string",
+		);`,
+	}
+
+	dateSchemaDecl = gen.Declaration{
+		ID: "__date_schema",
+		Content: `export const Date_Schema = z.custom<Date_>(
+			(value) => typeof value === "string" && /^\d{4}-\d{2}-\d{2}$/.test(value) && !isNaN(Date.parse(value)),
+			"expected a AAAA-MM-YY date",
+		);`,
+	}
+)
+
+// isStringKind returns true if [ty] is marshalled as a JSON string
+func isStringKind(ty an.Type) bool {
+	switch ty := ty.(type) {
+	case *an.Basic:
+		return ty.Kind() == an.BKString
+	case *an.Enum:
+		return ty.Kind() == an.BKString
+	case *an.Named:
+		return isStringKind(ty.Underlying)
+	default:
+		return false
+	}
+}
+
+// zodExpr returns the schema expression for [ty].
+// Named schemas are referenced with z.lazy, so that the declaration
+// order does not matter, and recursive types are supported.
+func zodExpr(ty an.Type) string {
+	switch ty := ty.(type) {
+	case *an.Pointer:
+		return zodExpr(ty.Elem) + ".nullable()"
+	case *an.Basic:
+		switch ty.Kind() {
+		case an.BKString:
+			return "z.string()"
+		case an.BKInt:
+			return "IntSchema"
+		case an.BKFloat:
+			return "z.number()"
+		case an.BKBool:
+			return "z.boolean()"
+		default:
+			panic(an.ExhaustiveBasicKindSwitch)
+		}
+	case *an.Time:
+		if ty.IsDate {
+			return "Date_Schema"
+		}
+		return "TimeSchema"
+	case *an.Map:
+		if isStringKind(ty.Key) {
+			return fmt.Sprintf("z.record(%s, %s).nullable()", zodExpr(ty.Key), zodExpr(ty.Elem))
+		}
+		// JSON object keys are always strings
+		return fmt.Sprintf("(z.record(z.string(), %s).nullable() as unknown as z.ZodType<%s>)", zodExpr(ty.Elem), typeName(ty))
+	case *an.Array:
+		elem := zodExpr(ty.Elem)
+		if ty.Len >= 0 {
+			return fmt.Sprintf("z.tuple([%s])", strings.Repeat(elem+",", ty.Len))
+		}
+		// nullable since empty slice may be JSONized as null
+		return fmt.Sprintf("z.array(%s).nullable()", elem)
+	case *an.Named:
+		if typeName(ty) == typeName(ty.Underlying) {
+			return zodExpr(ty.Underlying)
+		}
+		return fmt.Sprintf("z.lazy(() => %s)", zodSchemaName(ty))
+	case *an.Enum, *an.Struct, *an.Union:
+		return fmt.Sprintf("z.lazy(() => %s)", zodSchemaName(ty))
+	case *an.External:
+		var schema string
+		switch ty.Mapping.JSON {
+		case an.JSONString:
+			schema = "z.string()"
+		case an.JSONNumber:
+			schema = "z.number()"
+		case an.JSONBoolean:
+			schema = "z.boolean()"
+		default:
+			schema = "z.unknown()"
+		}
+		if ty.Mapping.TypeScript != "" { // custom TS type
+			return fmt.Sprintf("(%s as unknown as z.ZodType<%s>)", schema, ty.Mapping.TypeScript)
+		}
+		return schema
+	case *an.TypeParam: // only found in generic declarations, which have no schemas
+		return "z.unknown()"
+	default:
+		panic(an.ExhaustiveTypeSwitch)
+	}
+}
+
+func generateZod(ty an.Type, cache gen.Cache) []gen.Declaration {
+	if cache.Check(ty) {
+		return nil
+	}
+
+	switch ty := ty.(type) {
+	case *an.Basic:
+		if ty.Kind() == an.BKInt {
+			return []gen.Declaration{intSchemaDecl}
+		}
+		// nothing to do
+		return nil
+	case *an.Pointer:
+		return generateZod(ty.Elem, cache)
+	case *an.External, *an.TypeParam: // nothing to declare
+		return nil
+	case *an.Time:
+		if ty.IsDate {
+			return []gen.Declaration{dateSchemaDecl}
+		}
+		return []gen.Declaration{timeSchemaDecl}
+	case *an.Array:
+		return generateZod(ty.Elem, cache)
+	case *an.Map:
+		return append(generateZod(ty.Key, cache), generateZod(ty.Elem, cache)...)
+	case *an.Named:
+		if len(ty.TypeParams) != 0 { // schemas are defined for each instantiation
+			return nil
+		}
+		return zodForNamed(ty, cache)
+	case *an.Enum:
+		return []gen.Declaration{zodForEnum(ty)}
+	case *an.Struct:
+		if len(ty.TypeParams) != 0 { // schemas are defined for each instantiation
+			return nil
+		}
+		return zodForStruct(ty, cache)
+	case *an.Union:
+		return zodForUnion(ty, cache)
+	default:
+		panic(an.ExhaustiveTypeSwitch)
+	}
+}
+
+func zodForNamed(named *an.Named, cache gen.Cache) []gen.Declaration {
+	// special case for named int, such as IDs
+	if basic, ok := named.Underlying.(*an.Basic); ok && basic.Kind() == an.BKInt {
+		return []gen.Declaration{zodDecl(named,
+			fmt.Sprintf(`z.custom<%s>((value) => Number.isInteger(value), "expected an integer")`, typeName(named)))}
+	}
+
+	deps := generateZod(named.Underlying, cache) // recurse
+
+	// do not add decl if the names are the same
+	if typeName(named) == typeName(named.Underlying) {
+		return deps
+	}
+
+	return append(deps, zodDecl(named, zodExpr(named.Underlying)))
+}
+
+func zodForEnum(enum *an.Enum) gen.Declaration {
+	var literals []string
+	for _, val := range enum.Members {
+		literals = append(literals, fmt.Sprintf("z.literal(%s)", val.Const.Val().String()))
+	}
+	code := literals[0]
+	if len(literals) > 1 { // z.union requires at least two options
+		code = fmt.Sprintf("z.union([%s])", strings.Join(literals, ", "))
+	}
+	return zodDecl(enum, code)
+}
+
+func zodForStruct(t *an.Struct, cache gen.Cache) (decls []gen.Declaration) {
+	var fields []string
+	for _, field := range t.Fields {
+		if !field.Exported() {
+			continue
+		}
+
+		var schema string
+		switch {
+		case field.IsOpaqueFor("typescript"):
+			schema = "z.unknown()"
+		case field.AsString():
+			schema = "z.string()"
+		default:
+			decls = append(decls, generateZod(field.Type, cache)...) // recurse
+			schema = zodExpr(field.Type)
+		}
+		if field.OmitEmpty() {
+			schema += ".optional()"
+		}
+		fields = append(fields, fmt.Sprintf("\t%s: %s,", propertyName(field.JSONName()), schema))
+	}
+
+	code := fmt.Sprintf(`z.object({
+		%s
+	})`, strings.Join(fields, "\n"))
+	if isEmpty := len(t.Fields) == 0; isEmpty { // match Record<string, never>
+		code = "z.record(z.string(), z.never())"
+	}
+
+	return append(decls, zodDecl(t, code))
+}
+
+func zodForUnion(u *an.Union, cache gen.Cache) (out []gen.Declaration) {
+	var members []string
+	for _, m := range u.Members {
+		members = append(members, fmt.Sprintf(`z.object({ Kind: z.literal(%q), Data: %s }),`, an.LocalName(m), zodExpr(m)))
+
+		out = append(out, generateZod(m, cache)...) // recurse
+	}
+	code := fmt.Sprintf(`z.discriminatedUnion("Kind", [
+		%s
+	])`, strings.Join(members, "\n"))
+
+	return append(out, zodDecl(u, code))
+}
@@ -0,0 +1,37 @@
+package typescript
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/benoitkugler/gomacro/analysis"
+	"github.com/benoitkugler/gomacro/generator"
+)
+
+func TestGenerateZod(t *testing.T) {
+	source := "../../testutils/testsource/defs.go"
+	pkg, err := analysis.LoadSource(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ana := analysis.NewAnalysisFromFile(pkg, source, nil)
+
+	code := generator.WriteDeclarations(GenerateZod(ana, nil))
+	for _, expected := range []string{
+		`import { z } from "zod";`,
+		"export interface RecursiveType {",
+		"export const RecursiveTypeSchema: z.ZodType<RecursiveType> = z.object({",
+		"Children: z.array(z.lazy(() => RecursiveTypeSchema)).nullable(),",
+		`z.object({ Kind: z.literal("ConcretType1"), Data: z.lazy(() => ConcretType1Schema) }),`,
+		"export const EnumIntSchema: z.ZodType<EnumInt> = z.union([",
+		"with_tag: (z.record(z.string(), IntSchema).nullable() as unknown as z.ZodType<(Record<Int,Int> | null)>),",
+		"F: z.tuple([z.tuple([z.boolean(),z.boolean(),z.boolean(),z.boolean(),z.boolean(),]),",
+		"Date: z.lazy(() => MyDateSchema),",
+		"export const MyDateSchema: z.ZodType<MyDate> = Date_Schema;",
+		"F2: z.unknown(),",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatal(expected)
+		}
+	}
+}
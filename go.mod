@@ -4,7 +4,10 @@ go 1.23.0
 
 toolchain go1.24.1
 
-require golang.org/x/tools v0.31.0
+require (
+	golang.org/x/tools v0.31.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	golang.org/x/mod v0.24.0 // indirect
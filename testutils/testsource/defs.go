@@ -1,6 +1,9 @@
 package testsource
 
 import (
+	"encoding/json"
+	"math/big"
+	"net/netip"
 	"time"
 
 	"github.com/benoitkugler/gomacro/testutils/testsource/subpackage"
@@ -84,3 +87,69 @@ type WithOpaque struct {
 	F2 RecursiveType         `gomacro-opaque:"dart, typescript"`
 	F3 StructWithExternalRef `gomacro-opaque:" typescript"`
 }
+
+type WithJSONOptions struct {
+	Name  string   `json:"name,omitempty"`
+	Count int      `json:",string"`
+	Tags  []string `json:"tags,omitempty"`
+	Inner Comp     `json:"inner,omitempty"` // structs are never empty
+	Flat  Comp     `json:",inline"`
+	Comp  `json:"comp"`
+}
+
+type WithPointers struct {
+	Label    *string
+	Count    *int
+	Deadline *time.Time
+	Inner    *Comp
+	Values   []*EnumInt
+	Opt      *string `json:"opt,omitempty"`
+}
+
+type WithExternals struct {
+	Raw     json.RawMessage
+	Addr    netip.Addr
+	Amount  *big.Int
+	Addrs   []netip.Addr
+	Timeout time.Duration `gomacro-type:"json:number,typescript:Duration,dart:Duration,dart-from-json:durationFromJson,dart-to-json:durationToJson,dart-import:package:app/duration.dart,sql:interval,randdata:randDuration"`
+}
+
+// Page is a generic struct
+type Page[T any] struct {
+	Items []T
+	Next  *T
+	Total int
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// Batch is a generic named type
+type Batch[T any] []T
+
+type WithGenerics struct {
+	Comps  Page[Comp]
+	Ints   Page[int]
+	Pair   Pair[string, EnumInt]
+	Names  Batch[string]
+	Nested Page[Batch[Comp]]
+}
+
+type WithAnonymous struct {
+	Meta struct {
+		Count int
+		Tags  []string `json:"tags"`
+	} `json:"meta"`
+	Items []struct {
+		Id    int64
+		Label string
+		Inner struct{ Ok bool }
+	}
+	Pair *struct{ A, B string }
+	Rows Rows
+}
+
+// Rows is a named slice of anonymous structs
+type Rows []struct{ X int }
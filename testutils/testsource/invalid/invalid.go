@@ -0,0 +1,48 @@
+// Package invalid defines types which can't be
+// (fully) analyzed, used to test the diagnostics.
+package invalid
+
+import "time"
+
+// Event is a valid type
+type Event struct {
+	ID    int64
+	Label string
+}
+
+// gomacro:UNKNOWN something
+type Broken struct {
+	ID      int64
+	Updates chan int
+	Timeout time.Duration `gomacro-type:"json"`
+	Event   Event
+}
+
+// Phase has complex constants, which are not supported
+type Phase complex128
+
+const PhaseA Phase = 1i
+
+// Measure has fields with basic types not supported
+type Measure struct {
+	Value  float64
+	Ratio  complex64
+	Phase  Phase
+	Offset uintptr
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// ListInt has the monomorphized name of []int
+type ListInt []int
+
+// Instantiations uses instantiations with
+// the same monomorphized name
+type Instantiations struct {
+	A Pair[int, ListInt]
+	B Pair[int, []int]
+	C Pair[int, ListInt]
+}
@@ -80,3 +80,18 @@ type (
 	IdCamp int64
 	IdFile int64
 )
+
+// WithAnonymousCollisions has anonymous structs whose
+// synthesized names would collide
+type WithAnonymousCollisions struct {
+	Settings struct{ A int }
+	Index    map[struct{ K string }]struct{ V int }
+	Named    Index
+}
+
+// WithAnonymousCollisionsSettings is declared with the name
+// synthesized for the WithAnonymousCollisions.Settings field
+type WithAnonymousCollisionsSettings struct{ B int }
+
+// Index is a named map of anonymous structs
+type Index map[struct{ K string }]struct{ V int }